@@ -26,6 +26,7 @@ import (
 	"github.com/coreos/vault-operator/pkg/util/probe"
 	"github.com/coreos/vault-operator/version"
 
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
 	"k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes"
@@ -58,6 +59,7 @@ func main() {
 	kubecli := kubernetes.NewForConfigOrDie(kubecfg)
 
 	http.HandleFunc(probe.HTTPReadyzEndpoint, probe.ReadyzHandler)
+	http.Handle("/metrics", promhttp.Handler())
 	go http.ListenAndServe("0.0.0.0:8080", nil)
 
 	id, err := os.Hostname()
@@ -0,0 +1,72 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"k8s.io/api/core/v1"
+)
+
+// AuditPolicy defines the audit devices that the vault operator enables in vault once it is unsealed.
+// Each device is enabled at the path named after its type, e.g. "file".
+type AuditPolicy struct {
+	// File writes the audit log to a file on a volume of vault pods.
+	// This field cannot be updated once the CR is created.
+	File *FileAuditDevice `json:"file,omitempty"`
+
+	// Socket writes the audit log to a TCP or UDP address.
+	Socket *SocketAuditDevice `json:"socket,omitempty"`
+
+	// Syslog writes the audit log to the syslog of the vault container.
+	Syslog *SyslogAuditDevice `json:"syslog,omitempty"`
+}
+
+// FileAuditDevice writes the audit log to "/var/log/vault/audit.log" in vault pods.
+type FileAuditDevice struct {
+	// PersistentVolumeClaimName is the name of the claim of the volume the audit log is written to.
+	// The claim must be ReadWriteMany if the vault service has more than one node.
+	// Default: an emptyDir volume, which goes away with the pod.
+	PersistentVolumeClaimName string `json:"persistentVolumeClaimName,omitempty"`
+
+	// Sidecar is a container run alongside the vault container to ship the audit log,
+	// with the audit log volume mounted read-only at "/var/log/vault".
+	Sidecar *v1.Container `json:"sidecar,omitempty"`
+
+	// Options are additional options of the audit device, e.g. "format", passed to vault as is.
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// SocketAuditDevice writes the audit log to a socket.
+type SocketAuditDevice struct {
+	// Address to write the audit log to, e.g. "fluentd.logging:24224".
+	Address string `json:"address"`
+
+	// SocketType is "tcp" or "udp". Default: "tcp".
+	SocketType string `json:"socketType,omitempty"`
+
+	// Options are additional options of the audit device, passed to vault as is.
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// SyslogAuditDevice writes the audit log to syslog.
+type SyslogAuditDevice struct {
+	// Facility of the syslog messages. Default: "AUTH".
+	Facility string `json:"facility,omitempty"`
+
+	// Tag of the syslog messages. Default: "vault".
+	Tag string `json:"tag,omitempty"`
+
+	// Options are additional options of the audit device, passed to vault as is.
+	Options map[string]string `json:"options,omitempty"`
+}
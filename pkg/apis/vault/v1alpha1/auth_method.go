@@ -0,0 +1,158 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AuthMethodType is the type of a vault auth method.
+type AuthMethodType string
+
+const (
+	AuthMethodKubernetes AuthMethodType = "kubernetes"
+	AuthMethodAppRole    AuthMethodType = "approle"
+	AuthMethodUserpass   AuthMethodType = "userpass"
+	AuthMethodJWT        AuthMethodType = "jwt"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type VaultAuthMethodList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []VaultAuthMethod `json:"items"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VaultAuthMethod is an auth method, with its config and roles, that the vault operator enables in a vault service.
+type VaultAuthMethod struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              VaultAuthMethodSpec   `json:"spec"`
+	Status            VaultAuthMethodStatus `json:"status,omitempty"`
+}
+
+type VaultAuthMethodSpec struct {
+	// VaultService is the name of the vault service in the same namespace to enable the auth method in.
+	VaultService string `json:"vaultService"`
+
+	// Type of the auth method: kubernetes, approle, userpass or jwt.
+	Type AuthMethodType `json:"type"`
+
+	// Path the auth method is enabled at, e.g. "kubernetes" for "auth/kubernetes".
+	// Default: the type of the auth method.
+	// This field cannot be updated once the auth method is enabled.
+	Path string `json:"path,omitempty"`
+
+	// Description of the auth method in vault.
+	Description string `json:"description,omitempty"`
+
+	// Kubernetes is the config of the kubernetes auth method.
+	Kubernetes *KubernetesAuthConfig `json:"kubernetes,omitempty"`
+
+	// JWT is the config of the jwt auth method.
+	JWT *JWTAuthConfig `json:"jwt,omitempty"`
+
+	// Roles of the auth method. For the userpass auth method these are the users.
+	// Roles removed from this list are deleted from vault.
+	Roles []AuthRole `json:"roles,omitempty"`
+}
+
+// KubernetesAuthConfig is the config of the kubernetes auth method.
+type KubernetesAuthConfig struct {
+	// Host is the URL of the Kubernetes API server.
+	// Default: "https://kubernetes.default.svc".
+	Host string `json:"host,omitempty"`
+
+	// CACert is the PEM encoded CA cert of the Kubernetes API server.
+	// Default: the CA cert of the service account token of the vault pods.
+	CACert string `json:"caCert,omitempty"`
+
+	// TokenReviewerSecret is the service account token secret used to review the tokens clients log in with.
+	// Default: the service account token of the vault pods, which needs spec.kubernetesAuth of the vault service.
+	TokenReviewerSecret string `json:"tokenReviewerSecret,omitempty"`
+}
+
+// JWTAuthConfig is the config of the jwt auth method.
+// One of OIDCDiscoveryURL and JWTValidationPubKeys must be set.
+type JWTAuthConfig struct {
+	// OIDCDiscoveryURL is the URL used to discover the keys that verify the JWTs.
+	OIDCDiscoveryURL string `json:"oidcDiscoveryURL,omitempty"`
+
+	// OIDCDiscoveryCACert is the PEM encoded CA cert to verify the OIDC discovery URL with.
+	OIDCDiscoveryCACert string `json:"oidcDiscoveryCACert,omitempty"`
+
+	// JWTValidationPubKeys are the PEM encoded public keys that verify the JWTs.
+	JWTValidationPubKeys []string `json:"jwtValidationPubKeys,omitempty"`
+
+	// BoundIssuer is the issuer that JWTs must have.
+	BoundIssuer string `json:"boundIssuer,omitempty"`
+}
+
+// AuthRole is a role of an auth method, or a user of the userpass auth method.
+type AuthRole struct {
+	// Name of the role or user.
+	Name string `json:"name"`
+
+	// Policies of the tokens issued for the role.
+	Policies []string `json:"policies,omitempty"`
+
+	// TTL of the tokens issued for the role, e.g. "1h".
+	TTL string `json:"ttl,omitempty"`
+
+	// MaxTTL of the tokens issued for the role, e.g. "24h".
+	MaxTTL string `json:"maxTTL,omitempty"`
+
+	// BoundServiceAccountNames are the service accounts allowed to log in. Only used with kubernetes.
+	BoundServiceAccountNames []string `json:"boundServiceAccountNames,omitempty"`
+
+	// BoundServiceAccountNamespaces are the namespaces of the service accounts allowed to log in.
+	// Only used with kubernetes.
+	BoundServiceAccountNamespaces []string `json:"boundServiceAccountNamespaces,omitempty"`
+
+	// BoundAudiences are the audiences that JWTs must have one of. Only used with jwt.
+	BoundAudiences []string `json:"boundAudiences,omitempty"`
+
+	// UserClaim is the claim of the JWT to use as the identity of the user. Only used with jwt.
+	UserClaim string `json:"userClaim,omitempty"`
+
+	// PasswordSecret selects the key of the secret holding the password of the user. Only used with userpass.
+	PasswordSecret *v1.SecretKeySelector `json:"passwordSecret,omitempty"`
+
+	// Options are additional parameters of the role, passed to vault as is.
+	Options map[string]string `json:"options,omitempty"`
+}
+
+type VaultAuthMethodStatus struct {
+	SyncStatus `json:",inline"`
+
+	// Path the auth method is enabled at.
+	Path string `json:"path,omitempty"`
+
+	// Roles are the names of the roles written to vault.
+	Roles []string `json:"roles,omitempty"`
+}
+
+// GetPath returns the path the auth method is enabled at.
+func (s *VaultAuthMethodSpec) GetPath() string {
+	if len(s.Path) != 0 {
+		return s.Path
+	}
+	return string(s.Type)
+}
@@ -0,0 +1,186 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// BackupPhase is the phase of a vault backup.
+type BackupPhase string
+
+const (
+	BackupPhaseNone      BackupPhase = ""
+	BackupPhaseRunning   BackupPhase = "Running"
+	BackupPhaseSucceeded BackupPhase = "Succeeded"
+	BackupPhaseFailed    BackupPhase = "Failed"
+)
+
+const defaultMaxFailedBackups = 3
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type VaultBackupList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []VaultBackup `json:"items"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VaultBackup is a point-in-time snapshot of the storage of a vault service, taken once by the vault operator.
+type VaultBackup struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              VaultBackupSpec   `json:"spec"`
+	Status            VaultBackupStatus `json:"status,omitempty"`
+}
+
+type VaultBackupSpec struct {
+	// VaultService is the name of the vault service in the same namespace to back up.
+	VaultService string `json:"vaultService"`
+
+	// S3 saves the snapshot to S3 or an S3 compatible object store.
+	// Exactly one of S3 and PVC must be set.
+	S3 *S3BackupTarget `json:"s3,omitempty"`
+
+	// PVC saves the snapshot to a persistent volume claim.
+	// Exactly one of S3 and PVC must be set.
+	PVC *PVCBackupTarget `json:"pvc,omitempty"`
+
+	// DeleteSnapshot makes the operator delete the snapshot when the VaultBackup is deleted.
+	// It is set on the backups taken on the schedule of a vault service, which are pruned by deleting them.
+	DeleteSnapshot bool `json:"deleteSnapshot,omitempty"`
+}
+
+// BackupPolicy makes the operator back up a vault service on a schedule.
+// Each backup is a VaultBackup named "<vault service>-<time>", e.g. "example-20180101-000000".
+type BackupPolicy struct {
+	// Schedule of the backups in cron syntax, in UTC, e.g. "0 */6 * * *".
+	Schedule string `json:"schedule"`
+
+	// S3 saves the snapshots to S3 or an S3 compatible object store.
+	// Path is "<s3-bucket-name>/<prefix>", and every snapshot is saved as "<prefix>/<backup name>.backup".
+	// Exactly one of S3 and PVC must be set.
+	S3 *S3BackupTarget `json:"s3,omitempty"`
+
+	// PVC saves the snapshots to a persistent volume claim.
+	// Path is the directory in the volume, and every snapshot is saved as "<path>/<backup name>.db".
+	// Exactly one of S3 and PVC must be set.
+	PVC *PVCBackupTarget `json:"pvc,omitempty"`
+
+	// Retention defines which backups are pruned, deleting their snapshots.
+	// If it is not set, all backups are kept.
+	Retention *BackupRetention `json:"retention,omitempty"`
+}
+
+// BackupRetention defines which of the backups taken on a schedule are kept.
+// The latest successful backup is always kept.
+type BackupRetention struct {
+	// MaxBackups is the number of successful backups to keep.
+	// Failed backups are pruned once a later backup succeeds.
+	// Default: all backups are kept.
+	MaxBackups int `json:"maxBackups,omitempty"`
+
+	// MaxFailedBackups is the number of failed backups to keep while no later backup has succeeded.
+	// Default: 3
+	MaxFailedBackups int `json:"maxFailedBackups,omitempty"`
+
+	// MaxAge of the backups to keep, e.g. "720h".
+	// Default: backups are kept regardless of their age.
+	MaxAge string `json:"maxAge,omitempty"`
+}
+
+// GetMaxFailedBackups returns the number of failed backups to keep.
+func (r *BackupRetention) GetMaxFailedBackups() int {
+	if r.MaxFailedBackups == 0 {
+		return defaultMaxFailedBackups
+	}
+	return r.MaxFailedBackups
+}
+
+// RestoreSource is the backup a vault service is restored from.
+// etcd can only be restored from S3, so the backup must be saved to S3.
+type RestoreSource struct {
+	// Backup is the name of a successful VaultBackup in the same namespace, saved to S3.
+	// Exactly one of Backup and S3 must be set.
+	Backup string `json:"backup,omitempty"`
+
+	// S3 is where on S3 the snapshot is, e.g. one saved by a VaultBackup that is gone.
+	// Exactly one of Backup and S3 must be set.
+	S3 *S3BackupTarget `json:"s3,omitempty"`
+}
+
+// S3BackupTarget is where on S3 a snapshot is saved.
+type S3BackupTarget struct {
+	// Path of the snapshot, in the format "<s3-bucket-name>/<path-to-backup-file>".
+	Path string `json:"path"`
+
+	// AWSSecret is the name of the secret in the same namespace holding the
+	// AWS "credentials" and "config" files, with the profile "default".
+	AWSSecret string `json:"awsSecret"`
+
+	// Endpoint of an S3 compatible object store.
+	// Default: AWS S3.
+	Endpoint string `json:"endpoint,omitempty"`
+}
+
+// PVCBackupTarget is where on a persistent volume claim a snapshot is saved.
+type PVCBackupTarget struct {
+	// ClaimName is the name of the persistent volume claim in the same namespace.
+	ClaimName string `json:"claimName"`
+
+	// Path of the snapshot file in the volume.
+	// Default: "<name of the VaultBackup>.db".
+	Path string `json:"path,omitempty"`
+}
+
+type VaultBackupStatus struct {
+	// Phase is Running while the snapshot is taken, and Succeeded or Failed once it is done.
+	Phase BackupPhase `json:"phase,omitempty"`
+
+	// Reason is a brief CamelCase reason why the backup failed or has not started.
+	Reason string `json:"reason,omitempty"`
+
+	// Message says why the backup failed or has not started.
+	Message string `json:"message,omitempty"`
+
+	// Storage is the storage backend of the vault service that is backed up, e.g. "etcd".
+	Storage string `json:"storage,omitempty"`
+
+	// Location of the snapshot, e.g. "s3://mybucket/vault.backup" or "pvc://backups/vault.backup".
+	Location string `json:"location,omitempty"`
+
+	// Size of the snapshot in bytes.
+	Size int64 `json:"size,omitempty"`
+
+	// Revision of the storage the snapshot was taken at.
+	Revision int64 `json:"revision,omitempty"`
+
+	// EtcdVersion is the version of the etcd server the snapshot was taken from.
+	EtcdVersion string `json:"etcdVersion,omitempty"`
+
+	// CompletionTime is when the snapshot was saved.
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+}
+
+// GetPath returns the path of the snapshot file in the volume.
+func (t *PVCBackupTarget) GetPath(backupName string) string {
+	if len(t.Path) != 0 {
+		return t.Path
+	}
+	return backupName + ".db"
+}
@@ -0,0 +1,71 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NetworkPolicy makes the vault operator create network policies that only allow
+// the traffic the vault pods and the etcd pods backing them need.
+type NetworkPolicy struct {
+	// Clients are the sources allowed to reach the vault client port.
+	// If empty, all sources are allowed.
+	Clients []networkingv1.NetworkPolicyPeer `json:"clients,omitempty"`
+
+	// Scrapers are the sources allowed to reach the prometheus port of the statsd exporter.
+	// If empty, all sources are allowed.
+	Scrapers []networkingv1.NetworkPolicyPeer `json:"scrapers,omitempty"`
+
+	// OperatorSelector selects the vault operator pods in the namespace of the vault service,
+	// which poll the vault pods.
+	// Default: name=vault-operator.
+	OperatorSelector *metav1.LabelSelector `json:"operatorSelector,omitempty"`
+
+	// EtcdOperatorSelector selects the etcd operator pods in the namespace of the vault service,
+	// which manage the etcd cluster backing vault.
+	// Default: name=etcd-operator.
+	EtcdOperatorSelector *metav1.LabelSelector `json:"etcdOperatorSelector,omitempty"`
+
+	// EtcdBackupOperatorSelector selects the etcd backup operator pods in the namespace of the vault service,
+	// which take the etcd snapshots of vault backups to S3.
+	// Default: name=etcd-backup-operator.
+	EtcdBackupOperatorSelector *metav1.LabelSelector `json:"etcdBackupOperatorSelector,omitempty"`
+}
+
+// GetOperatorSelector returns the selector of the vault operator pods.
+func (np *NetworkPolicy) GetOperatorSelector() *metav1.LabelSelector {
+	if np.OperatorSelector != nil {
+		return np.OperatorSelector
+	}
+	return &metav1.LabelSelector{MatchLabels: map[string]string{"name": "vault-operator"}}
+}
+
+// GetEtcdOperatorSelector returns the selector of the etcd operator pods.
+func (np *NetworkPolicy) GetEtcdOperatorSelector() *metav1.LabelSelector {
+	if np.EtcdOperatorSelector != nil {
+		return np.EtcdOperatorSelector
+	}
+	return &metav1.LabelSelector{MatchLabels: map[string]string{"name": "etcd-operator"}}
+}
+
+// GetEtcdBackupOperatorSelector returns the selector of the etcd backup operator pods.
+func (np *NetworkPolicy) GetEtcdBackupOperatorSelector() *metav1.LabelSelector {
+	if np.EtcdBackupOperatorSelector != nil {
+		return np.EtcdBackupOperatorSelector
+	}
+	return &metav1.LabelSelector{MatchLabels: map[string]string{"name": "etcd-backup-operator"}}
+}
@@ -0,0 +1,75 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+// OperatorAuthMethod is the auth method the vault operator logs in to vault with.
+type OperatorAuthMethod string
+
+const (
+	OperatorAuthAppRole    OperatorAuthMethod = "approle"
+	OperatorAuthKubernetes OperatorAuthMethod = "kubernetes"
+)
+
+const (
+	defaultOperatorTokenTTL    = "1h"
+	defaultOperatorTokenMaxTTL = "24h"
+)
+
+// OperatorAuthPolicy makes the vault operator set up its own vault identity, with a policy covering only
+// what the operator does in vault. The token in spec.operatorTokenSecret is only used to set it up.
+type OperatorAuthPolicy struct {
+	// Method is the auth method the operator logs in with: approle or kubernetes.
+	// The kubernetes auth method needs spec.kubernetesAuth, so that vault can review the
+	// service account token of the operator.
+	Method OperatorAuthMethod `json:"method"`
+
+	// Policies are additional vault policies of the operator, e.g. for writing the config
+	// of the secret engines declared with VaultSecretEngine resources.
+	Policies []string `json:"policies,omitempty"`
+
+	// TokenTTL of the operator token, e.g. "1h". The token is renewed when half of it has passed.
+	// Default: "1h".
+	TokenTTL string `json:"tokenTTL,omitempty"`
+
+	// TokenMaxTTL of the operator token, e.g. "24h". The operator logs in again before the token
+	// cannot be renewed any longer.
+	// Default: "24h".
+	TokenMaxTTL string `json:"tokenMaxTTL,omitempty"`
+
+	// RevokeBootstrapToken makes the operator revoke the token in spec.operatorTokenSecret,
+	// e.g. the initial root token, once its own identity is set up.
+	RevokeBootstrapToken bool `json:"revokeBootstrapToken,omitempty"`
+}
+
+// GetTokenTTL returns the TTL of the operator token.
+func (p *OperatorAuthPolicy) GetTokenTTL() string {
+	if len(p.TokenTTL) != 0 {
+		return p.TokenTTL
+	}
+	return defaultOperatorTokenTTL
+}
+
+// GetTokenMaxTTL returns the max TTL of the operator token.
+func (p *OperatorAuthPolicy) GetTokenMaxTTL() string {
+	if len(p.TokenMaxTTL) != 0 {
+		return p.TokenMaxTTL
+	}
+	return defaultOperatorTokenMaxTTL
+}
+
+// OperatorCredentialSecretName returns the name of the secret holding the encrypted vault credential of the operator.
+func OperatorCredentialSecretName(vaultName string) string {
+	return vaultName + "-operator-credential"
+}
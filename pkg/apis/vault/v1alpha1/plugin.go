@@ -0,0 +1,37 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+// Plugin is an external vault plugin, e.g. a secret engine or auth method.
+// The plugin binary is copied from the given image into the plugin directory of vault pods,
+// and registered in vault's plugin catalog under the given name.
+type Plugin struct {
+	// Name of the plugin in vault's plugin catalog.
+	// It is also the file name of the binary in the plugin directory.
+	Name string `json:"name"`
+
+	// Image containing the plugin binary. The image must provide "cp".
+	Image string `json:"image"`
+
+	// Path of the plugin binary in the image.
+	Path string `json:"path"`
+
+	// SHA256 is the hex encoded SHA256 checksum of the plugin binary.
+	// Vault refuses to run the plugin if the binary does not match it.
+	SHA256 string `json:"sha256"`
+
+	// Args are the arguments passed to the plugin binary when vault runs it.
+	Args []string `json:"args,omitempty"`
+}
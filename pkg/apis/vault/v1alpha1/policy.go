@@ -0,0 +1,58 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type VaultPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []VaultPolicy `json:"items"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VaultPolicy is an ACL policy that the vault operator writes to a vault service.
+type VaultPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              VaultPolicySpec `json:"spec"`
+	Status            SyncStatus      `json:"status,omitempty"`
+}
+
+type VaultPolicySpec struct {
+	// VaultService is the name of the vault service in the same namespace to write the policy to.
+	VaultService string `json:"vaultService"`
+
+	// Name of the policy in vault.
+	// Default: the name of the VaultPolicy.
+	Name string `json:"name,omitempty"`
+
+	// Policy is the policy document in HCL or JSON.
+	Policy string `json:"policy"`
+}
+
+// GetPolicyName returns the name of the policy in vault.
+func (p *VaultPolicy) GetPolicyName() string {
+	if len(p.Spec.Name) != 0 {
+		return p.Spec.Name
+	}
+	return p.Name
+}
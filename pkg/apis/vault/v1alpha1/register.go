@@ -23,6 +23,18 @@ import (
 const (
 	VaultServiceKind   = "VaultService"
 	VaultServicePlural = "vaultservices"
+
+	VaultPolicyKind   = "VaultPolicy"
+	VaultPolicyPlural = "vaultpolicies"
+
+	VaultAuthMethodKind   = "VaultAuthMethod"
+	VaultAuthMethodPlural = "vaultauthmethods"
+
+	VaultSecretEngineKind   = "VaultSecretEngine"
+	VaultSecretEnginePlural = "vaultsecretengines"
+
+	VaultBackupKind   = "VaultBackup"
+	VaultBackupPlural = "vaultbackups"
 )
 
 var (
@@ -41,11 +53,24 @@ const groupName = "vault.security.coreos.com"
 // SchemeGroupVersion is the group version used to register these objects.
 var SchemeGroupVersion = schema.GroupVersion{Group: groupName, Version: "v1alpha1"}
 
+// Resource takes an unqualified resource and returns a Group qualified GroupResource
+func Resource(resource string) schema.GroupResource {
+	return SchemeGroupVersion.WithResource(resource).GroupResource()
+}
+
 // addKnownTypes adds the set of types defined in this package to the supplied scheme.
 func addKnownTypes(scheme *runtime.Scheme) error {
 	scheme.AddKnownTypes(SchemeGroupVersion,
 		&VaultService{},
 		&VaultServiceList{},
+		&VaultPolicy{},
+		&VaultPolicyList{},
+		&VaultAuthMethod{},
+		&VaultAuthMethodList{},
+		&VaultSecretEngine{},
+		&VaultSecretEngineList{},
+		&VaultBackup{},
+		&VaultBackupList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
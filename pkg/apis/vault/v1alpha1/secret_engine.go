@@ -0,0 +1,246 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SecretEngineType is the type of a vault secret engine.
+type SecretEngineType string
+
+const (
+	SecretEngineKVv2     SecretEngineType = "kv-v2"
+	SecretEnginePKI      SecretEngineType = "pki"
+	SecretEngineTransit  SecretEngineType = "transit"
+	SecretEngineDatabase SecretEngineType = "database"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+type VaultSecretEngineList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []VaultSecretEngine `json:"items"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// VaultSecretEngine is a secret engine, with its config, that the vault operator mounts in a vault service.
+type VaultSecretEngine struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata"`
+	Spec              VaultSecretEngineSpec   `json:"spec"`
+	Status            VaultSecretEngineStatus `json:"status,omitempty"`
+}
+
+type VaultSecretEngineSpec struct {
+	// VaultService is the name of the vault service in the same namespace to mount the secret engine in.
+	VaultService string `json:"vaultService"`
+
+	// Type of the secret engine: kv-v2, pki, transit or database.
+	Type SecretEngineType `json:"type"`
+
+	// Path the secret engine is mounted at.
+	// Default: the type of the secret engine.
+	// This field cannot be updated once the secret engine is mounted.
+	Path string `json:"path,omitempty"`
+
+	// Description of the secret engine in vault. It is only set when the secret engine is mounted.
+	Description string `json:"description,omitempty"`
+
+	// DefaultLeaseTTL of the secret engine, e.g. "1h".
+	// Default: the default lease TTL of vault.
+	DefaultLeaseTTL string `json:"defaultLeaseTTL,omitempty"`
+
+	// MaxLeaseTTL of the secret engine, e.g. "24h".
+	// Default: the max lease TTL of vault.
+	MaxLeaseTTL string `json:"maxLeaseTTL,omitempty"`
+
+	// CorrectDrift makes the operator mount the secret engine again if it is disabled,
+	// and tune it back to its spec if its lease TTLs are changed in vault directly.
+	// Otherwise such drift is only reported in the status.
+	CorrectDrift bool `json:"correctDrift,omitempty"`
+
+	// KV is the config of the kv-v2 secret engine.
+	KV *KVConfig `json:"kv,omitempty"`
+
+	// PKI is the config of the pki secret engine.
+	PKI *PKIConfig `json:"pki,omitempty"`
+
+	// Transit is the config of the transit secret engine.
+	Transit *TransitConfig `json:"transit,omitempty"`
+
+	// Database is the config of the database secret engine.
+	Database *DatabaseConfig `json:"database,omitempty"`
+}
+
+// KVConfig is the config of the kv-v2 secret engine.
+type KVConfig struct {
+	// MaxVersions is the number of versions kept per key. Default: 10.
+	MaxVersions int `json:"maxVersions,omitempty"`
+
+	// CASRequired makes every write require the check-and-set parameter.
+	CASRequired bool `json:"casRequired,omitempty"`
+}
+
+// PKIConfig is the config of the pki secret engine.
+type PKIConfig struct {
+	// RootCA makes the operator generate a self-signed root CA when the secret engine is mounted.
+	RootCA *PKIRootCA `json:"rootCA,omitempty"`
+
+	// IssuingCertificates are the URLs of the issuing CA cert.
+	IssuingCertificates []string `json:"issuingCertificates,omitempty"`
+
+	// CRLDistributionPoints are the URLs of the CRL.
+	CRLDistributionPoints []string `json:"crlDistributionPoints,omitempty"`
+
+	// Roles that certs are issued for.
+	Roles []PKIRole `json:"roles,omitempty"`
+}
+
+// PKIRootCA is a self-signed root CA generated in the pki secret engine.
+type PKIRootCA struct {
+	// CommonName of the CA cert.
+	CommonName string `json:"commonName"`
+
+	// TTL of the CA cert, e.g. "87600h".
+	TTL string `json:"ttl,omitempty"`
+}
+
+// PKIRole is a role of the pki secret engine.
+type PKIRole struct {
+	// Name of the role.
+	Name string `json:"name"`
+
+	// AllowedDomains are the domains that certs can be issued for.
+	AllowedDomains []string `json:"allowedDomains,omitempty"`
+
+	// AllowSubdomains allows issuing certs for the subdomains of the allowed domains.
+	AllowSubdomains bool `json:"allowSubdomains,omitempty"`
+
+	// MaxTTL of the issued certs, e.g. "72h".
+	MaxTTL string `json:"maxTTL,omitempty"`
+
+	// Options are additional parameters of the role, passed to vault as is.
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// TransitConfig is the config of the transit secret engine.
+type TransitConfig struct {
+	// Keys to create. Keys are never deleted by the operator.
+	Keys []TransitKey `json:"keys,omitempty"`
+}
+
+// TransitKey is an encryption key of the transit secret engine.
+type TransitKey struct {
+	// Name of the key.
+	Name string `json:"name"`
+
+	// Type of the key, e.g. "aes256-gcm96". Default: the default key type of vault.
+	// This field cannot be updated once the key is created.
+	Type string `json:"type,omitempty"`
+}
+
+// DatabaseConfig is the config of the database secret engine.
+type DatabaseConfig struct {
+	// Connections to the databases.
+	Connections []DatabaseConnection `json:"connections,omitempty"`
+
+	// Roles that database credentials are generated for.
+	Roles []DatabaseRole `json:"roles,omitempty"`
+}
+
+// DatabaseConnection is a connection of the database secret engine to a database.
+type DatabaseConnection struct {
+	// Name of the connection.
+	Name string `json:"name"`
+
+	// PluginName is the name of the database plugin, e.g. "postgresql-database-plugin".
+	PluginName string `json:"pluginName"`
+
+	// ConnectionURL is the connection string of the database.
+	// It can refer to the credentials as "{{username}}" and "{{password}}".
+	ConnectionURL string `json:"connectionURL"`
+
+	// AllowedRoles are the roles allowed to use the connection.
+	AllowedRoles []string `json:"allowedRoles,omitempty"`
+
+	// UsernameSecret selects the key of the secret holding the username for the database.
+	UsernameSecret *v1.SecretKeySelector `json:"usernameSecret,omitempty"`
+
+	// PasswordSecret selects the key of the secret holding the password for the database.
+	PasswordSecret *v1.SecretKeySelector `json:"passwordSecret,omitempty"`
+
+	// Options are additional parameters of the connection, passed to vault as is.
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// DatabaseRole is a role of the database secret engine.
+type DatabaseRole struct {
+	// Name of the role.
+	Name string `json:"name"`
+
+	// DBName is the name of the connection the credentials are created with.
+	DBName string `json:"dbName"`
+
+	// CreationStatements are the statements creating the credentials.
+	CreationStatements []string `json:"creationStatements"`
+
+	// RevocationStatements are the statements revoking the credentials.
+	RevocationStatements []string `json:"revocationStatements,omitempty"`
+
+	// DefaultTTL of the credentials, e.g. "1h".
+	DefaultTTL string `json:"defaultTTL,omitempty"`
+
+	// MaxTTL of the credentials, e.g. "24h".
+	MaxTTL string `json:"maxTTL,omitempty"`
+}
+
+type VaultSecretEngineStatus struct {
+	SyncStatus `json:",inline"`
+
+	// Path the secret engine is mounted at.
+	Path string `json:"path,omitempty"`
+
+	// DefaultLeaseTTL last set on the secret engine by the operator.
+	DefaultLeaseTTL string `json:"defaultLeaseTTL,omitempty"`
+
+	// MaxLeaseTTL last set on the secret engine by the operator.
+	MaxLeaseTTL string `json:"maxLeaseTTL,omitempty"`
+
+	// RootCAGenerated is true once the root CA of the pki secret engine is generated.
+	RootCAGenerated bool `json:"rootCAGenerated,omitempty"`
+
+	// Roles are the names of the pki or database roles written to vault.
+	Roles []string `json:"roles,omitempty"`
+
+	// Connections are the names of the database connections written to vault.
+	Connections []string `json:"connections,omitempty"`
+
+	// Drift lists the changes made to the secret engine in vault directly,
+	// which are not corrected because spec.correctDrift is not set.
+	Drift []string `json:"drift,omitempty"`
+}
+
+// GetPath returns the path the secret engine is mounted at.
+func (s *VaultSecretEngineSpec) GetPath() string {
+	if len(s.Path) != 0 {
+		return s.Path
+	}
+	return string(s.Type)
+}
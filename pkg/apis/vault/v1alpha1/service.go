@@ -0,0 +1,104 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"net/url"
+
+	"k8s.io/api/core/v1"
+)
+
+// ServicePolicy defines how the vault service is exposed.
+type ServicePolicy struct {
+	// Type of the vault service: ClusterIP, NodePort or LoadBalancer.
+	// Default: ClusterIP.
+	Type v1.ServiceType `json:"type,omitempty"`
+
+	// Annotations specifies the annotations to attach to the vault service,
+	// e.g. to configure the cloud load balancer.
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// LoadBalancerSourceRanges restricts the client IPs allowed through the load balancer.
+	// Only used with the LoadBalancer type.
+	LoadBalancerSourceRanges []string `json:"loadBalancerSourceRanges,omitempty"`
+
+	// ExternalTrafficPolicy of the vault service: Cluster or Local.
+	// Only used with the NodePort and LoadBalancer types.
+	ExternalTrafficPolicy v1.ServiceExternalTrafficPolicyType `json:"externalTrafficPolicy,omitempty"`
+
+	// ExternalAddress is the URL clients outside of the Kubernetes cluster reach vault at,
+	// e.g. "https://vault.example.com:8200". Vault advertises it as its API address.
+	// It defaults to the ingress host if an ingress is specified.
+	// The API address of vault pods is set when the vault deployment is created,
+	// and does not follow later changes.
+	ExternalAddress string `json:"externalAddress,omitempty"`
+
+	// Ingress specifies the ingress exposing the vault service.
+	// If it is not set, no ingress is created.
+	// The API address of vault pods is set when the vault deployment is created,
+	// and does not follow later changes.
+	Ingress *IngressPolicy `json:"ingress,omitempty"`
+
+	// Route specifies the OpenShift route exposing the vault service.
+	// If it is not set, no route is created.
+	// The API address of vault pods is set when the vault deployment is created,
+	// and does not follow later changes.
+	Route *RoutePolicy `json:"route,omitempty"`
+}
+
+// IngressPolicy defines the ingress exposing the vault service.
+// TLS is passed through to vault, so that vault terminates TLS with its own server cert.
+type IngressPolicy struct {
+	// Host is the DNS name vault is reached at through the ingress.
+	Host string `json:"host"`
+
+	// Annotations specifies the annotations to attach to the ingress, e.g. the ingress class.
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// RoutePolicy defines the OpenShift route exposing the vault service.
+// TLS is passed through to vault, so that vault terminates TLS with its own server cert.
+type RoutePolicy struct {
+	// Host is the DNS name vault is reached at through the route.
+	Host string `json:"host"`
+
+	// Annotations specifies the annotations to attach to the route.
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// GetExternalAddress returns the URL clients outside of the Kubernetes cluster reach vault at,
+// or an empty string if vault is not exposed outside of the cluster under a known address.
+func (sp *ServicePolicy) GetExternalAddress() string {
+	switch {
+	case sp == nil:
+		return ""
+	case len(sp.ExternalAddress) != 0:
+		return sp.ExternalAddress
+	case sp.Ingress != nil:
+		return "https://" + sp.Ingress.Host
+	case sp.Route != nil:
+		return "https://" + sp.Route.Host
+	}
+	return ""
+}
+
+// GetExternalHost returns the host name of the external address, or an empty string if there is none.
+func (sp *ServicePolicy) GetExternalHost() string {
+	u, err := url.Parse(sp.GetExternalAddress())
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
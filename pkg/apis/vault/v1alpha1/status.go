@@ -0,0 +1,82 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"time"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NewCondition returns a vault service condition with the given type, status, reason and message.
+func NewCondition(t VaultServiceConditionType, status v1.ConditionStatus, reason, message string) VaultServiceCondition {
+	now := metav1.NewTime(time.Now())
+	return VaultServiceCondition{
+		Type:               t,
+		Status:             status,
+		LastUpdateTime:     now,
+		LastTransitionTime: now,
+		Reason:             reason,
+		Message:            message,
+	}
+}
+
+// GetCondition returns the condition of the given type, or nil if it does not exist.
+func (s *VaultServiceStatus) GetCondition(t VaultServiceConditionType) *VaultServiceCondition {
+	for i := range s.Conditions {
+		if s.Conditions[i].Type == t {
+			return &s.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// SetCondition adds or updates the condition of the same type.
+// LastTransitionTime is kept if the status doesn't change.
+// It returns false if the condition was already set with the same status, reason and message.
+func (s *VaultServiceStatus) SetCondition(c VaultServiceCondition) bool {
+	cur := s.GetCondition(c.Type)
+	if cur == nil {
+		s.Conditions = append(s.Conditions, c)
+		return true
+	}
+	if cur.Status == c.Status && cur.Reason == c.Reason && cur.Message == c.Message {
+		return false
+	}
+	if cur.Status == c.Status {
+		c.LastTransitionTime = cur.LastTransitionTime
+	}
+	*cur = c
+	return true
+}
+
+// RemoveCondition removes the condition of the given type.
+// It returns false if there is no such condition.
+func (s *VaultServiceStatus) RemoveCondition(t VaultServiceConditionType) bool {
+	for i := range s.Conditions {
+		if s.Conditions[i].Type == t {
+			s.Conditions = append(s.Conditions[:i], s.Conditions[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// IsConditionTrue checks if the condition of the given type exists and has status "True".
+func (s *VaultServiceStatus) IsConditionTrue(t VaultServiceConditionType) bool {
+	c := s.GetCondition(t)
+	return c != nil && c.Status == v1.ConditionTrue
+}
@@ -0,0 +1,88 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetCondition(t *testing.T) {
+	past := metav1.NewTime(time.Now().Add(-time.Hour))
+	cur := VaultServiceCondition{
+		Type:               VaultServiceTLSValid,
+		Status:             v1.ConditionFalse,
+		LastUpdateTime:     past,
+		LastTransitionTime: past,
+		Reason:             "InvalidTLSSecret",
+		Message:            "missing server.key",
+	}
+
+	tests := []struct {
+		name        string
+		c           VaultServiceCondition
+		wantChanged bool
+		// wantTransition tells if LastTransitionTime should be the one of the new condition.
+		wantTransition bool
+	}{{
+		name:        "same condition",
+		c:           NewCondition(VaultServiceTLSValid, v1.ConditionFalse, "InvalidTLSSecret", "missing server.key"),
+		wantChanged: false,
+	}, {
+		name:           "same status with another message",
+		c:              NewCondition(VaultServiceTLSValid, v1.ConditionFalse, "InvalidTLSSecret", "missing server.crt"),
+		wantChanged:    true,
+		wantTransition: false,
+	}, {
+		name:           "status changed",
+		c:              NewCondition(VaultServiceTLSValid, v1.ConditionTrue, "", ""),
+		wantChanged:    true,
+		wantTransition: true,
+	}}
+	for _, tt := range tests {
+		s := &VaultServiceStatus{Conditions: []VaultServiceCondition{cur}}
+		if changed := s.SetCondition(tt.c); changed != tt.wantChanged {
+			t.Errorf("%s: SetCondition() = %v, want %v", tt.name, changed, tt.wantChanged)
+		}
+		if len(s.Conditions) != 1 {
+			t.Fatalf("%s: want 1 condition, got %d", tt.name, len(s.Conditions))
+		}
+		got := s.Conditions[0]
+		if !tt.wantChanged {
+			if got != cur {
+				t.Errorf("%s: condition should not change, got %+v", tt.name, got)
+			}
+			continue
+		}
+		if got.Status != tt.c.Status || got.Message != tt.c.Message {
+			t.Errorf("%s: condition not updated, got %+v", tt.name, got)
+		}
+		wantTransition := past
+		if tt.wantTransition {
+			wantTransition = tt.c.LastTransitionTime
+		}
+		if !got.LastTransitionTime.Equal(&wantTransition) {
+			t.Errorf("%s: LastTransitionTime = %v, want %v", tt.name, got.LastTransitionTime, wantTransition)
+		}
+	}
+
+	s := &VaultServiceStatus{}
+	if !s.SetCondition(cur) || s.GetCondition(VaultServiceTLSValid) == nil {
+		t.Errorf("new condition: want the condition to be added, got %+v", s.Conditions)
+	}
+}
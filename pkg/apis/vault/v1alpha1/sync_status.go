@@ -0,0 +1,54 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+// VaultFinalizer is the finalizer the vault operator puts on the objects it writes to vault,
+// so that it can remove them from vault before the objects are deleted.
+// It is also put on vault services with the kubernetes auth method enabled,
+// so that their cluster scoped token review binding is deleted with them.
+const VaultFinalizer = "vault.security.coreos.com/cleanup"
+
+// SyncStatus is the status of an object that the vault operator writes to a vault service.
+type SyncStatus struct {
+	// Synced is true if the last attempt to write the object to vault succeeded.
+	Synced bool `json:"synced"`
+
+	// Reason is a brief CamelCase reason why the last attempt failed.
+	Reason string `json:"reason,omitempty"`
+
+	// Message is the error of the last attempt, if it failed.
+	Message string `json:"message,omitempty"`
+}
+
+// HasFinalizer returns true if the given finalizers contain the vault finalizer.
+func HasFinalizer(finalizers []string) bool {
+	for _, f := range finalizers {
+		if f == VaultFinalizer {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveFinalizer returns the given finalizers without the vault finalizer.
+func RemoveFinalizer(finalizers []string) []string {
+	var fs []string
+	for _, f := range finalizers {
+		if f != VaultFinalizer {
+			fs = append(fs, f)
+		}
+	}
+	return fs
+}
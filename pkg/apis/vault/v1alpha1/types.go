@@ -73,12 +73,164 @@ type VaultServiceSpec struct {
 
 	// TLS policy of vault nodes
 	TLS *TLSPolicy `json:"TLS,omitempty"`
+
+	// Service defines how the vault service is exposed.
+	// Default: a ClusterIP service.
+	Service *ServicePolicy `json:"service,omitempty"`
+
+	// KubernetesAuth makes the operator bind the service account of vault pods to the
+	// system:auth-delegator cluster role, which vault's kubernetes auth method needs
+	// to review the service account tokens clients log in with.
+	KubernetesAuth bool `json:"kubernetesAuth,omitempty"`
+
+	// NetworkPolicy makes the operator restrict the traffic to the vault and etcd pods.
+	// If it is not set, no network policy is created.
+	NetworkPolicy *NetworkPolicy `json:"networkPolicy,omitempty"`
+
+	// Plugins are the external plugins installed into vault pods and
+	// registered in vault's plugin catalog once vault is unsealed.
+	// This field cannot be updated once the CR is created.
+	Plugins []Plugin `json:"plugins,omitempty"`
+
+	// Audit defines the audit devices that the operator enables in vault.
+	// If it is not set, the operator leaves audit devices alone.
+	Audit *AuditPolicy `json:"audit,omitempty"`
+
+	// OperatorTokenSecret is the name of the secret holding the vault token that the operator
+	// uses for vault API calls that need authentication, e.g. registering plugins.
+	// The token is read from the "token" key of the secret.
+	// With OperatorAuth, the token is only used to set up the operator's own vault identity.
+	OperatorTokenSecret string `json:"operatorTokenSecret,omitempty"`
+
+	// OperatorAuth makes the operator set up and use its own vault identity with least privilege,
+	// instead of the token in OperatorTokenSecret.
+	OperatorAuth *OperatorAuthPolicy `json:"operatorAuth,omitempty"`
+
+	// Backup makes the operator back up the storage of vault on a schedule.
+	// If it is not set, the vault service is only backed up with VaultBackup resources.
+	Backup *BackupPolicy `json:"backup,omitempty"`
+
+	// RestoreFrom makes the operator restore the storage of vault from a backup when the vault service is created,
+	// before vault is deployed. The vault service only turns "Running" once vault sees the restored data.
+	// It is ignored once the vault service is running.
+	RestoreFrom *RestoreSource `json:"restoreFrom,omitempty"`
+
+	// Upgrade defines how vault pods are upgraded when the version or base image changes.
+	// Default: the rolling strategy.
+	Upgrade *UpgradePolicy `json:"upgrade,omitempty"`
 }
 
+// OperatorTokenKey is the key of the vault token in the operator token secret
+const OperatorTokenKey = "token"
+
 // PodPolicy defines the policy for pods owned by vault operator.
 type PodPolicy struct {
 	// Resources is the resource requirements for the containers.
+	// It is also used for the etcd cluster backing the vault service.
 	Resources v1.ResourceRequirements `json:"resources,omitempty"`
+
+	// VaultResources is the resource requirements for the vault container.
+	// If set, it overrides Resources for the vault container.
+	VaultResources *v1.ResourceRequirements `json:"vaultResources,omitempty"`
+
+	// ExporterResources is the resource requirements for the statsd exporter container.
+	// If set, it overrides Resources for the statsd exporter container.
+	ExporterResources *v1.ResourceRequirements `json:"exporterResources,omitempty"`
+
+	// NodeSelector specifies a map of key-value pairs. For the pod to be eligible
+	// to run on a node, the node must have each of the indicated key-value pairs as
+	// labels.
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Affinity is the scheduling constraints on vault pods.
+	// If set, it replaces the default anti-affinity derived from TopologySpread.
+	Affinity *v1.Affinity `json:"affinity,omitempty"`
+
+	// TopologySpread defines how vault pods are spread across topology domains
+	// when Affinity is not set.
+	// Default: vault pods prefer to run on different nodes and zones.
+	TopologySpread *TopologySpreadPolicy `json:"topologySpread,omitempty"`
+
+	// Tolerations specifies the pod's tolerations.
+	Tolerations []v1.Toleration `json:"tolerations,omitempty"`
+
+	// PriorityClassName is the name of the priority class of vault pods.
+	PriorityClassName string `json:"priorityClassName,omitempty"`
+
+	// Labels specifies the labels to attach to vault pods.
+	// "app", "vault_cluster" and "vault-active" labels are reserved for the internal use of the vault operator.
+	// Do not overwrite them.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations specifies the annotations to attach to vault pods.
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// ServiceAccountName is the name of the ServiceAccount to run vault pods as.
+	// Default: the ServiceAccount the operator creates for the vault service.
+	ServiceAccountName string `json:"serviceAccountName,omitempty"`
+
+	// ImagePullSecrets are the secrets used to pull the vault and exporter images.
+	ImagePullSecrets []v1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+
+	// SecurityContext is the pod-level security attributes of vault pods.
+	SecurityContext *v1.PodSecurityContext `json:"securityContext,omitempty"`
+
+	// ContainerSecurityContext is the security context of every container in vault pods.
+	// The IPC_LOCK capability is always added to the vault container, since vault needs to mlock memory.
+	ContainerSecurityContext *v1.SecurityContext `json:"containerSecurityContext,omitempty"`
+
+	// Env is the list of extra environment variables to set in the vault container, e.g. VAULT_LOG_LEVEL.
+	// VAULT_API_ADDR and VAULT_CLUSTER_ADDR are reserved for the internal use of the vault operator.
+	Env []v1.EnvVar `json:"env,omitempty"`
+
+	// VolumeMounts are the extra volumes to mount into the vault container.
+	// They must not use the reserved volume names, or mount over the vault config or TLS directories.
+	VolumeMounts []v1.VolumeMount `json:"volumeMounts,omitempty"`
+
+	// ExtraVolumes are the extra volumes of vault pods, used by VolumeMounts or ExtraContainers.
+	// "vault-config" and "vault-tls-secret" are reserved volume names.
+	ExtraVolumes []v1.Volume `json:"extraVolumes,omitempty"`
+
+	// ExtraContainers are run alongside the vault container in vault pods, e.g. log shippers.
+	// "vault" and "statsd-exporter" are reserved container names.
+	ExtraContainers []v1.Container `json:"extraContainers,omitempty"`
+
+	// InitContainers are run before the containers of vault pods, e.g. to fetch plugins.
+	InitContainers []v1.Container `json:"initContainers,omitempty"`
+}
+
+// TopologySpreadPolicy defines how vault pods are spread across topology domains.
+// It is implemented with pod anti-affinity between the pods of a vault service.
+type TopologySpreadPolicy struct {
+	// TopologyKeys are the node labels whose values define the topology domains
+	// that vault pods are spread across.
+	// Default: ["kubernetes.io/hostname", "failure-domain.beta.kubernetes.io/zone"]
+	TopologyKeys []string `json:"topologyKeys,omitempty"`
+
+	// Required makes the spread a hard scheduling requirement: no two vault pods are
+	// scheduled into the same domain of any topology key.
+	// By default the spread is best effort.
+	Required bool `json:"required,omitempty"`
+}
+
+const (
+	// TopologyKeyHostname is the node label spreading vault pods across nodes.
+	TopologyKeyHostname = "kubernetes.io/hostname"
+	// TopologyKeyZone is the node label spreading vault pods across zones.
+	TopologyKeyZone = "failure-domain.beta.kubernetes.io/zone"
+)
+
+// GetTopologyKeys returns the topology keys of the spread policy, or the default ones if they are not set.
+func (tp *TopologySpreadPolicy) GetTopologyKeys() []string {
+	if tp == nil || len(tp.TopologyKeys) == 0 {
+		return []string{TopologyKeyHostname, TopologyKeyZone}
+	}
+	return tp.TopologyKeys
+}
+
+// IsRequired returns true if the spread policy is a hard scheduling requirement.
+func (tp *TopologySpreadPolicy) IsRequired() bool {
+	return tp != nil && tp.Required
 }
 
 // SetDefaults sets the default vaules for the vault spec and returns true if the spec was changed
@@ -98,10 +250,14 @@ func (v *VaultService) SetDefaults() bool {
 		changed = true
 	}
 	if vs.TLS == nil {
-		vs.TLS = &TLSPolicy{Static: &StaticTLS{
+		vs.TLS = &TLSPolicy{}
+		changed = true
+	}
+	if vs.TLS.Static == nil {
+		vs.TLS.Static = &StaticTLS{
 			ServerSecret: DefaultVaultServerTLSSecretName(v.Name),
 			ClientSecret: DefaultVaultClientTLSSecretName(v.Name),
-		}}
+		}
 		changed = true
 	}
 	return changed
@@ -123,12 +279,68 @@ type VaultServiceStatus struct {
 	// It's the same on client LB service and vault nodes.
 	ClientPort int `json:"clientPort,omitempty"`
 
+	// APIAddress is the address vault advertises to clients, i.e. the external address
+	// if vault is exposed outside of the Kubernetes cluster, or the URL of the vault service otherwise.
+	APIAddress string `json:"apiAddress,omitempty"`
+
 	// VaultStatus is the set of Vault node specific statuses: Active, Standby, and Sealed
 	VaultStatus VaultStatus `json:"vaultStatus"`
 
 	// PodNames of updated Vault nodes. Updated means the Vault container image version
 	// matches the spec's version.
 	UpdatedNodes []string `json:"updatedNodes,omitempty"`
+
+	// Upgrade is the progress of the latest upgrade of vault.
+	Upgrade *UpgradeStatus `json:"upgrade,omitempty"`
+
+	// Conditions represent the latest available observations of the vault service's state.
+	Conditions []VaultServiceCondition `json:"conditions,omitempty"`
+}
+
+type VaultServiceConditionType string
+
+// These are valid conditions of a vault service.
+const (
+	// TLSValid means the TLS secrets referenced by the vault service are valid.
+	// If it is "False", the vault deployment is not rolled out until the secrets are fixed.
+	VaultServiceTLSValid VaultServiceConditionType = "TLSValid"
+	// CertificatesExpiring means at least one TLS cert used by the vault service
+	// expires within the cert expiry window of the TLS policy.
+	VaultServiceCertificatesExpiring VaultServiceConditionType = "CertificatesExpiring"
+	// PluginsRegistered means all plugins of the vault service are registered in vault's plugin catalog.
+	VaultServicePluginsRegistered VaultServiceConditionType = "PluginsRegistered"
+	// AuditEnabled means at least one audit device is enabled in vault, and the ones of spec.audit are enabled.
+	VaultServiceAuditEnabled VaultServiceConditionType = "AuditEnabled"
+	// OperatorCredentialReady means the operator has its own vault identity set up, and a valid token for it.
+	VaultServiceOperatorCredentialReady VaultServiceConditionType = "OperatorCredentialReady"
+	// BackupSucceeded means the latest backup taken on the schedule of spec.backup succeeded.
+	// Its message tells when the vault service was last backed up successfully.
+	VaultServiceBackupSucceeded VaultServiceConditionType = "BackupSucceeded"
+	// Restored means the storage of the vault service was restored from spec.restoreFrom,
+	// and vault sees the restored data. It is "False" while the restore is in progress or if it failed.
+	VaultServiceRestored VaultServiceConditionType = "Restored"
+	// UpgradePaused means the canary of an upgrade failed, and the rest of the vault pods are not upgraded.
+	// Its message says why the canary failed.
+	VaultServiceUpgradePaused VaultServiceConditionType = "UpgradePaused"
+	// Progressing means the vault deployment is rolling to a new version, or finished rolling to it.
+	// It is "False" if the upgrade failed and was rolled back to the previous version.
+	VaultServiceProgressing VaultServiceConditionType = "Progressing"
+)
+
+// VaultServiceCondition describes the state of a vault service at a certain point.
+type VaultServiceCondition struct {
+	// Type of vault service condition.
+	Type VaultServiceConditionType `json:"type"`
+	// Status of the condition: True, False, or Unknown.
+	Status v1.ConditionStatus `json:"status"`
+	// The last time this condition was updated.
+	LastUpdateTime metav1.Time `json:"lastUpdateTime,omitempty"`
+	// Last time the condition transitioned from one status to another.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// The reason for the condition's last transition.
+	Reason string `json:"reason,omitempty"`
+	// A human readable message indicating details about the transition.
+	Message string `json:"message,omitempty"`
 }
 
 type VaultStatus struct {
@@ -155,3 +367,8 @@ func DefaultVaultClientTLSSecretName(vaultName string) string {
 func DefaultVaultServerTLSSecretName(vaultName string) string {
 	return vaultName + "-default-vault-server-tls"
 }
+
+// DefaultVaultCATLSSecretName returns the name of the secret holding the default vault CA cert and key
+func DefaultVaultCATLSSecretName(vaultName string) string {
+	return vaultName + "-default-vault-ca-tls"
+}
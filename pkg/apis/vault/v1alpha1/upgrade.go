@@ -0,0 +1,119 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// UpgradeStrategy is how vault pods are upgraded to a new version.
+type UpgradeStrategy string
+
+const (
+	// UpgradeStrategyRolling upgrades all vault pods but the active one at once,
+	// and steps the active one down once the others are unsealed standbys.
+	UpgradeStrategyRolling UpgradeStrategy = "Rolling"
+	// UpgradeStrategyCanary brings up a single vault pod of the new version first,
+	// and only upgrades the rest as with the rolling strategy once it passed.
+	UpgradeStrategyCanary UpgradeStrategy = "Canary"
+)
+
+const (
+	defaultSoakTime       = 5 * time.Minute
+	defaultUpgradeTimeout = 10 * time.Minute
+)
+
+// UpgradePolicy defines how the vault operator upgrades vault pods to a new version.
+// With an upgrade policy, an upgrade that fails is rolled back to the previous version.
+type UpgradePolicy struct {
+	// Strategy is "Rolling" or "Canary".
+	// Default: "Rolling".
+	Strategy UpgradeStrategy `json:"strategy,omitempty"`
+
+	// SoakTime is how long the canary must stay a healthy unsealed standby
+	// before the rest of the vault pods are upgraded, e.g. "10m".
+	// Default: "5m".
+	SoakTime string `json:"soakTime,omitempty"`
+
+	// Timeout is how long the canary may take to become an unsealed standby, and the rest of the vault pods
+	// to be upgraded until the active one runs the new version, e.g. "30m".
+	// The upgraded pods have to be unsealed within that time, or the upgrade is rolled back.
+	// Default: "10m" for the canary. The rest of the vault pods are given as long as they need, unless it is set.
+	Timeout string `json:"timeout,omitempty"`
+}
+
+// IsCanary checks if the upgrade policy uses the canary strategy.
+func (p *UpgradePolicy) IsCanary() bool {
+	return p != nil && p.Strategy == UpgradeStrategyCanary
+}
+
+// GetSoakTime returns how long the canary must stay a healthy unsealed standby.
+func (p *UpgradePolicy) GetSoakTime() time.Duration {
+	return parseDurationOr(p.SoakTime, defaultSoakTime)
+}
+
+// GetTimeout returns how long the canary may take to become an unsealed standby, and the rest of the vault pods to be upgraded.
+func (p *UpgradePolicy) GetTimeout() time.Duration {
+	return parseDurationOr(p.Timeout, defaultUpgradeTimeout)
+}
+
+// parseDurationOr returns the duration of s, or the default if s is empty or invalid.
+// The durations of the upgrade policy are validated with the vault service.
+func parseDurationOr(s string, def time.Duration) time.Duration {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return def
+	}
+	return d
+}
+
+// UpgradePhase is the phase of an upgrade of vault.
+type UpgradePhase string
+
+const (
+	// UpgradePhaseCanary means the canary is brought up and soaking.
+	UpgradePhaseCanary UpgradePhase = "Canary"
+	// UpgradePhasePaused means the canary failed, and the rest of the vault pods are not upgraded.
+	UpgradePhasePaused UpgradePhase = "Paused"
+	// UpgradePhaseRolling means the canary passed, and the rest of the vault pods are upgraded.
+	UpgradePhaseRolling UpgradePhase = "Rolling"
+	// UpgradePhaseCompleted means the active vault pod runs the new version.
+	UpgradePhaseCompleted UpgradePhase = "Completed"
+	// UpgradePhaseRolledBack means the upgraded vault pods failed, and the deployment was rolled back to the previous image.
+	UpgradePhaseRolledBack UpgradePhase = "RolledBack"
+)
+
+// UpgradeStatus is the progress of the latest upgrade of vault.
+type UpgradeStatus struct {
+	Phase UpgradePhase `json:"phase"`
+
+	// Image is the vault image upgraded to.
+	Image string `json:"image"`
+
+	// PreviousImage is the vault image before the upgrade, the last known good one,
+	// which the deployment is rolled back to if the upgrade fails.
+	PreviousImage string `json:"previousImage,omitempty"`
+
+	// Canary is the name of the canary pod. It is cleared once the canary pod is deleted after it passed.
+	Canary string `json:"canary,omitempty"`
+
+	// StartTime is when the current phase started, i.e. when the canary was created, or the deployment started rolling.
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CanaryStandbyTime is when the canary became an unsealed standby, which its soak time is counted from.
+	CanaryStandbyTime *metav1.Time `json:"canaryStandbyTime,omitempty"`
+}
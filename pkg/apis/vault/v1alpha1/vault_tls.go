@@ -14,9 +14,24 @@
 
 package v1alpha1
 
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DefaultCertExpiryWindow is the default time before a cert expires to report it as expiring
+	DefaultCertExpiryWindow = 30 * 24 * time.Hour
+)
+
 const (
 	// Name of CA cert file in the client secret
 	CATLSCertName = "vault-client-ca.crt"
+	// Name of client cert and key files in the client secret.
+	// They are only present when client authentication is enabled.
+	ClientTLSCertName = "vault-client.crt"
+	ClientTLSKeyName  = "vault-client.key"
 )
 
 // TLSPolicy defines the TLS policy of the vault nodes
@@ -25,6 +40,17 @@ type TLSPolicy struct {
 	// by putting them into Kubernetes secrets, and specifying them here.
 	// If this is not set, operator will auto-gen TLS assets and secrets.
 	Static *StaticTLS `json:"static,omitempty"`
+
+	// ClientAuth enables mutual TLS on the vault listener. Clients must present
+	// a certificate signed by the CA in the client secret.
+	// If this is not set, clients are not authenticated at the TLS layer.
+	// This field cannot be updated once the CR is created.
+	ClientAuth *ClientAuthPolicy `json:"clientAuth,omitempty"`
+
+	// CertExpiryWindow is how long before any TLS cert used by the vault cluster expires
+	// the operator starts reporting the CertificatesExpiring condition, e.g. "720h".
+	// Default: 720h (30 days).
+	CertExpiryWindow *metav1.Duration `json:"certExpiryWindow,omitempty"`
 }
 
 type StaticTLS struct {
@@ -41,9 +67,22 @@ type StaticTLS struct {
 	// ClientSecret is the secret containing the CA certificate
 	// that will be used to verify the above server certificate
 	// The ca secret should contain one file: vault-client-ca.crt
+	// If client authentication is enabled, the same CA is used to verify client certificates,
+	// and the secret should also contain vault-client.crt and vault-client.key,
+	// which the operator and the vault pod probes present to the vault listener.
 	ClientSecret string `json:"clientSecret,omitempty"`
 }
 
+// ClientAuthPolicy defines the client certificate authentication of the vault listener.
+type ClientAuthPolicy struct {
+	// ClientSecrets are the names of secrets the operator issues client certificates into,
+	// one for each consumer of the vault service.
+	// Each secret contains three files: vault-client.crt, vault-client.key and vault-client-ca.crt
+	// Issuing client certificates is only supported with operator generated TLS assets,
+	// since the operator must own the CA key to sign them.
+	ClientSecrets []string `json:"clientSecrets,omitempty"`
+}
+
 // IsTLSConfigured checks if the vault TLS secrets have been specified by the user
 func IsTLSConfigured(tp *TLSPolicy) bool {
 	if tp == nil || tp.Static == nil {
@@ -51,3 +90,16 @@ func IsTLSConfigured(tp *TLSPolicy) bool {
 	}
 	return len(tp.Static.ServerSecret) != 0 && len(tp.Static.ClientSecret) != 0
 }
+
+// IsClientAuthEnabled checks if the vault listener requires and verifies client certificates
+func IsClientAuthEnabled(tp *TLSPolicy) bool {
+	return tp != nil && tp.ClientAuth != nil
+}
+
+// GetCertExpiryWindow returns the cert expiry window of the TLS policy, or the default one if it is not set
+func (tp *TLSPolicy) GetCertExpiryWindow() time.Duration {
+	if tp == nil || tp.CertExpiryWindow == nil {
+		return DefaultCertExpiryWindow
+	}
+	return tp.CertExpiryWindow.Duration
+}
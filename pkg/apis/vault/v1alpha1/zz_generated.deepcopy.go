@@ -19,6 +19,9 @@
 package v1alpha1
 
 import (
+	core_v1 "k8s.io/api/core/v1"
+	networking_v1 "k8s.io/api/networking/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	conversion "k8s.io/apimachinery/pkg/conversion"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	reflect "reflect"
@@ -29,22 +32,214 @@ import (
 // Deprecated: deepcopy registration will go away when static deepcopy is fully implemented.
 func GetGeneratedDeepCopyFuncs() []conversion.GeneratedDeepCopyFunc {
 	return []conversion.GeneratedDeepCopyFunc{
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*AuditPolicy).DeepCopyInto(out.(*AuditPolicy))
+			return nil
+		}, InType: reflect.TypeOf(&AuditPolicy{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*AuthRole).DeepCopyInto(out.(*AuthRole))
+			return nil
+		}, InType: reflect.TypeOf(&AuthRole{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*BackupPolicy).DeepCopyInto(out.(*BackupPolicy))
+			return nil
+		}, InType: reflect.TypeOf(&BackupPolicy{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*BackupRetention).DeepCopyInto(out.(*BackupRetention))
+			return nil
+		}, InType: reflect.TypeOf(&BackupRetention{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*ClientAuthPolicy).DeepCopyInto(out.(*ClientAuthPolicy))
+			return nil
+		}, InType: reflect.TypeOf(&ClientAuthPolicy{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*DatabaseConfig).DeepCopyInto(out.(*DatabaseConfig))
+			return nil
+		}, InType: reflect.TypeOf(&DatabaseConfig{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*DatabaseConnection).DeepCopyInto(out.(*DatabaseConnection))
+			return nil
+		}, InType: reflect.TypeOf(&DatabaseConnection{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*DatabaseRole).DeepCopyInto(out.(*DatabaseRole))
+			return nil
+		}, InType: reflect.TypeOf(&DatabaseRole{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*FileAuditDevice).DeepCopyInto(out.(*FileAuditDevice))
+			return nil
+		}, InType: reflect.TypeOf(&FileAuditDevice{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*IngressPolicy).DeepCopyInto(out.(*IngressPolicy))
+			return nil
+		}, InType: reflect.TypeOf(&IngressPolicy{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*JWTAuthConfig).DeepCopyInto(out.(*JWTAuthConfig))
+			return nil
+		}, InType: reflect.TypeOf(&JWTAuthConfig{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*KVConfig).DeepCopyInto(out.(*KVConfig))
+			return nil
+		}, InType: reflect.TypeOf(&KVConfig{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*KubernetesAuthConfig).DeepCopyInto(out.(*KubernetesAuthConfig))
+			return nil
+		}, InType: reflect.TypeOf(&KubernetesAuthConfig{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*NetworkPolicy).DeepCopyInto(out.(*NetworkPolicy))
+			return nil
+		}, InType: reflect.TypeOf(&NetworkPolicy{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*OperatorAuthPolicy).DeepCopyInto(out.(*OperatorAuthPolicy))
+			return nil
+		}, InType: reflect.TypeOf(&OperatorAuthPolicy{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*PKIConfig).DeepCopyInto(out.(*PKIConfig))
+			return nil
+		}, InType: reflect.TypeOf(&PKIConfig{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*PKIRole).DeepCopyInto(out.(*PKIRole))
+			return nil
+		}, InType: reflect.TypeOf(&PKIRole{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*PKIRootCA).DeepCopyInto(out.(*PKIRootCA))
+			return nil
+		}, InType: reflect.TypeOf(&PKIRootCA{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*PVCBackupTarget).DeepCopyInto(out.(*PVCBackupTarget))
+			return nil
+		}, InType: reflect.TypeOf(&PVCBackupTarget{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*Plugin).DeepCopyInto(out.(*Plugin))
+			return nil
+		}, InType: reflect.TypeOf(&Plugin{})},
 		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
 			in.(*PodPolicy).DeepCopyInto(out.(*PodPolicy))
 			return nil
 		}, InType: reflect.TypeOf(&PodPolicy{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*RestoreSource).DeepCopyInto(out.(*RestoreSource))
+			return nil
+		}, InType: reflect.TypeOf(&RestoreSource{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*RoutePolicy).DeepCopyInto(out.(*RoutePolicy))
+			return nil
+		}, InType: reflect.TypeOf(&RoutePolicy{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*S3BackupTarget).DeepCopyInto(out.(*S3BackupTarget))
+			return nil
+		}, InType: reflect.TypeOf(&S3BackupTarget{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*ServicePolicy).DeepCopyInto(out.(*ServicePolicy))
+			return nil
+		}, InType: reflect.TypeOf(&ServicePolicy{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*SocketAuditDevice).DeepCopyInto(out.(*SocketAuditDevice))
+			return nil
+		}, InType: reflect.TypeOf(&SocketAuditDevice{})},
 		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
 			in.(*StaticTLS).DeepCopyInto(out.(*StaticTLS))
 			return nil
 		}, InType: reflect.TypeOf(&StaticTLS{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*SyncStatus).DeepCopyInto(out.(*SyncStatus))
+			return nil
+		}, InType: reflect.TypeOf(&SyncStatus{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*SyslogAuditDevice).DeepCopyInto(out.(*SyslogAuditDevice))
+			return nil
+		}, InType: reflect.TypeOf(&SyslogAuditDevice{})},
 		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
 			in.(*TLSPolicy).DeepCopyInto(out.(*TLSPolicy))
 			return nil
 		}, InType: reflect.TypeOf(&TLSPolicy{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*TopologySpreadPolicy).DeepCopyInto(out.(*TopologySpreadPolicy))
+			return nil
+		}, InType: reflect.TypeOf(&TopologySpreadPolicy{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*TransitConfig).DeepCopyInto(out.(*TransitConfig))
+			return nil
+		}, InType: reflect.TypeOf(&TransitConfig{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*TransitKey).DeepCopyInto(out.(*TransitKey))
+			return nil
+		}, InType: reflect.TypeOf(&TransitKey{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*UpgradePolicy).DeepCopyInto(out.(*UpgradePolicy))
+			return nil
+		}, InType: reflect.TypeOf(&UpgradePolicy{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*UpgradeStatus).DeepCopyInto(out.(*UpgradeStatus))
+			return nil
+		}, InType: reflect.TypeOf(&UpgradeStatus{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*VaultAuthMethod).DeepCopyInto(out.(*VaultAuthMethod))
+			return nil
+		}, InType: reflect.TypeOf(&VaultAuthMethod{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*VaultAuthMethodList).DeepCopyInto(out.(*VaultAuthMethodList))
+			return nil
+		}, InType: reflect.TypeOf(&VaultAuthMethodList{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*VaultAuthMethodSpec).DeepCopyInto(out.(*VaultAuthMethodSpec))
+			return nil
+		}, InType: reflect.TypeOf(&VaultAuthMethodSpec{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*VaultAuthMethodStatus).DeepCopyInto(out.(*VaultAuthMethodStatus))
+			return nil
+		}, InType: reflect.TypeOf(&VaultAuthMethodStatus{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*VaultBackup).DeepCopyInto(out.(*VaultBackup))
+			return nil
+		}, InType: reflect.TypeOf(&VaultBackup{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*VaultBackupList).DeepCopyInto(out.(*VaultBackupList))
+			return nil
+		}, InType: reflect.TypeOf(&VaultBackupList{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*VaultBackupSpec).DeepCopyInto(out.(*VaultBackupSpec))
+			return nil
+		}, InType: reflect.TypeOf(&VaultBackupSpec{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*VaultBackupStatus).DeepCopyInto(out.(*VaultBackupStatus))
+			return nil
+		}, InType: reflect.TypeOf(&VaultBackupStatus{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*VaultPolicy).DeepCopyInto(out.(*VaultPolicy))
+			return nil
+		}, InType: reflect.TypeOf(&VaultPolicy{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*VaultPolicyList).DeepCopyInto(out.(*VaultPolicyList))
+			return nil
+		}, InType: reflect.TypeOf(&VaultPolicyList{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*VaultPolicySpec).DeepCopyInto(out.(*VaultPolicySpec))
+			return nil
+		}, InType: reflect.TypeOf(&VaultPolicySpec{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*VaultSecretEngine).DeepCopyInto(out.(*VaultSecretEngine))
+			return nil
+		}, InType: reflect.TypeOf(&VaultSecretEngine{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*VaultSecretEngineList).DeepCopyInto(out.(*VaultSecretEngineList))
+			return nil
+		}, InType: reflect.TypeOf(&VaultSecretEngineList{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*VaultSecretEngineSpec).DeepCopyInto(out.(*VaultSecretEngineSpec))
+			return nil
+		}, InType: reflect.TypeOf(&VaultSecretEngineSpec{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*VaultSecretEngineStatus).DeepCopyInto(out.(*VaultSecretEngineStatus))
+			return nil
+		}, InType: reflect.TypeOf(&VaultSecretEngineStatus{})},
 		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
 			in.(*VaultService).DeepCopyInto(out.(*VaultService))
 			return nil
 		}, InType: reflect.TypeOf(&VaultService{})},
+		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
+			in.(*VaultServiceCondition).DeepCopyInto(out.(*VaultServiceCondition))
+			return nil
+		}, InType: reflect.TypeOf(&VaultServiceCondition{})},
 		{Fn: func(in interface{}, out interface{}, c *conversion.Cloner) error {
 			in.(*VaultServiceList).DeepCopyInto(out.(*VaultServiceList))
 			return nil
@@ -65,59 +260,1539 @@ func GetGeneratedDeepCopyFuncs() []conversion.GeneratedDeepCopyFunc {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *PodPolicy) DeepCopyInto(out *PodPolicy) {
+func (in *AuditPolicy) DeepCopyInto(out *AuditPolicy) {
+	*out = *in
+	if in.File != nil {
+		in, out := &in.File, &out.File
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(FileAuditDevice)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Socket != nil {
+		in, out := &in.Socket, &out.Socket
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(SocketAuditDevice)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Syslog != nil {
+		in, out := &in.Syslog, &out.Syslog
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(SyslogAuditDevice)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuditPolicy.
+func (in *AuditPolicy) DeepCopy() *AuditPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(AuditPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AuthRole) DeepCopyInto(out *AuthRole) {
+	*out = *in
+	if in.Policies != nil {
+		in, out := &in.Policies, &out.Policies
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.BoundServiceAccountNames != nil {
+		in, out := &in.BoundServiceAccountNames, &out.BoundServiceAccountNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.BoundServiceAccountNamespaces != nil {
+		in, out := &in.BoundServiceAccountNamespaces, &out.BoundServiceAccountNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.BoundAudiences != nil {
+		in, out := &in.BoundAudiences, &out.BoundAudiences
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PasswordSecret != nil {
+		in, out := &in.PasswordSecret, &out.PasswordSecret
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(core_v1.SecretKeySelector)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Options != nil {
+		in, out := &in.Options, &out.Options
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AuthRole.
+func (in *AuthRole) DeepCopy() *AuthRole {
+	if in == nil {
+		return nil
+	}
+	out := new(AuthRole)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupPolicy) DeepCopyInto(out *BackupPolicy) {
+	*out = *in
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(S3BackupTarget)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.PVC != nil {
+		in, out := &in.PVC, &out.PVC
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(PVCBackupTarget)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(BackupRetention)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupPolicy.
+func (in *BackupPolicy) DeepCopy() *BackupPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupRetention) DeepCopyInto(out *BackupRetention) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupRetention.
+func (in *BackupRetention) DeepCopy() *BackupRetention {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupRetention)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClientAuthPolicy) DeepCopyInto(out *ClientAuthPolicy) {
+	*out = *in
+	if in.ClientSecrets != nil {
+		in, out := &in.ClientSecrets, &out.ClientSecrets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClientAuthPolicy.
+func (in *ClientAuthPolicy) DeepCopy() *ClientAuthPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ClientAuthPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseConfig) DeepCopyInto(out *DatabaseConfig) {
+	*out = *in
+	if in.Connections != nil {
+		in, out := &in.Connections, &out.Connections
+		*out = make([]DatabaseConnection, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]DatabaseRole, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseConfig.
+func (in *DatabaseConfig) DeepCopy() *DatabaseConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseConnection) DeepCopyInto(out *DatabaseConnection) {
+	*out = *in
+	if in.AllowedRoles != nil {
+		in, out := &in.AllowedRoles, &out.AllowedRoles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.UsernameSecret != nil {
+		in, out := &in.UsernameSecret, &out.UsernameSecret
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(core_v1.SecretKeySelector)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.PasswordSecret != nil {
+		in, out := &in.PasswordSecret, &out.PasswordSecret
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(core_v1.SecretKeySelector)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Options != nil {
+		in, out := &in.Options, &out.Options
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseConnection.
+func (in *DatabaseConnection) DeepCopy() *DatabaseConnection {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseConnection)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DatabaseRole) DeepCopyInto(out *DatabaseRole) {
+	*out = *in
+	if in.CreationStatements != nil {
+		in, out := &in.CreationStatements, &out.CreationStatements
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.RevocationStatements != nil {
+		in, out := &in.RevocationStatements, &out.RevocationStatements
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DatabaseRole.
+func (in *DatabaseRole) DeepCopy() *DatabaseRole {
+	if in == nil {
+		return nil
+	}
+	out := new(DatabaseRole)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *FileAuditDevice) DeepCopyInto(out *FileAuditDevice) {
+	*out = *in
+	if in.Sidecar != nil {
+		in, out := &in.Sidecar, &out.Sidecar
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(core_v1.Container)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Options != nil {
+		in, out := &in.Options, &out.Options
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new FileAuditDevice.
+func (in *FileAuditDevice) DeepCopy() *FileAuditDevice {
+	if in == nil {
+		return nil
+	}
+	out := new(FileAuditDevice)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IngressPolicy) DeepCopyInto(out *IngressPolicy) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IngressPolicy.
+func (in *IngressPolicy) DeepCopy() *IngressPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(IngressPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JWTAuthConfig) DeepCopyInto(out *JWTAuthConfig) {
+	*out = *in
+	if in.JWTValidationPubKeys != nil {
+		in, out := &in.JWTValidationPubKeys, &out.JWTValidationPubKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JWTAuthConfig.
+func (in *JWTAuthConfig) DeepCopy() *JWTAuthConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(JWTAuthConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KVConfig) DeepCopyInto(out *KVConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KVConfig.
+func (in *KVConfig) DeepCopy() *KVConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(KVConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KubernetesAuthConfig) DeepCopyInto(out *KubernetesAuthConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KubernetesAuthConfig.
+func (in *KubernetesAuthConfig) DeepCopy() *KubernetesAuthConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(KubernetesAuthConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NetworkPolicy) DeepCopyInto(out *NetworkPolicy) {
+	*out = *in
+	if in.Clients != nil {
+		in, out := &in.Clients, &out.Clients
+		*out = make([]networking_v1.NetworkPolicyPeer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Scrapers != nil {
+		in, out := &in.Scrapers, &out.Scrapers
+		*out = make([]networking_v1.NetworkPolicyPeer, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.OperatorSelector != nil {
+		in, out := &in.OperatorSelector, &out.OperatorSelector
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(meta_v1.LabelSelector)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.EtcdOperatorSelector != nil {
+		in, out := &in.EtcdOperatorSelector, &out.EtcdOperatorSelector
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(meta_v1.LabelSelector)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.EtcdBackupOperatorSelector != nil {
+		in, out := &in.EtcdBackupOperatorSelector, &out.EtcdBackupOperatorSelector
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(meta_v1.LabelSelector)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NetworkPolicy.
+func (in *NetworkPolicy) DeepCopy() *NetworkPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperatorAuthPolicy) DeepCopyInto(out *OperatorAuthPolicy) {
+	*out = *in
+	if in.Policies != nil {
+		in, out := &in.Policies, &out.Policies
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorAuthPolicy.
+func (in *OperatorAuthPolicy) DeepCopy() *OperatorAuthPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(OperatorAuthPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PKIConfig) DeepCopyInto(out *PKIConfig) {
+	*out = *in
+	if in.RootCA != nil {
+		in, out := &in.RootCA, &out.RootCA
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(PKIRootCA)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.IssuingCertificates != nil {
+		in, out := &in.IssuingCertificates, &out.IssuingCertificates
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CRLDistributionPoints != nil {
+		in, out := &in.CRLDistributionPoints, &out.CRLDistributionPoints
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]PKIRole, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PKIConfig.
+func (in *PKIConfig) DeepCopy() *PKIConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(PKIConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PKIRole) DeepCopyInto(out *PKIRole) {
+	*out = *in
+	if in.AllowedDomains != nil {
+		in, out := &in.AllowedDomains, &out.AllowedDomains
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Options != nil {
+		in, out := &in.Options, &out.Options
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PKIRole.
+func (in *PKIRole) DeepCopy() *PKIRole {
+	if in == nil {
+		return nil
+	}
+	out := new(PKIRole)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PKIRootCA) DeepCopyInto(out *PKIRootCA) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PKIRootCA.
+func (in *PKIRootCA) DeepCopy() *PKIRootCA {
+	if in == nil {
+		return nil
+	}
+	out := new(PKIRootCA)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PVCBackupTarget) DeepCopyInto(out *PVCBackupTarget) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PVCBackupTarget.
+func (in *PVCBackupTarget) DeepCopy() *PVCBackupTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(PVCBackupTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Plugin) DeepCopyInto(out *Plugin) {
+	*out = *in
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Plugin.
+func (in *Plugin) DeepCopy() *Plugin {
+	if in == nil {
+		return nil
+	}
+	out := new(Plugin)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodPolicy) DeepCopyInto(out *PodPolicy) {
+	*out = *in
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.VaultResources != nil {
+		in, out := &in.VaultResources, &out.VaultResources
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(core_v1.ResourceRequirements)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.ExporterResources != nil {
+		in, out := &in.ExporterResources, &out.ExporterResources
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(core_v1.ResourceRequirements)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Affinity != nil {
+		in, out := &in.Affinity, &out.Affinity
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(core_v1.Affinity)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.TopologySpread != nil {
+		in, out := &in.TopologySpread, &out.TopologySpread
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(TopologySpreadPolicy)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]core_v1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ImagePullSecrets != nil {
+		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
+		*out = make([]core_v1.LocalObjectReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecurityContext != nil {
+		in, out := &in.SecurityContext, &out.SecurityContext
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(core_v1.PodSecurityContext)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.ContainerSecurityContext != nil {
+		in, out := &in.ContainerSecurityContext, &out.ContainerSecurityContext
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(core_v1.SecurityContext)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]core_v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.VolumeMounts != nil {
+		in, out := &in.VolumeMounts, &out.VolumeMounts
+		*out = make([]core_v1.VolumeMount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExtraVolumes != nil {
+		in, out := &in.ExtraVolumes, &out.ExtraVolumes
+		*out = make([]core_v1.Volume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ExtraContainers != nil {
+		in, out := &in.ExtraContainers, &out.ExtraContainers
+		*out = make([]core_v1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.InitContainers != nil {
+		in, out := &in.InitContainers, &out.InitContainers
+		*out = make([]core_v1.Container, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodPolicy.
+func (in *PodPolicy) DeepCopy() *PodPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(PodPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RestoreSource) DeepCopyInto(out *RestoreSource) {
+	*out = *in
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(S3BackupTarget)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreSource.
+func (in *RestoreSource) DeepCopy() *RestoreSource {
+	if in == nil {
+		return nil
+	}
+	out := new(RestoreSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RoutePolicy) DeepCopyInto(out *RoutePolicy) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RoutePolicy.
+func (in *RoutePolicy) DeepCopy() *RoutePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(RoutePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *S3BackupTarget) DeepCopyInto(out *S3BackupTarget) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new S3BackupTarget.
+func (in *S3BackupTarget) DeepCopy() *S3BackupTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(S3BackupTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServicePolicy) DeepCopyInto(out *ServicePolicy) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.LoadBalancerSourceRanges != nil {
+		in, out := &in.LoadBalancerSourceRanges, &out.LoadBalancerSourceRanges
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Ingress != nil {
+		in, out := &in.Ingress, &out.Ingress
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(IngressPolicy)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Route != nil {
+		in, out := &in.Route, &out.Route
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(RoutePolicy)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServicePolicy.
+func (in *ServicePolicy) DeepCopy() *ServicePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ServicePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SocketAuditDevice) DeepCopyInto(out *SocketAuditDevice) {
+	*out = *in
+	if in.Options != nil {
+		in, out := &in.Options, &out.Options
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SocketAuditDevice.
+func (in *SocketAuditDevice) DeepCopy() *SocketAuditDevice {
+	if in == nil {
+		return nil
+	}
+	out := new(SocketAuditDevice)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StaticTLS) DeepCopyInto(out *StaticTLS) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StaticTLS.
+func (in *StaticTLS) DeepCopy() *StaticTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(StaticTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyncStatus) DeepCopyInto(out *SyncStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyncStatus.
+func (in *SyncStatus) DeepCopy() *SyncStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SyslogAuditDevice) DeepCopyInto(out *SyslogAuditDevice) {
+	*out = *in
+	if in.Options != nil {
+		in, out := &in.Options, &out.Options
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SyslogAuditDevice.
+func (in *SyslogAuditDevice) DeepCopy() *SyslogAuditDevice {
+	if in == nil {
+		return nil
+	}
+	out := new(SyslogAuditDevice)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TLSPolicy) DeepCopyInto(out *TLSPolicy) {
+	*out = *in
+	if in.Static != nil {
+		in, out := &in.Static, &out.Static
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(StaticTLS)
+			**out = **in
+		}
+	}
+	if in.ClientAuth != nil {
+		in, out := &in.ClientAuth, &out.ClientAuth
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ClientAuthPolicy)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.CertExpiryWindow != nil {
+		in, out := &in.CertExpiryWindow, &out.CertExpiryWindow
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(meta_v1.Duration)
+			**out = **in
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSPolicy.
+func (in *TLSPolicy) DeepCopy() *TLSPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(TLSPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TopologySpreadPolicy) DeepCopyInto(out *TopologySpreadPolicy) {
+	*out = *in
+	if in.TopologyKeys != nil {
+		in, out := &in.TopologyKeys, &out.TopologyKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TopologySpreadPolicy.
+func (in *TopologySpreadPolicy) DeepCopy() *TopologySpreadPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(TopologySpreadPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransitConfig) DeepCopyInto(out *TransitConfig) {
+	*out = *in
+	if in.Keys != nil {
+		in, out := &in.Keys, &out.Keys
+		*out = make([]TransitKey, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransitConfig.
+func (in *TransitConfig) DeepCopy() *TransitConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(TransitConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransitKey) DeepCopyInto(out *TransitKey) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransitKey.
+func (in *TransitKey) DeepCopy() *TransitKey {
+	if in == nil {
+		return nil
+	}
+	out := new(TransitKey)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradePolicy) DeepCopyInto(out *UpgradePolicy) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradePolicy.
+func (in *UpgradePolicy) DeepCopy() *UpgradePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradeStatus) DeepCopyInto(out *UpgradeStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(meta_v1.Time)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.CanaryStandbyTime != nil {
+		in, out := &in.CanaryStandbyTime, &out.CanaryStandbyTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(meta_v1.Time)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradeStatus.
+func (in *UpgradeStatus) DeepCopy() *UpgradeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultAuthMethod) DeepCopyInto(out *VaultAuthMethod) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthMethod.
+func (in *VaultAuthMethod) DeepCopy() *VaultAuthMethod {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultAuthMethod)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VaultAuthMethod) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	} else {
+		return nil
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultAuthMethodList) DeepCopyInto(out *VaultAuthMethodList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VaultAuthMethod, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthMethodList.
+func (in *VaultAuthMethodList) DeepCopy() *VaultAuthMethodList {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultAuthMethodList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VaultAuthMethodList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	} else {
+		return nil
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultAuthMethodSpec) DeepCopyInto(out *VaultAuthMethodSpec) {
+	*out = *in
+	if in.Kubernetes != nil {
+		in, out := &in.Kubernetes, &out.Kubernetes
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(KubernetesAuthConfig)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.JWT != nil {
+		in, out := &in.JWT, &out.JWT
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(JWTAuthConfig)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]AuthRole, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthMethodSpec.
+func (in *VaultAuthMethodSpec) DeepCopy() *VaultAuthMethodSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultAuthMethodSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultAuthMethodStatus) DeepCopyInto(out *VaultAuthMethodStatus) {
+	*out = *in
+	out.SyncStatus = in.SyncStatus
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultAuthMethodStatus.
+func (in *VaultAuthMethodStatus) DeepCopy() *VaultAuthMethodStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultAuthMethodStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultBackup) DeepCopyInto(out *VaultBackup) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultBackup.
+func (in *VaultBackup) DeepCopy() *VaultBackup {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultBackup)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VaultBackup) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	} else {
+		return nil
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultBackupList) DeepCopyInto(out *VaultBackupList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VaultBackup, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultBackupList.
+func (in *VaultBackupList) DeepCopy() *VaultBackupList {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultBackupList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VaultBackupList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	} else {
+		return nil
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultBackupSpec) DeepCopyInto(out *VaultBackupSpec) {
+	*out = *in
+	if in.S3 != nil {
+		in, out := &in.S3, &out.S3
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(S3BackupTarget)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.PVC != nil {
+		in, out := &in.PVC, &out.PVC
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(PVCBackupTarget)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultBackupSpec.
+func (in *VaultBackupSpec) DeepCopy() *VaultBackupSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultBackupSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultBackupStatus) DeepCopyInto(out *VaultBackupStatus) {
 	*out = *in
-	in.Resources.DeepCopyInto(&out.Resources)
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(meta_v1.Time)
+			(*in).DeepCopyInto(*out)
+		}
+	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodPolicy.
-func (in *PodPolicy) DeepCopy() *PodPolicy {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultBackupStatus.
+func (in *VaultBackupStatus) DeepCopy() *VaultBackupStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(PodPolicy)
+	out := new(VaultBackupStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *StaticTLS) DeepCopyInto(out *StaticTLS) {
+func (in *VaultPolicy) DeepCopyInto(out *VaultPolicy) {
 	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StaticTLS.
-func (in *StaticTLS) DeepCopy() *StaticTLS {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultPolicy.
+func (in *VaultPolicy) DeepCopy() *VaultPolicy {
 	if in == nil {
 		return nil
 	}
-	out := new(StaticTLS)
+	out := new(VaultPolicy)
 	in.DeepCopyInto(out)
 	return out
 }
 
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VaultPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	} else {
+		return nil
+	}
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *TLSPolicy) DeepCopyInto(out *TLSPolicy) {
+func (in *VaultPolicyList) DeepCopyInto(out *VaultPolicyList) {
 	*out = *in
-	if in.Static != nil {
-		in, out := &in.Static, &out.Static
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VaultPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultPolicyList.
+func (in *VaultPolicyList) DeepCopy() *VaultPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VaultPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	} else {
+		return nil
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultPolicySpec) DeepCopyInto(out *VaultPolicySpec) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultPolicySpec.
+func (in *VaultPolicySpec) DeepCopy() *VaultPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultSecretEngine) DeepCopyInto(out *VaultSecretEngine) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultSecretEngine.
+func (in *VaultSecretEngine) DeepCopy() *VaultSecretEngine {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultSecretEngine)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VaultSecretEngine) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	} else {
+		return nil
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultSecretEngineList) DeepCopyInto(out *VaultSecretEngineList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	out.ListMeta = in.ListMeta
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]VaultSecretEngine, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultSecretEngineList.
+func (in *VaultSecretEngineList) DeepCopy() *VaultSecretEngineList {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultSecretEngineList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *VaultSecretEngineList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	} else {
+		return nil
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultSecretEngineSpec) DeepCopyInto(out *VaultSecretEngineSpec) {
+	*out = *in
+	if in.KV != nil {
+		in, out := &in.KV, &out.KV
 		if *in == nil {
 			*out = nil
 		} else {
-			*out = new(StaticTLS)
-			**out = **in
+			*out = new(KVConfig)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.PKI != nil {
+		in, out := &in.PKI, &out.PKI
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(PKIConfig)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Transit != nil {
+		in, out := &in.Transit, &out.Transit
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(TransitConfig)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Database != nil {
+		in, out := &in.Database, &out.Database
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(DatabaseConfig)
+			(*in).DeepCopyInto(*out)
 		}
 	}
 	return
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TLSPolicy.
-func (in *TLSPolicy) DeepCopy() *TLSPolicy {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultSecretEngineSpec.
+func (in *VaultSecretEngineSpec) DeepCopy() *VaultSecretEngineSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(TLSPolicy)
+	out := new(VaultSecretEngineSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultSecretEngineStatus) DeepCopyInto(out *VaultSecretEngineStatus) {
+	*out = *in
+	out.SyncStatus = in.SyncStatus
+	if in.Roles != nil {
+		in, out := &in.Roles, &out.Roles
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Connections != nil {
+		in, out := &in.Connections, &out.Connections
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Drift != nil {
+		in, out := &in.Drift, &out.Drift
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultSecretEngineStatus.
+func (in *VaultSecretEngineStatus) DeepCopy() *VaultSecretEngineStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultSecretEngineStatus)
 	in.DeepCopyInto(out)
 	return out
 }
@@ -151,6 +1826,24 @@ func (in *VaultService) DeepCopyObject() runtime.Object {
 	}
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VaultServiceCondition) DeepCopyInto(out *VaultServiceCondition) {
+	*out = *in
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VaultServiceCondition.
+func (in *VaultServiceCondition) DeepCopy() *VaultServiceCondition {
+	if in == nil {
+		return nil
+	}
+	out := new(VaultServiceCondition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VaultServiceList) DeepCopyInto(out *VaultServiceList) {
 	*out = *in
@@ -206,6 +1899,76 @@ func (in *VaultServiceSpec) DeepCopyInto(out *VaultServiceSpec) {
 			(*in).DeepCopyInto(*out)
 		}
 	}
+	if in.Service != nil {
+		in, out := &in.Service, &out.Service
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(ServicePolicy)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.NetworkPolicy != nil {
+		in, out := &in.NetworkPolicy, &out.NetworkPolicy
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(NetworkPolicy)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Plugins != nil {
+		in, out := &in.Plugins, &out.Plugins
+		*out = make([]Plugin, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Audit != nil {
+		in, out := &in.Audit, &out.Audit
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(AuditPolicy)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.OperatorAuth != nil {
+		in, out := &in.OperatorAuth, &out.OperatorAuth
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(OperatorAuthPolicy)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Backup != nil {
+		in, out := &in.Backup, &out.Backup
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(BackupPolicy)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.RestoreFrom != nil {
+		in, out := &in.RestoreFrom, &out.RestoreFrom
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(RestoreSource)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Upgrade != nil {
+		in, out := &in.Upgrade, &out.Upgrade
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(UpgradePolicy)
+			**out = **in
+		}
+	}
 	return
 }
 
@@ -228,6 +1991,22 @@ func (in *VaultServiceStatus) DeepCopyInto(out *VaultServiceStatus) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.Upgrade != nil {
+		in, out := &in.Upgrade, &out.Upgrade
+		if *in == nil {
+			*out = nil
+		} else {
+			*out = new(UpgradeStatus)
+			(*in).DeepCopyInto(*out)
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]VaultServiceCondition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
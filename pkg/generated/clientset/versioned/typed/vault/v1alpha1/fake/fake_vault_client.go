@@ -28,6 +28,22 @@ func (c *FakeVaultV1alpha1) VaultServices(namespace string) v1alpha1.VaultServic
 	return &FakeVaultServices{c, namespace}
 }
 
+func (c *FakeVaultV1alpha1) VaultBackups(namespace string) v1alpha1.VaultBackupInterface {
+	return &FakeVaultBackups{c, namespace}
+}
+
+func (c *FakeVaultV1alpha1) VaultSecretEngines(namespace string) v1alpha1.VaultSecretEngineInterface {
+	return &FakeVaultSecretEngines{c, namespace}
+}
+
+func (c *FakeVaultV1alpha1) VaultAuthMethods(namespace string) v1alpha1.VaultAuthMethodInterface {
+	return &FakeVaultAuthMethods{c, namespace}
+}
+
+func (c *FakeVaultV1alpha1) VaultPolicies(namespace string) v1alpha1.VaultPolicyInterface {
+	return &FakeVaultPolicies{c, namespace}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeVaultV1alpha1) RESTClient() rest.Interface {
@@ -0,0 +1,136 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fake
+
+import (
+	v1alpha1 "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeVaultAuthMethods implements VaultAuthMethodInterface
+type FakeVaultAuthMethods struct {
+	Fake *FakeVaultV1alpha1
+	ns   string
+}
+
+var vaultauthmethodsResource = schema.GroupVersionResource{Group: "vault.security.coreos.com", Version: "v1alpha1", Resource: "vaultauthmethods"}
+
+var vaultauthmethodsKind = schema.GroupVersionKind{Group: "vault.security.coreos.com", Version: "v1alpha1", Kind: "VaultAuthMethod"}
+
+// Get takes name of the vaultAuthMethod, and returns the corresponding vaultAuthMethod object, and an error if there is any.
+func (c *FakeVaultAuthMethods) Get(name string, options v1.GetOptions) (result *v1alpha1.VaultAuthMethod, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(vaultauthmethodsResource, c.ns, name), &v1alpha1.VaultAuthMethod{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VaultAuthMethod), err
+}
+
+// List takes label and field selectors, and returns the list of VaultAuthMethods that match those selectors.
+func (c *FakeVaultAuthMethods) List(opts v1.ListOptions) (result *v1alpha1.VaultAuthMethodList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(vaultauthmethodsResource, vaultauthmethodsKind, c.ns, opts), &v1alpha1.VaultAuthMethodList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.VaultAuthMethodList{}
+	for _, item := range obj.(*v1alpha1.VaultAuthMethodList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested vaultAuthMethods.
+func (c *FakeVaultAuthMethods) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(vaultauthmethodsResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a vaultAuthMethod and creates it.  Returns the server's representation of the vaultAuthMethod, and an error, if there is any.
+func (c *FakeVaultAuthMethods) Create(vaultAuthMethod *v1alpha1.VaultAuthMethod) (result *v1alpha1.VaultAuthMethod, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(vaultauthmethodsResource, c.ns, vaultAuthMethod), &v1alpha1.VaultAuthMethod{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VaultAuthMethod), err
+}
+
+// Update takes the representation of a vaultAuthMethod and updates it. Returns the server's representation of the vaultAuthMethod, and an error, if there is any.
+func (c *FakeVaultAuthMethods) Update(vaultAuthMethod *v1alpha1.VaultAuthMethod) (result *v1alpha1.VaultAuthMethod, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(vaultauthmethodsResource, c.ns, vaultAuthMethod), &v1alpha1.VaultAuthMethod{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VaultAuthMethod), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeVaultAuthMethods) UpdateStatus(vaultAuthMethod *v1alpha1.VaultAuthMethod) (*v1alpha1.VaultAuthMethod, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(vaultauthmethodsResource, "status", c.ns, vaultAuthMethod), &v1alpha1.VaultAuthMethod{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VaultAuthMethod), err
+}
+
+// Delete takes name of the vaultAuthMethod and deletes it. Returns an error if one occurs.
+func (c *FakeVaultAuthMethods) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(vaultauthmethodsResource, c.ns, name), &v1alpha1.VaultAuthMethod{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeVaultAuthMethods) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(vaultauthmethodsResource, c.ns, listOptions)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.VaultAuthMethodList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched vaultAuthMethod.
+func (c *FakeVaultAuthMethods) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.VaultAuthMethod, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(vaultauthmethodsResource, c.ns, name, data, subresources...), &v1alpha1.VaultAuthMethod{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VaultAuthMethod), err
+}
@@ -0,0 +1,136 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fake
+
+import (
+	v1alpha1 "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeVaultBackups implements VaultBackupInterface
+type FakeVaultBackups struct {
+	Fake *FakeVaultV1alpha1
+	ns   string
+}
+
+var vaultbackupsResource = schema.GroupVersionResource{Group: "vault.security.coreos.com", Version: "v1alpha1", Resource: "vaultbackups"}
+
+var vaultbackupsKind = schema.GroupVersionKind{Group: "vault.security.coreos.com", Version: "v1alpha1", Kind: "VaultBackup"}
+
+// Get takes name of the vaultBackup, and returns the corresponding vaultBackup object, and an error if there is any.
+func (c *FakeVaultBackups) Get(name string, options v1.GetOptions) (result *v1alpha1.VaultBackup, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(vaultbackupsResource, c.ns, name), &v1alpha1.VaultBackup{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VaultBackup), err
+}
+
+// List takes label and field selectors, and returns the list of VaultBackups that match those selectors.
+func (c *FakeVaultBackups) List(opts v1.ListOptions) (result *v1alpha1.VaultBackupList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(vaultbackupsResource, vaultbackupsKind, c.ns, opts), &v1alpha1.VaultBackupList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.VaultBackupList{}
+	for _, item := range obj.(*v1alpha1.VaultBackupList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested vaultBackups.
+func (c *FakeVaultBackups) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(vaultbackupsResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a vaultBackup and creates it.  Returns the server's representation of the vaultBackup, and an error, if there is any.
+func (c *FakeVaultBackups) Create(vaultBackup *v1alpha1.VaultBackup) (result *v1alpha1.VaultBackup, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(vaultbackupsResource, c.ns, vaultBackup), &v1alpha1.VaultBackup{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VaultBackup), err
+}
+
+// Update takes the representation of a vaultBackup and updates it. Returns the server's representation of the vaultBackup, and an error, if there is any.
+func (c *FakeVaultBackups) Update(vaultBackup *v1alpha1.VaultBackup) (result *v1alpha1.VaultBackup, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(vaultbackupsResource, c.ns, vaultBackup), &v1alpha1.VaultBackup{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VaultBackup), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeVaultBackups) UpdateStatus(vaultBackup *v1alpha1.VaultBackup) (*v1alpha1.VaultBackup, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(vaultbackupsResource, "status", c.ns, vaultBackup), &v1alpha1.VaultBackup{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VaultBackup), err
+}
+
+// Delete takes name of the vaultBackup and deletes it. Returns an error if one occurs.
+func (c *FakeVaultBackups) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(vaultbackupsResource, c.ns, name), &v1alpha1.VaultBackup{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeVaultBackups) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(vaultbackupsResource, c.ns, listOptions)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.VaultBackupList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched vaultBackup.
+func (c *FakeVaultBackups) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.VaultBackup, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(vaultbackupsResource, c.ns, name, data, subresources...), &v1alpha1.VaultBackup{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VaultBackup), err
+}
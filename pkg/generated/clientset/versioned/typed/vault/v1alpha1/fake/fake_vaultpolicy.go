@@ -0,0 +1,136 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fake
+
+import (
+	v1alpha1 "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeVaultPolicies implements VaultPolicyInterface
+type FakeVaultPolicies struct {
+	Fake *FakeVaultV1alpha1
+	ns   string
+}
+
+var vaultpoliciesResource = schema.GroupVersionResource{Group: "vault.security.coreos.com", Version: "v1alpha1", Resource: "vaultpolicies"}
+
+var vaultpoliciesKind = schema.GroupVersionKind{Group: "vault.security.coreos.com", Version: "v1alpha1", Kind: "VaultPolicy"}
+
+// Get takes name of the vaultPolicy, and returns the corresponding vaultPolicy object, and an error if there is any.
+func (c *FakeVaultPolicies) Get(name string, options v1.GetOptions) (result *v1alpha1.VaultPolicy, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(vaultpoliciesResource, c.ns, name), &v1alpha1.VaultPolicy{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VaultPolicy), err
+}
+
+// List takes label and field selectors, and returns the list of VaultPolicies that match those selectors.
+func (c *FakeVaultPolicies) List(opts v1.ListOptions) (result *v1alpha1.VaultPolicyList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(vaultpoliciesResource, vaultpoliciesKind, c.ns, opts), &v1alpha1.VaultPolicyList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.VaultPolicyList{}
+	for _, item := range obj.(*v1alpha1.VaultPolicyList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested vaultPolicies.
+func (c *FakeVaultPolicies) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(vaultpoliciesResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a vaultPolicy and creates it.  Returns the server's representation of the vaultPolicy, and an error, if there is any.
+func (c *FakeVaultPolicies) Create(vaultPolicy *v1alpha1.VaultPolicy) (result *v1alpha1.VaultPolicy, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(vaultpoliciesResource, c.ns, vaultPolicy), &v1alpha1.VaultPolicy{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VaultPolicy), err
+}
+
+// Update takes the representation of a vaultPolicy and updates it. Returns the server's representation of the vaultPolicy, and an error, if there is any.
+func (c *FakeVaultPolicies) Update(vaultPolicy *v1alpha1.VaultPolicy) (result *v1alpha1.VaultPolicy, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(vaultpoliciesResource, c.ns, vaultPolicy), &v1alpha1.VaultPolicy{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VaultPolicy), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeVaultPolicies) UpdateStatus(vaultPolicy *v1alpha1.VaultPolicy) (*v1alpha1.VaultPolicy, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(vaultpoliciesResource, "status", c.ns, vaultPolicy), &v1alpha1.VaultPolicy{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VaultPolicy), err
+}
+
+// Delete takes name of the vaultPolicy and deletes it. Returns an error if one occurs.
+func (c *FakeVaultPolicies) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(vaultpoliciesResource, c.ns, name), &v1alpha1.VaultPolicy{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeVaultPolicies) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(vaultpoliciesResource, c.ns, listOptions)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.VaultPolicyList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched vaultPolicy.
+func (c *FakeVaultPolicies) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.VaultPolicy, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(vaultpoliciesResource, c.ns, name, data, subresources...), &v1alpha1.VaultPolicy{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VaultPolicy), err
+}
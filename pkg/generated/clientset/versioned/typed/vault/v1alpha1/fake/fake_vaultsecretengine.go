@@ -0,0 +1,136 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fake
+
+import (
+	v1alpha1 "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	labels "k8s.io/apimachinery/pkg/labels"
+	schema "k8s.io/apimachinery/pkg/runtime/schema"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	testing "k8s.io/client-go/testing"
+)
+
+// FakeVaultSecretEngines implements VaultSecretEngineInterface
+type FakeVaultSecretEngines struct {
+	Fake *FakeVaultV1alpha1
+	ns   string
+}
+
+var vaultsecretenginesResource = schema.GroupVersionResource{Group: "vault.security.coreos.com", Version: "v1alpha1", Resource: "vaultsecretengines"}
+
+var vaultsecretenginesKind = schema.GroupVersionKind{Group: "vault.security.coreos.com", Version: "v1alpha1", Kind: "VaultSecretEngine"}
+
+// Get takes name of the vaultSecretEngine, and returns the corresponding vaultSecretEngine object, and an error if there is any.
+func (c *FakeVaultSecretEngines) Get(name string, options v1.GetOptions) (result *v1alpha1.VaultSecretEngine, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewGetAction(vaultsecretenginesResource, c.ns, name), &v1alpha1.VaultSecretEngine{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VaultSecretEngine), err
+}
+
+// List takes label and field selectors, and returns the list of VaultSecretEngines that match those selectors.
+func (c *FakeVaultSecretEngines) List(opts v1.ListOptions) (result *v1alpha1.VaultSecretEngineList, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewListAction(vaultsecretenginesResource, vaultsecretenginesKind, c.ns, opts), &v1alpha1.VaultSecretEngineList{})
+
+	if obj == nil {
+		return nil, err
+	}
+
+	label, _, _ := testing.ExtractFromListOptions(opts)
+	if label == nil {
+		label = labels.Everything()
+	}
+	list := &v1alpha1.VaultSecretEngineList{}
+	for _, item := range obj.(*v1alpha1.VaultSecretEngineList).Items {
+		if label.Matches(labels.Set(item.Labels)) {
+			list.Items = append(list.Items, item)
+		}
+	}
+	return list, err
+}
+
+// Watch returns a watch.Interface that watches the requested vaultSecretEngines.
+func (c *FakeVaultSecretEngines) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	return c.Fake.
+		InvokesWatch(testing.NewWatchAction(vaultsecretenginesResource, c.ns, opts))
+
+}
+
+// Create takes the representation of a vaultSecretEngine and creates it.  Returns the server's representation of the vaultSecretEngine, and an error, if there is any.
+func (c *FakeVaultSecretEngines) Create(vaultSecretEngine *v1alpha1.VaultSecretEngine) (result *v1alpha1.VaultSecretEngine, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewCreateAction(vaultsecretenginesResource, c.ns, vaultSecretEngine), &v1alpha1.VaultSecretEngine{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VaultSecretEngine), err
+}
+
+// Update takes the representation of a vaultSecretEngine and updates it. Returns the server's representation of the vaultSecretEngine, and an error, if there is any.
+func (c *FakeVaultSecretEngines) Update(vaultSecretEngine *v1alpha1.VaultSecretEngine) (result *v1alpha1.VaultSecretEngine, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateAction(vaultsecretenginesResource, c.ns, vaultSecretEngine), &v1alpha1.VaultSecretEngine{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VaultSecretEngine), err
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+func (c *FakeVaultSecretEngines) UpdateStatus(vaultSecretEngine *v1alpha1.VaultSecretEngine) (*v1alpha1.VaultSecretEngine, error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewUpdateSubresourceAction(vaultsecretenginesResource, "status", c.ns, vaultSecretEngine), &v1alpha1.VaultSecretEngine{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VaultSecretEngine), err
+}
+
+// Delete takes name of the vaultSecretEngine and deletes it. Returns an error if one occurs.
+func (c *FakeVaultSecretEngines) Delete(name string, options *v1.DeleteOptions) error {
+	_, err := c.Fake.
+		Invokes(testing.NewDeleteAction(vaultsecretenginesResource, c.ns, name), &v1alpha1.VaultSecretEngine{})
+
+	return err
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *FakeVaultSecretEngines) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	action := testing.NewDeleteCollectionAction(vaultsecretenginesResource, c.ns, listOptions)
+
+	_, err := c.Fake.Invokes(action, &v1alpha1.VaultSecretEngineList{})
+	return err
+}
+
+// Patch applies the patch and returns the patched vaultSecretEngine.
+func (c *FakeVaultSecretEngines) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.VaultSecretEngine, err error) {
+	obj, err := c.Fake.
+		Invokes(testing.NewPatchSubresourceAction(vaultsecretenginesResource, c.ns, name, data, subresources...), &v1alpha1.VaultSecretEngine{})
+
+	if obj == nil {
+		return nil, err
+	}
+	return obj.(*v1alpha1.VaultSecretEngine), err
+}
@@ -15,3 +15,11 @@
 package v1alpha1
 
 type VaultServiceExpansion interface{}
+
+type VaultPolicyExpansion interface{}
+
+type VaultAuthMethodExpansion interface{}
+
+type VaultSecretEngineExpansion interface{}
+
+type VaultBackupExpansion interface{}
@@ -24,6 +24,10 @@ import (
 type VaultV1alpha1Interface interface {
 	RESTClient() rest.Interface
 	VaultServicesGetter
+	VaultBackupsGetter
+	VaultSecretEnginesGetter
+	VaultAuthMethodsGetter
+	VaultPoliciesGetter
 }
 
 // VaultV1alpha1Client is used to interact with features provided by the vault.security.coreos.com group.
@@ -35,6 +39,22 @@ func (c *VaultV1alpha1Client) VaultServices(namespace string) VaultServiceInterf
 	return newVaultServices(c, namespace)
 }
 
+func (c *VaultV1alpha1Client) VaultPolicies(namespace string) VaultPolicyInterface {
+	return newVaultPolicies(c, namespace)
+}
+
+func (c *VaultV1alpha1Client) VaultAuthMethods(namespace string) VaultAuthMethodInterface {
+	return newVaultAuthMethods(c, namespace)
+}
+
+func (c *VaultV1alpha1Client) VaultSecretEngines(namespace string) VaultSecretEngineInterface {
+	return newVaultSecretEngines(c, namespace)
+}
+
+func (c *VaultV1alpha1Client) VaultBackups(namespace string) VaultBackupInterface {
+	return newVaultBackups(c, namespace)
+}
+
 // NewForConfig creates a new VaultV1alpha1Client for the given config.
 func NewForConfig(c *rest.Config) (*VaultV1alpha1Client, error) {
 	config := *c
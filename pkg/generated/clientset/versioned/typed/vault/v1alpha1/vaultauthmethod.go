@@ -0,0 +1,170 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+	scheme "github.com/coreos/vault-operator/pkg/generated/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// VaultAuthMethodsGetter has a method to return a VaultAuthMethodInterface.
+// A group's client should implement this interface.
+type VaultAuthMethodsGetter interface {
+	VaultAuthMethods(namespace string) VaultAuthMethodInterface
+}
+
+// VaultAuthMethodInterface has methods to work with VaultAuthMethod resources.
+type VaultAuthMethodInterface interface {
+	Create(*v1alpha1.VaultAuthMethod) (*v1alpha1.VaultAuthMethod, error)
+	Update(*v1alpha1.VaultAuthMethod) (*v1alpha1.VaultAuthMethod, error)
+	UpdateStatus(*v1alpha1.VaultAuthMethod) (*v1alpha1.VaultAuthMethod, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1alpha1.VaultAuthMethod, error)
+	List(opts v1.ListOptions) (*v1alpha1.VaultAuthMethodList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.VaultAuthMethod, err error)
+	VaultAuthMethodExpansion
+}
+
+// vaultAuthMethods implements VaultAuthMethodInterface
+type vaultAuthMethods struct {
+	client rest.Interface
+	ns     string
+}
+
+// newVaultAuthMethods returns a VaultAuthMethods
+func newVaultAuthMethods(c *VaultV1alpha1Client, namespace string) *vaultAuthMethods {
+	return &vaultAuthMethods{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the vaultAuthMethod, and returns the corresponding vaultAuthMethod object, and an error if there is any.
+func (c *vaultAuthMethods) Get(name string, options v1.GetOptions) (result *v1alpha1.VaultAuthMethod, err error) {
+	result = &v1alpha1.VaultAuthMethod{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("vaultauthmethods").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of VaultAuthMethods that match those selectors.
+func (c *vaultAuthMethods) List(opts v1.ListOptions) (result *v1alpha1.VaultAuthMethodList, err error) {
+	result = &v1alpha1.VaultAuthMethodList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("vaultauthmethods").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested vaultAuthMethods.
+func (c *vaultAuthMethods) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("vaultauthmethods").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Watch()
+}
+
+// Create takes the representation of a vaultAuthMethod and creates it.  Returns the server's representation of the vaultAuthMethod, and an error, if there is any.
+func (c *vaultAuthMethods) Create(vaultAuthMethod *v1alpha1.VaultAuthMethod) (result *v1alpha1.VaultAuthMethod, err error) {
+	result = &v1alpha1.VaultAuthMethod{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("vaultauthmethods").
+		Body(vaultAuthMethod).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a vaultAuthMethod and updates it. Returns the server's representation of the vaultAuthMethod, and an error, if there is any.
+func (c *vaultAuthMethods) Update(vaultAuthMethod *v1alpha1.VaultAuthMethod) (result *v1alpha1.VaultAuthMethod, err error) {
+	result = &v1alpha1.VaultAuthMethod{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("vaultauthmethods").
+		Name(vaultAuthMethod.Name).
+		Body(vaultAuthMethod).
+		Do().
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+
+func (c *vaultAuthMethods) UpdateStatus(vaultAuthMethod *v1alpha1.VaultAuthMethod) (result *v1alpha1.VaultAuthMethod, err error) {
+	result = &v1alpha1.VaultAuthMethod{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("vaultauthmethods").
+		Name(vaultAuthMethod.Name).
+		SubResource("status").
+		Body(vaultAuthMethod).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the vaultAuthMethod and deletes it. Returns an error if one occurs.
+func (c *vaultAuthMethods) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("vaultauthmethods").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *vaultAuthMethods) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("vaultauthmethods").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched vaultAuthMethod.
+func (c *vaultAuthMethods) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.VaultAuthMethod, err error) {
+	result = &v1alpha1.VaultAuthMethod{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("vaultauthmethods").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
@@ -0,0 +1,170 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+	scheme "github.com/coreos/vault-operator/pkg/generated/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// VaultBackupsGetter has a method to return a VaultBackupInterface.
+// A group's client should implement this interface.
+type VaultBackupsGetter interface {
+	VaultBackups(namespace string) VaultBackupInterface
+}
+
+// VaultBackupInterface has methods to work with VaultBackup resources.
+type VaultBackupInterface interface {
+	Create(*v1alpha1.VaultBackup) (*v1alpha1.VaultBackup, error)
+	Update(*v1alpha1.VaultBackup) (*v1alpha1.VaultBackup, error)
+	UpdateStatus(*v1alpha1.VaultBackup) (*v1alpha1.VaultBackup, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1alpha1.VaultBackup, error)
+	List(opts v1.ListOptions) (*v1alpha1.VaultBackupList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.VaultBackup, err error)
+	VaultBackupExpansion
+}
+
+// vaultBackups implements VaultBackupInterface
+type vaultBackups struct {
+	client rest.Interface
+	ns     string
+}
+
+// newVaultBackups returns a VaultBackups
+func newVaultBackups(c *VaultV1alpha1Client, namespace string) *vaultBackups {
+	return &vaultBackups{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the vaultBackup, and returns the corresponding vaultBackup object, and an error if there is any.
+func (c *vaultBackups) Get(name string, options v1.GetOptions) (result *v1alpha1.VaultBackup, err error) {
+	result = &v1alpha1.VaultBackup{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("vaultbackups").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of VaultBackups that match those selectors.
+func (c *vaultBackups) List(opts v1.ListOptions) (result *v1alpha1.VaultBackupList, err error) {
+	result = &v1alpha1.VaultBackupList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("vaultbackups").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested vaultBackups.
+func (c *vaultBackups) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("vaultbackups").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Watch()
+}
+
+// Create takes the representation of a vaultBackup and creates it.  Returns the server's representation of the vaultBackup, and an error, if there is any.
+func (c *vaultBackups) Create(vaultBackup *v1alpha1.VaultBackup) (result *v1alpha1.VaultBackup, err error) {
+	result = &v1alpha1.VaultBackup{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("vaultbackups").
+		Body(vaultBackup).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a vaultBackup and updates it. Returns the server's representation of the vaultBackup, and an error, if there is any.
+func (c *vaultBackups) Update(vaultBackup *v1alpha1.VaultBackup) (result *v1alpha1.VaultBackup, err error) {
+	result = &v1alpha1.VaultBackup{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("vaultbackups").
+		Name(vaultBackup.Name).
+		Body(vaultBackup).
+		Do().
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+
+func (c *vaultBackups) UpdateStatus(vaultBackup *v1alpha1.VaultBackup) (result *v1alpha1.VaultBackup, err error) {
+	result = &v1alpha1.VaultBackup{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("vaultbackups").
+		Name(vaultBackup.Name).
+		SubResource("status").
+		Body(vaultBackup).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the vaultBackup and deletes it. Returns an error if one occurs.
+func (c *vaultBackups) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("vaultbackups").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *vaultBackups) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("vaultbackups").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched vaultBackup.
+func (c *vaultBackups) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.VaultBackup, err error) {
+	result = &v1alpha1.VaultBackup{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("vaultbackups").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
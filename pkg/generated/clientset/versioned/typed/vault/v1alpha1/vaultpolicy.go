@@ -0,0 +1,170 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+	scheme "github.com/coreos/vault-operator/pkg/generated/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// VaultPoliciesGetter has a method to return a VaultPolicyInterface.
+// A group's client should implement this interface.
+type VaultPoliciesGetter interface {
+	VaultPolicies(namespace string) VaultPolicyInterface
+}
+
+// VaultPolicyInterface has methods to work with VaultPolicy resources.
+type VaultPolicyInterface interface {
+	Create(*v1alpha1.VaultPolicy) (*v1alpha1.VaultPolicy, error)
+	Update(*v1alpha1.VaultPolicy) (*v1alpha1.VaultPolicy, error)
+	UpdateStatus(*v1alpha1.VaultPolicy) (*v1alpha1.VaultPolicy, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1alpha1.VaultPolicy, error)
+	List(opts v1.ListOptions) (*v1alpha1.VaultPolicyList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.VaultPolicy, err error)
+	VaultPolicyExpansion
+}
+
+// vaultPolicies implements VaultPolicyInterface
+type vaultPolicies struct {
+	client rest.Interface
+	ns     string
+}
+
+// newVaultPolicies returns a VaultPolicies
+func newVaultPolicies(c *VaultV1alpha1Client, namespace string) *vaultPolicies {
+	return &vaultPolicies{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the vaultPolicy, and returns the corresponding vaultPolicy object, and an error if there is any.
+func (c *vaultPolicies) Get(name string, options v1.GetOptions) (result *v1alpha1.VaultPolicy, err error) {
+	result = &v1alpha1.VaultPolicy{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("vaultpolicies").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of VaultPolicies that match those selectors.
+func (c *vaultPolicies) List(opts v1.ListOptions) (result *v1alpha1.VaultPolicyList, err error) {
+	result = &v1alpha1.VaultPolicyList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("vaultpolicies").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested vaultPolicies.
+func (c *vaultPolicies) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("vaultpolicies").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Watch()
+}
+
+// Create takes the representation of a vaultPolicy and creates it.  Returns the server's representation of the vaultPolicy, and an error, if there is any.
+func (c *vaultPolicies) Create(vaultPolicy *v1alpha1.VaultPolicy) (result *v1alpha1.VaultPolicy, err error) {
+	result = &v1alpha1.VaultPolicy{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("vaultpolicies").
+		Body(vaultPolicy).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a vaultPolicy and updates it. Returns the server's representation of the vaultPolicy, and an error, if there is any.
+func (c *vaultPolicies) Update(vaultPolicy *v1alpha1.VaultPolicy) (result *v1alpha1.VaultPolicy, err error) {
+	result = &v1alpha1.VaultPolicy{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("vaultpolicies").
+		Name(vaultPolicy.Name).
+		Body(vaultPolicy).
+		Do().
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+
+func (c *vaultPolicies) UpdateStatus(vaultPolicy *v1alpha1.VaultPolicy) (result *v1alpha1.VaultPolicy, err error) {
+	result = &v1alpha1.VaultPolicy{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("vaultpolicies").
+		Name(vaultPolicy.Name).
+		SubResource("status").
+		Body(vaultPolicy).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the vaultPolicy and deletes it. Returns an error if one occurs.
+func (c *vaultPolicies) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("vaultpolicies").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *vaultPolicies) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("vaultpolicies").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched vaultPolicy.
+func (c *vaultPolicies) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.VaultPolicy, err error) {
+	result = &v1alpha1.VaultPolicy{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("vaultpolicies").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
@@ -0,0 +1,170 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+	scheme "github.com/coreos/vault-operator/pkg/generated/clientset/versioned/scheme"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	types "k8s.io/apimachinery/pkg/types"
+	watch "k8s.io/apimachinery/pkg/watch"
+	rest "k8s.io/client-go/rest"
+)
+
+// VaultSecretEnginesGetter has a method to return a VaultSecretEngineInterface.
+// A group's client should implement this interface.
+type VaultSecretEnginesGetter interface {
+	VaultSecretEngines(namespace string) VaultSecretEngineInterface
+}
+
+// VaultSecretEngineInterface has methods to work with VaultSecretEngine resources.
+type VaultSecretEngineInterface interface {
+	Create(*v1alpha1.VaultSecretEngine) (*v1alpha1.VaultSecretEngine, error)
+	Update(*v1alpha1.VaultSecretEngine) (*v1alpha1.VaultSecretEngine, error)
+	UpdateStatus(*v1alpha1.VaultSecretEngine) (*v1alpha1.VaultSecretEngine, error)
+	Delete(name string, options *v1.DeleteOptions) error
+	DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error
+	Get(name string, options v1.GetOptions) (*v1alpha1.VaultSecretEngine, error)
+	List(opts v1.ListOptions) (*v1alpha1.VaultSecretEngineList, error)
+	Watch(opts v1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.VaultSecretEngine, err error)
+	VaultSecretEngineExpansion
+}
+
+// vaultSecretEngines implements VaultSecretEngineInterface
+type vaultSecretEngines struct {
+	client rest.Interface
+	ns     string
+}
+
+// newVaultSecretEngines returns a VaultSecretEngines
+func newVaultSecretEngines(c *VaultV1alpha1Client, namespace string) *vaultSecretEngines {
+	return &vaultSecretEngines{
+		client: c.RESTClient(),
+		ns:     namespace,
+	}
+}
+
+// Get takes name of the vaultSecretEngine, and returns the corresponding vaultSecretEngine object, and an error if there is any.
+func (c *vaultSecretEngines) Get(name string, options v1.GetOptions) (result *v1alpha1.VaultSecretEngine, err error) {
+	result = &v1alpha1.VaultSecretEngine{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("vaultsecretengines").
+		Name(name).
+		VersionedParams(&options, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// List takes label and field selectors, and returns the list of VaultSecretEngines that match those selectors.
+func (c *vaultSecretEngines) List(opts v1.ListOptions) (result *v1alpha1.VaultSecretEngineList, err error) {
+	result = &v1alpha1.VaultSecretEngineList{}
+	err = c.client.Get().
+		Namespace(c.ns).
+		Resource("vaultsecretengines").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Do().
+		Into(result)
+	return
+}
+
+// Watch returns a watch.Interface that watches the requested vaultSecretEngines.
+func (c *vaultSecretEngines) Watch(opts v1.ListOptions) (watch.Interface, error) {
+	opts.Watch = true
+	return c.client.Get().
+		Namespace(c.ns).
+		Resource("vaultsecretengines").
+		VersionedParams(&opts, scheme.ParameterCodec).
+		Watch()
+}
+
+// Create takes the representation of a vaultSecretEngine and creates it.  Returns the server's representation of the vaultSecretEngine, and an error, if there is any.
+func (c *vaultSecretEngines) Create(vaultSecretEngine *v1alpha1.VaultSecretEngine) (result *v1alpha1.VaultSecretEngine, err error) {
+	result = &v1alpha1.VaultSecretEngine{}
+	err = c.client.Post().
+		Namespace(c.ns).
+		Resource("vaultsecretengines").
+		Body(vaultSecretEngine).
+		Do().
+		Into(result)
+	return
+}
+
+// Update takes the representation of a vaultSecretEngine and updates it. Returns the server's representation of the vaultSecretEngine, and an error, if there is any.
+func (c *vaultSecretEngines) Update(vaultSecretEngine *v1alpha1.VaultSecretEngine) (result *v1alpha1.VaultSecretEngine, err error) {
+	result = &v1alpha1.VaultSecretEngine{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("vaultsecretengines").
+		Name(vaultSecretEngine.Name).
+		Body(vaultSecretEngine).
+		Do().
+		Into(result)
+	return
+}
+
+// UpdateStatus was generated because the type contains a Status member.
+// Add a +genclient:noStatus comment above the type to avoid generating UpdateStatus().
+
+func (c *vaultSecretEngines) UpdateStatus(vaultSecretEngine *v1alpha1.VaultSecretEngine) (result *v1alpha1.VaultSecretEngine, err error) {
+	result = &v1alpha1.VaultSecretEngine{}
+	err = c.client.Put().
+		Namespace(c.ns).
+		Resource("vaultsecretengines").
+		Name(vaultSecretEngine.Name).
+		SubResource("status").
+		Body(vaultSecretEngine).
+		Do().
+		Into(result)
+	return
+}
+
+// Delete takes name of the vaultSecretEngine and deletes it. Returns an error if one occurs.
+func (c *vaultSecretEngines) Delete(name string, options *v1.DeleteOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("vaultsecretengines").
+		Name(name).
+		Body(options).
+		Do().
+		Error()
+}
+
+// DeleteCollection deletes a collection of objects.
+func (c *vaultSecretEngines) DeleteCollection(options *v1.DeleteOptions, listOptions v1.ListOptions) error {
+	return c.client.Delete().
+		Namespace(c.ns).
+		Resource("vaultsecretengines").
+		VersionedParams(&listOptions, scheme.ParameterCodec).
+		Body(options).
+		Do().
+		Error()
+}
+
+// Patch applies the patch and returns the patched vaultSecretEngine.
+func (c *vaultSecretEngines) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (result *v1alpha1.VaultSecretEngine, err error) {
+	result = &v1alpha1.VaultSecretEngine{}
+	err = c.client.Patch(pt).
+		Namespace(c.ns).
+		Resource("vaultsecretengines").
+		SubResource(subresources...).
+		Name(name).
+		Body(data).
+		Do().
+		Into(result)
+	return
+}
@@ -52,6 +52,14 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	// Group=Vault, Version=V1alpha1
 	case v1alpha1.SchemeGroupVersion.WithResource("vaultservices"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Vault().V1alpha1().VaultServices().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("vaultbackups"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Vault().V1alpha1().VaultBackups().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("vaultsecretengines"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Vault().V1alpha1().VaultSecretEngines().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("vaultauthmethods"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Vault().V1alpha1().VaultAuthMethods().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("vaultpolicies"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Vault().V1alpha1().VaultPolicies().Informer()}, nil
 
 	}
 
@@ -24,6 +24,14 @@ import (
 type Interface interface {
 	// VaultServices returns a VaultServiceInformer.
 	VaultServices() VaultServiceInformer
+	// VaultPolicies returns a VaultPolicyInformer.
+	VaultPolicies() VaultPolicyInformer
+	// VaultAuthMethods returns a VaultAuthMethodInformer.
+	VaultAuthMethods() VaultAuthMethodInformer
+	// VaultSecretEngines returns a VaultSecretEngineInformer.
+	VaultSecretEngines() VaultSecretEngineInformer
+	// VaultBackups returns a VaultBackupInformer.
+	VaultBackups() VaultBackupInformer
 }
 
 type version struct {
@@ -39,3 +47,23 @@ func New(f internalinterfaces.SharedInformerFactory) Interface {
 func (v *version) VaultServices() VaultServiceInformer {
 	return &vaultServiceInformer{factory: v.SharedInformerFactory}
 }
+
+// VaultPolicies returns a VaultPolicyInformer.
+func (v *version) VaultPolicies() VaultPolicyInformer {
+	return &vaultPolicyInformer{factory: v.SharedInformerFactory}
+}
+
+// VaultAuthMethods returns a VaultAuthMethodInformer.
+func (v *version) VaultAuthMethods() VaultAuthMethodInformer {
+	return &vaultAuthMethodInformer{factory: v.SharedInformerFactory}
+}
+
+// VaultSecretEngines returns a VaultSecretEngineInformer.
+func (v *version) VaultSecretEngines() VaultSecretEngineInformer {
+	return &vaultSecretEngineInformer{factory: v.SharedInformerFactory}
+}
+
+// VaultBackups returns a VaultBackupInformer.
+func (v *version) VaultBackups() VaultBackupInformer {
+	return &vaultBackupInformer{factory: v.SharedInformerFactory}
+}
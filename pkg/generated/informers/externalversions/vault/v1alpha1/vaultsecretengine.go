@@ -0,0 +1,71 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This file was automatically generated by informer-gen
+
+package v1alpha1
+
+import (
+	vault_v1alpha1 "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+	versioned "github.com/coreos/vault-operator/pkg/generated/clientset/versioned"
+	internalinterfaces "github.com/coreos/vault-operator/pkg/generated/informers/externalversions/internalinterfaces"
+	v1alpha1 "github.com/coreos/vault-operator/pkg/generated/listers/vault/v1alpha1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+	watch "k8s.io/apimachinery/pkg/watch"
+	cache "k8s.io/client-go/tools/cache"
+	time "time"
+)
+
+// VaultSecretEngineInformer provides access to a shared informer and lister for
+// VaultSecretEngines.
+type VaultSecretEngineInformer interface {
+	Informer() cache.SharedIndexInformer
+	Lister() v1alpha1.VaultSecretEngineLister
+}
+
+type vaultSecretEngineInformer struct {
+	factory internalinterfaces.SharedInformerFactory
+}
+
+// NewVaultSecretEngineInformer constructs a new informer for VaultSecretEngine type.
+// Always prefer using an informer factory to get a shared informer instead of getting an independent
+// one. This reduces memory footprint and number of connections to the server.
+func NewVaultSecretEngineInformer(client versioned.Interface, namespace string, resyncPeriod time.Duration, indexers cache.Indexers) cache.SharedIndexInformer {
+	return cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: func(options v1.ListOptions) (runtime.Object, error) {
+				return client.VaultV1alpha1().VaultSecretEngines(namespace).List(options)
+			},
+			WatchFunc: func(options v1.ListOptions) (watch.Interface, error) {
+				return client.VaultV1alpha1().VaultSecretEngines(namespace).Watch(options)
+			},
+		},
+		&vault_v1alpha1.VaultSecretEngine{},
+		resyncPeriod,
+		indexers,
+	)
+}
+
+func defaultVaultSecretEngineInformer(client versioned.Interface, resyncPeriod time.Duration) cache.SharedIndexInformer {
+	return NewVaultSecretEngineInformer(client, v1.NamespaceAll, resyncPeriod, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+}
+
+func (f *vaultSecretEngineInformer) Informer() cache.SharedIndexInformer {
+	return f.factory.InformerFor(&vault_v1alpha1.VaultSecretEngine{}, defaultVaultSecretEngineInformer)
+}
+
+func (f *vaultSecretEngineInformer) Lister() v1alpha1.VaultSecretEngineLister {
+	return v1alpha1.NewVaultSecretEngineLister(f.Informer().GetIndexer())
+}
@@ -23,3 +23,35 @@ type VaultServiceListerExpansion interface{}
 // VaultServiceNamespaceListerExpansion allows custom methods to be added to
 // VaultServiceNamespaceLister.
 type VaultServiceNamespaceListerExpansion interface{}
+
+// VaultPolicyListerExpansion allows custom methods to be added to
+// VaultPolicyLister.
+type VaultPolicyListerExpansion interface{}
+
+// VaultPolicyNamespaceListerExpansion allows custom methods to be added to
+// VaultPolicyNamespaceLister.
+type VaultPolicyNamespaceListerExpansion interface{}
+
+// VaultAuthMethodListerExpansion allows custom methods to be added to
+// VaultAuthMethodLister.
+type VaultAuthMethodListerExpansion interface{}
+
+// VaultAuthMethodNamespaceListerExpansion allows custom methods to be added to
+// VaultAuthMethodNamespaceLister.
+type VaultAuthMethodNamespaceListerExpansion interface{}
+
+// VaultSecretEngineListerExpansion allows custom methods to be added to
+// VaultSecretEngineLister.
+type VaultSecretEngineListerExpansion interface{}
+
+// VaultSecretEngineNamespaceListerExpansion allows custom methods to be added to
+// VaultSecretEngineNamespaceLister.
+type VaultSecretEngineNamespaceListerExpansion interface{}
+
+// VaultBackupListerExpansion allows custom methods to be added to
+// VaultBackupLister.
+type VaultBackupListerExpansion interface{}
+
+// VaultBackupNamespaceListerExpansion allows custom methods to be added to
+// VaultBackupNamespaceLister.
+type VaultBackupNamespaceListerExpansion interface{}
@@ -0,0 +1,92 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This file was automatically generated by lister-gen
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// VaultAuthMethodLister helps list VaultAuthMethods.
+type VaultAuthMethodLister interface {
+	// List lists all VaultAuthMethods in the indexer.
+	List(selector labels.Selector) (ret []*v1alpha1.VaultAuthMethod, err error)
+	// VaultAuthMethods returns an object that can list and get VaultAuthMethods.
+	VaultAuthMethods(namespace string) VaultAuthMethodNamespaceLister
+	VaultAuthMethodListerExpansion
+}
+
+// vaultAuthMethodLister implements the VaultAuthMethodLister interface.
+type vaultAuthMethodLister struct {
+	indexer cache.Indexer
+}
+
+// NewVaultAuthMethodLister returns a new VaultAuthMethodLister.
+func NewVaultAuthMethodLister(indexer cache.Indexer) VaultAuthMethodLister {
+	return &vaultAuthMethodLister{indexer: indexer}
+}
+
+// List lists all VaultAuthMethods in the indexer.
+func (s *vaultAuthMethodLister) List(selector labels.Selector) (ret []*v1alpha1.VaultAuthMethod, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.VaultAuthMethod))
+	})
+	return ret, err
+}
+
+// VaultAuthMethods returns an object that can list and get VaultAuthMethods.
+func (s *vaultAuthMethodLister) VaultAuthMethods(namespace string) VaultAuthMethodNamespaceLister {
+	return vaultAuthMethodNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// VaultAuthMethodNamespaceLister helps list and get VaultAuthMethods.
+type VaultAuthMethodNamespaceLister interface {
+	// List lists all VaultAuthMethods in the indexer for a given namespace.
+	List(selector labels.Selector) (ret []*v1alpha1.VaultAuthMethod, err error)
+	// Get retrieves the VaultAuthMethod from the indexer for a given namespace and name.
+	Get(name string) (*v1alpha1.VaultAuthMethod, error)
+	VaultAuthMethodNamespaceListerExpansion
+}
+
+// vaultAuthMethodNamespaceLister implements the VaultAuthMethodNamespaceLister
+// interface.
+type vaultAuthMethodNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all VaultAuthMethods in the indexer for a given namespace.
+func (s vaultAuthMethodNamespaceLister) List(selector labels.Selector) (ret []*v1alpha1.VaultAuthMethod, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.VaultAuthMethod))
+	})
+	return ret, err
+}
+
+// Get retrieves the VaultAuthMethod from the indexer for a given namespace and name.
+func (s vaultAuthMethodNamespaceLister) Get(name string) (*v1alpha1.VaultAuthMethod, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha1.Resource("vaultauthmethod"), name)
+	}
+	return obj.(*v1alpha1.VaultAuthMethod), nil
+}
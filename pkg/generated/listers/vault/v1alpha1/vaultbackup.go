@@ -0,0 +1,92 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This file was automatically generated by lister-gen
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// VaultBackupLister helps list VaultBackups.
+type VaultBackupLister interface {
+	// List lists all VaultBackups in the indexer.
+	List(selector labels.Selector) (ret []*v1alpha1.VaultBackup, err error)
+	// VaultBackups returns an object that can list and get VaultBackups.
+	VaultBackups(namespace string) VaultBackupNamespaceLister
+	VaultBackupListerExpansion
+}
+
+// vaultBackupLister implements the VaultBackupLister interface.
+type vaultBackupLister struct {
+	indexer cache.Indexer
+}
+
+// NewVaultBackupLister returns a new VaultBackupLister.
+func NewVaultBackupLister(indexer cache.Indexer) VaultBackupLister {
+	return &vaultBackupLister{indexer: indexer}
+}
+
+// List lists all VaultBackups in the indexer.
+func (s *vaultBackupLister) List(selector labels.Selector) (ret []*v1alpha1.VaultBackup, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.VaultBackup))
+	})
+	return ret, err
+}
+
+// VaultBackups returns an object that can list and get VaultBackups.
+func (s *vaultBackupLister) VaultBackups(namespace string) VaultBackupNamespaceLister {
+	return vaultBackupNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// VaultBackupNamespaceLister helps list and get VaultBackups.
+type VaultBackupNamespaceLister interface {
+	// List lists all VaultBackups in the indexer for a given namespace.
+	List(selector labels.Selector) (ret []*v1alpha1.VaultBackup, err error)
+	// Get retrieves the VaultBackup from the indexer for a given namespace and name.
+	Get(name string) (*v1alpha1.VaultBackup, error)
+	VaultBackupNamespaceListerExpansion
+}
+
+// vaultBackupNamespaceLister implements the VaultBackupNamespaceLister
+// interface.
+type vaultBackupNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all VaultBackups in the indexer for a given namespace.
+func (s vaultBackupNamespaceLister) List(selector labels.Selector) (ret []*v1alpha1.VaultBackup, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.VaultBackup))
+	})
+	return ret, err
+}
+
+// Get retrieves the VaultBackup from the indexer for a given namespace and name.
+func (s vaultBackupNamespaceLister) Get(name string) (*v1alpha1.VaultBackup, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha1.Resource("vaultbackup"), name)
+	}
+	return obj.(*v1alpha1.VaultBackup), nil
+}
@@ -0,0 +1,92 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// This file was automatically generated by lister-gen
+
+package v1alpha1
+
+import (
+	v1alpha1 "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+// VaultSecretEngineLister helps list VaultSecretEngines.
+type VaultSecretEngineLister interface {
+	// List lists all VaultSecretEngines in the indexer.
+	List(selector labels.Selector) (ret []*v1alpha1.VaultSecretEngine, err error)
+	// VaultSecretEngines returns an object that can list and get VaultSecretEngines.
+	VaultSecretEngines(namespace string) VaultSecretEngineNamespaceLister
+	VaultSecretEngineListerExpansion
+}
+
+// vaultSecretEngineLister implements the VaultSecretEngineLister interface.
+type vaultSecretEngineLister struct {
+	indexer cache.Indexer
+}
+
+// NewVaultSecretEngineLister returns a new VaultSecretEngineLister.
+func NewVaultSecretEngineLister(indexer cache.Indexer) VaultSecretEngineLister {
+	return &vaultSecretEngineLister{indexer: indexer}
+}
+
+// List lists all VaultSecretEngines in the indexer.
+func (s *vaultSecretEngineLister) List(selector labels.Selector) (ret []*v1alpha1.VaultSecretEngine, err error) {
+	err = cache.ListAll(s.indexer, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.VaultSecretEngine))
+	})
+	return ret, err
+}
+
+// VaultSecretEngines returns an object that can list and get VaultSecretEngines.
+func (s *vaultSecretEngineLister) VaultSecretEngines(namespace string) VaultSecretEngineNamespaceLister {
+	return vaultSecretEngineNamespaceLister{indexer: s.indexer, namespace: namespace}
+}
+
+// VaultSecretEngineNamespaceLister helps list and get VaultSecretEngines.
+type VaultSecretEngineNamespaceLister interface {
+	// List lists all VaultSecretEngines in the indexer for a given namespace.
+	List(selector labels.Selector) (ret []*v1alpha1.VaultSecretEngine, err error)
+	// Get retrieves the VaultSecretEngine from the indexer for a given namespace and name.
+	Get(name string) (*v1alpha1.VaultSecretEngine, error)
+	VaultSecretEngineNamespaceListerExpansion
+}
+
+// vaultSecretEngineNamespaceLister implements the VaultSecretEngineNamespaceLister
+// interface.
+type vaultSecretEngineNamespaceLister struct {
+	indexer   cache.Indexer
+	namespace string
+}
+
+// List lists all VaultSecretEngines in the indexer for a given namespace.
+func (s vaultSecretEngineNamespaceLister) List(selector labels.Selector) (ret []*v1alpha1.VaultSecretEngine, err error) {
+	err = cache.ListAllByNamespace(s.indexer, s.namespace, selector, func(m interface{}) {
+		ret = append(ret, m.(*v1alpha1.VaultSecretEngine))
+	})
+	return ret, err
+}
+
+// Get retrieves the VaultSecretEngine from the indexer for a given namespace and name.
+func (s vaultSecretEngineNamespaceLister) Get(name string) (*v1alpha1.VaultSecretEngine, error) {
+	obj, exists, err := s.indexer.GetByKey(s.namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errors.NewNotFound(v1alpha1.Resource("vaultsecretengine"), name)
+	}
+	return obj.(*v1alpha1.VaultSecretEngine), nil
+}
@@ -0,0 +1,163 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strings"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+	"github.com/coreos/vault-operator/pkg/util/vaultutil"
+
+	vaultapi "github.com/hashicorp/vault/api"
+	"k8s.io/api/core/v1"
+)
+
+const (
+	// auditDeviceDescription marks the audit devices enabled by the operator,
+	// so that the ones removed from spec.audit are disabled again.
+	auditDeviceDescription = "Managed by the vault operator"
+
+	reasonAuditSyncFailed = "SyncFailed"
+	reasonNoAuditDevice   = "NoAuditDevice"
+)
+
+// syncAudit enables the audit devices of the vault service via the active vault node, disables the ones
+// the operator enabled before but are no longer specified, and records whether vault is audited
+// as the AuditEnabled condition of the vault CR.
+func (v *Vaults) syncAudit(vr *api.VaultService, active string, tlsConfig *tls.Config) error {
+	n, serr := v.enableAuditDevices(vr, active, tlsConfig)
+	c := api.NewCondition(api.VaultServiceAuditEnabled, v1.ConditionTrue, "", "")
+	if serr != nil {
+		c = api.NewCondition(api.VaultServiceAuditEnabled, v1.ConditionFalse, reasonAuditSyncFailed, serr.Error())
+	} else if n == 0 {
+		c = api.NewCondition(api.VaultServiceAuditEnabled, v1.ConditionFalse, reasonNoAuditDevice, "no audit device is enabled in vault")
+	}
+	if err := v.updateVaultCRCondition(vr, c); err != nil {
+		return err
+	}
+	return serr
+}
+
+// enableAuditDevices brings the audit devices of vault to spec.audit, and returns the number of audit devices enabled in vault.
+func (v *Vaults) enableAuditDevices(vr *api.VaultService, active string, tlsConfig *tls.Config) (int, error) {
+	vapi, err := v.newVaultClient(vr, active, tlsConfig)
+	if err != nil {
+		return 0, err
+	}
+	devices, err := vapi.Sys().ListAudit()
+	if err != nil {
+		return 0, fmt.Errorf("list audit devices failed: %v", err)
+	}
+
+	want := auditDevices(vr.Spec.Audit)
+	for path, d := range want {
+		cur, ok := devices[path+"/"]
+		if ok && cur.Type == d.Type && auditOptionsMatch(cur.Options, d.Options) {
+			continue
+		}
+		if ok {
+			if cur.Description != auditDeviceDescription {
+				return len(devices), fmt.Errorf("path (%s) is used by an audit device not enabled by the operator", path)
+			}
+			// Audit devices cannot be tuned, so they are enabled again with the new options.
+			err = vapi.Sys().DisableAudit(path)
+			if err != nil {
+				return len(devices), fmt.Errorf("disable audit device (%s) failed: %v", path, err)
+			}
+			delete(devices, path+"/")
+		}
+		err = vapi.Sys().EnableAuditWithOptions(path, d)
+		if err != nil {
+			return len(devices), fmt.Errorf("enable audit device (%s) failed: %v", path, err)
+		}
+		devices[path+"/"] = &vaultapi.Audit{Path: path + "/", Type: d.Type, Description: d.Description, Options: d.Options}
+	}
+	for key, cur := range devices {
+		path := strings.TrimSuffix(key, "/")
+		if _, ok := want[path]; ok || cur.Description != auditDeviceDescription {
+			continue
+		}
+		err = vapi.Sys().DisableAudit(path)
+		if err != nil {
+			return len(devices), fmt.Errorf("disable audit device (%s) failed: %v", path, err)
+		}
+		delete(devices, key)
+	}
+	return len(devices), nil
+}
+
+// auditOptionsMatch returns true if the options of an audit device in vault have the given values.
+// Vault reports options it defaults, such as "hmac_accessor", too, so only the given keys are compared.
+func auditOptionsMatch(cur, want map[string]string) bool {
+	for k, val := range want {
+		if cv, ok := cur[k]; !ok || cv != val {
+			return false
+		}
+	}
+	return true
+}
+
+// auditDevices returns the audit devices of the audit policy by the path they are enabled at.
+func auditDevices(a *api.AuditPolicy) map[string]*vaultapi.EnableAuditOptions {
+	devices := map[string]*vaultapi.EnableAuditOptions{}
+	if a == nil {
+		return devices
+	}
+	if a.File != nil {
+		devices["file"] = newAuditDevice("file", a.File.Options, map[string]string{
+			"file_path": vaultutil.VaultAuditLogPath,
+		})
+	}
+	if a.Socket != nil {
+		socketType := a.Socket.SocketType
+		if len(socketType) == 0 {
+			socketType = "tcp"
+		}
+		devices["socket"] = newAuditDevice("socket", a.Socket.Options, map[string]string{
+			"address":     a.Socket.Address,
+			"socket_type": socketType,
+		})
+	}
+	if a.Syslog != nil {
+		opts := map[string]string{}
+		if len(a.Syslog.Facility) != 0 {
+			opts["facility"] = a.Syslog.Facility
+		}
+		if len(a.Syslog.Tag) != 0 {
+			opts["tag"] = a.Syslog.Tag
+		}
+		devices["syslog"] = newAuditDevice("syslog", a.Syslog.Options, opts)
+	}
+	return devices
+}
+
+// newAuditDevice returns an audit device of the given type with the given options.
+// The typed options take precedence over the additional ones.
+func newAuditDevice(typ string, extra, opts map[string]string) *vaultapi.EnableAuditOptions {
+	options := map[string]string{}
+	for k, val := range extra {
+		options[k] = val
+	}
+	for k, val := range opts {
+		options[k] = val
+	}
+	return &vaultapi.EnableAuditOptions{
+		Type:        typ,
+		Description: auditDeviceDescription,
+		Options:     options,
+	}
+}
@@ -0,0 +1,52 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import "testing"
+
+func TestAuditOptionsMatch(t *testing.T) {
+	tests := []struct {
+		name string
+		cur  map[string]string
+		want map[string]string
+		ok   bool
+	}{{
+		name: "same options",
+		cur:  map[string]string{"file_path": "/run/vault/audit/audit.log"},
+		want: map[string]string{"file_path": "/run/vault/audit/audit.log"},
+		ok:   true,
+	}, {
+		name: "options defaulted by vault",
+		cur:  map[string]string{"file_path": "/run/vault/audit/audit.log", "hmac_accessor": "true"},
+		want: map[string]string{"file_path": "/run/vault/audit/audit.log"},
+		ok:   true,
+	}, {
+		name: "changed option",
+		cur:  map[string]string{"file_path": "/var/log/vault/audit.log"},
+		want: map[string]string{"file_path": "/run/vault/audit/audit.log"},
+		ok:   false,
+	}, {
+		name: "missing option",
+		cur:  map[string]string{"file_path": "/run/vault/audit/audit.log"},
+		want: map[string]string{"file_path": "/run/vault/audit/audit.log", "format": "jsonx"},
+		ok:   false,
+	}}
+
+	for _, tt := range tests {
+		if got := auditOptionsMatch(tt.cur, tt.want); got != tt.ok {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+	"github.com/coreos/vault-operator/pkg/util/cronutil"
+	"github.com/coreos/vault-operator/pkg/util/k8sutil"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// scheduledBackupTimeout is how long a scheduled backup may take before it is failed,
+// so that a backup stuck e.g. on an unschedulable job doesn't hold up the later ones.
+const scheduledBackupTimeout = time.Hour
+
+// syncScheduledBackups takes a backup of the vault service when one is due on the schedule of spec.backup,
+// prunes the backups out of the retention, and records the outcome of the latest backup as the BackupSucceeded condition.
+// A backup is only taken once the previous one is done, and a missed schedule is caught up on with a single backup.
+func (v *Vaults) syncScheduledBackups(vr *api.VaultService) error {
+	sched, err := cronutil.Parse(vr.Spec.Backup.Schedule)
+	if err != nil {
+		return err
+	}
+	backups, err := v.listScheduledBackups(vr)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	if len(backups) != 0 && !isBackupDone(&backups[0]) && now.Sub(backups[0].CreationTimestamp.Time) > scheduledBackupTimeout {
+		err = v.failScheduledBackup(&backups[0])
+		if err != nil {
+			return err
+		}
+	}
+	last := vr.CreationTimestamp.Time
+	if len(backups) != 0 {
+		last = backups[0].CreationTimestamp.Time
+	}
+	next := sched.Next(last)
+	if !next.IsZero() && !next.After(now) && (len(backups) == 0 || isBackupDone(&backups[0])) {
+		b, err := v.vaultsCRCli.VaultV1alpha1().VaultBackups(vr.Namespace).Create(k8sutil.NewScheduledBackup(vr, now))
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create scheduled backup: %v", err)
+		}
+		if err == nil {
+			logrus.Infof("started scheduled backup (%s) of the vault service (%s)", b.Name, vr.Name)
+		}
+	}
+
+	if r := vr.Spec.Backup.Retention; r != nil {
+		for _, b := range backupsToPrune(backups, r, now) {
+			err = v.vaultsCRCli.VaultV1alpha1().VaultBackups(vr.Namespace).Delete(b.Name, nil)
+			if err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("failed to prune backup (%s): %v", b.Name, err)
+			}
+			logrus.Infof("pruned backup (%s) of the vault service (%s)", b.Name, vr.Name)
+		}
+	}
+
+	return v.updateBackupCondition(vr, backups)
+}
+
+// failScheduledBackup marks the given backup as failed because it did not finish in time.
+// The EtcdBackup or job taking the snapshot is left to be deleted with the backup when it is pruned.
+func (v *Vaults) failScheduledBackup(b *api.VaultBackup) error {
+	b.Status.Phase = api.BackupPhaseFailed
+	b.Status.Reason = reasonBackupTimedOut
+	b.Status.Message = fmt.Sprintf("backup did not finish within %v", scheduledBackupTimeout)
+	nb, err := v.vaultsCRCli.VaultV1alpha1().VaultBackups(b.Namespace).Update(b)
+	if err != nil {
+		return fmt.Errorf("failed to update status of timed out backup (%s): %v", b.Name, err)
+	}
+	*b = *nb
+	logrus.Warningf("scheduled backup (%s) timed out", b.Name)
+	return nil
+}
+
+// listScheduledBackups returns the backups taken on the schedule of the vault service, the latest first.
+func (v *Vaults) listScheduledBackups(vr *api.VaultService) ([]api.VaultBackup, error) {
+	sel := k8sutil.LabelsForVault(vr.Name)
+	sel[k8sutil.ScheduledBackupLabel] = "true"
+	l, err := v.vaultsCRCli.VaultV1alpha1().VaultBackups(vr.Namespace).List(metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(sel).String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list scheduled backups: %v", err)
+	}
+	backups := l.Items
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[j].CreationTimestamp.Before(&backups[i].CreationTimestamp)
+	})
+	return backups, nil
+}
+
+// backupsToPrune returns the backups, given the latest first, that are out of the retention.
+// The latest successful backup is always kept. Failed backups are pruned once a later backup succeeded,
+// and beyond the max number of failed backups to keep or the max age otherwise.
+func backupsToPrune(backups []api.VaultBackup, r *api.BackupRetention, now time.Time) []*api.VaultBackup {
+	var maxAge time.Duration
+	if len(r.MaxAge) != 0 {
+		// The retention is validated with the vault service.
+		maxAge, _ = time.ParseDuration(r.MaxAge)
+	}
+
+	var prune []*api.VaultBackup
+	succeeded, failed := 0, 0
+	for i := range backups {
+		b := &backups[i]
+		if b.DeletionTimestamp != nil {
+			continue
+		}
+		switch b.Status.Phase {
+		case api.BackupPhaseSucceeded:
+			succeeded++
+			if succeeded == 1 {
+				continue
+			}
+			if (r.MaxBackups > 0 && succeeded > r.MaxBackups) || (maxAge > 0 && now.Sub(b.CreationTimestamp.Time) > maxAge) {
+				prune = append(prune, b)
+			}
+		case api.BackupPhaseFailed:
+			failed++
+			if succeeded > 0 || failed > r.GetMaxFailedBackups() || (maxAge > 0 && now.Sub(b.CreationTimestamp.Time) > maxAge) {
+				prune = append(prune, b)
+			}
+		}
+	}
+	return prune
+}
+
+// updateBackupCondition sets the BackupSucceeded condition from the latest backup that is done.
+func (v *Vaults) updateBackupCondition(vr *api.VaultService, backups []api.VaultBackup) error {
+	var latest, lastSucceeded *api.VaultBackup
+	for i := range backups {
+		b := &backups[i]
+		if !isBackupDone(b) {
+			continue
+		}
+		if latest == nil {
+			latest = b
+		}
+		if b.Status.Phase == api.BackupPhaseSucceeded {
+			lastSucceeded = b
+			break
+		}
+	}
+	if latest == nil {
+		return nil
+	}
+
+	msg := "no backup has succeeded yet"
+	if lastSucceeded != nil && lastSucceeded.Status.CompletionTime != nil {
+		msg = fmt.Sprintf("last successful backup (%s) completed at %s", lastSucceeded.Name,
+			lastSucceeded.Status.CompletionTime.UTC().Format(time.RFC3339))
+	}
+	c := api.NewCondition(api.VaultServiceBackupSucceeded, v1.ConditionTrue, "", msg)
+	if latest.Status.Phase == api.BackupPhaseFailed {
+		c = api.NewCondition(api.VaultServiceBackupSucceeded, v1.ConditionFalse, reasonBackupFailed,
+			fmt.Sprintf("backup (%s) failed: %s; %s", latest.Name, latest.Status.Message, msg))
+	}
+	return v.updateVaultCRCondition(vr, c)
+}
+
+func isBackupDone(b *api.VaultBackup) bool {
+	return b.Status.Phase == api.BackupPhaseSucceeded || b.Status.Phase == api.BackupPhaseFailed
+}
@@ -0,0 +1,95 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestBackupsToPrune(t *testing.T) {
+	now := time.Date(2018, 1, 10, 0, 0, 0, 0, time.UTC)
+	// newBackups returns backups of the given phases taken a day apart, the latest first.
+	newBackups := func(phases ...api.BackupPhase) []api.VaultBackup {
+		var backups []api.VaultBackup
+		for i, p := range phases {
+			backups = append(backups, api.VaultBackup{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              string(rune('a' + i)),
+					CreationTimestamp: metav1.Time{Time: now.AddDate(0, 0, -i)},
+				},
+				Status: api.VaultBackupStatus{Phase: p},
+			})
+		}
+		return backups
+	}
+	s, f, r := api.BackupPhaseSucceeded, api.BackupPhaseFailed, api.BackupPhaseRunning
+
+	tests := []struct {
+		name      string
+		backups   []api.VaultBackup
+		retention api.BackupRetention
+		want      []string
+	}{{
+		name:      "max backups",
+		backups:   newBackups(s, s, s),
+		retention: api.BackupRetention{MaxBackups: 2},
+		want:      []string{"c"},
+	}, {
+		name:      "max age keeps the latest successful backup",
+		backups:   newBackups(r, s, s),
+		retention: api.BackupRetention{MaxAge: "1h"},
+		want:      []string{"c"},
+	}, {
+		name:      "failed backups before a successful one",
+		backups:   newBackups(s, f, f),
+		retention: api.BackupRetention{},
+		want:      []string{"b", "c"},
+	}, {
+		name:      "failed backups after the last successful one",
+		backups:   newBackups(f, f, s),
+		retention: api.BackupRetention{},
+	}, {
+		name:      "default max failed backups",
+		backups:   newBackups(f, f, f, f, f),
+		retention: api.BackupRetention{},
+		want:      []string{"d", "e"},
+	}, {
+		name:      "max failed backups",
+		backups:   newBackups(f, f, r, s),
+		retention: api.BackupRetention{MaxFailedBackups: 1},
+		want:      []string{"b"},
+	}, {
+		name:      "max age of failed backups",
+		backups:   newBackups(f, f, f),
+		retention: api.BackupRetention{MaxAge: "36h"},
+		want:      []string{"c"},
+	}}
+
+	for _, tt := range tests {
+		var got []string
+		for _, b := range backupsToPrune(tt.backups, &tt.retention, now) {
+			got = append(got, b.Name)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: pruned %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+	"github.com/coreos/vault-operator/pkg/util/k8sutil"
+	"github.com/coreos/vault-operator/pkg/util/tlsutil"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const reasonCertsExpiring = "CertsExpiring"
+
+var certNotAfter = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Namespace: "vault_operator",
+	Subsystem: "tls",
+	Name:      "cert_not_after_timestamp_seconds",
+	Help:      "Unix time after which a TLS cert used by a vault service is no longer valid.",
+}, []string{"namespace", "vault_service", "secret", "file"})
+
+func init() {
+	prometheus.MustRegister(certNotAfter)
+}
+
+// certFile identifies a cert file in a secret.
+type certFile struct {
+	secret string
+	file   string
+}
+
+// vaultTLSSecretNames returns the names of all secrets containing TLS certs used by the given vault service.
+func vaultTLSSecretNames(vr *api.VaultService) []string {
+	names := []string{
+		vr.Spec.TLS.Static.ServerSecret,
+		vr.Spec.TLS.Static.ClientSecret,
+		api.DefaultVaultCATLSSecretName(vr.Name),
+		k8sutil.EtcdClientTLSSecretName(vr.Name),
+		k8sutil.EtcdServerTLSSecretName(vr.Name),
+		k8sutil.EtcdPeerTLSSecretName(vr.Name),
+	}
+	if api.IsClientAuthEnabled(vr.Spec.TLS) {
+		names = append(names, vr.Spec.TLS.ClientAuth.ClientSecrets...)
+	}
+	return names
+}
+
+// syncCertExpiry exports the expiry time of every cert in the TLS secrets of the vault service,
+// and sets the CertificatesExpiring condition if any of them expires within the cert expiry window.
+// The reported cert files are added to the given set, so that they can be cleaned up by deleteCertExpiryMetrics.
+func (v *Vaults) syncCertExpiry(vr *api.VaultService, reported map[certFile]bool) error {
+	window := vr.Spec.TLS.GetCertExpiryWindow()
+	var expiring []string
+	for _, sn := range vaultTLSSecretNames(vr) {
+		// TODO: use secrets informer
+		se, err := v.kubecli.CoreV1().Secrets(vr.Namespace).Get(sn, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			// The default CA secret does not exist with user provided TLS assets.
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("get secret (%s) failed: %v", sn, err)
+		}
+		for file, data := range se.Data {
+			if !strings.HasSuffix(file, ".crt") {
+				continue
+			}
+			certs, err := tlsutil.ParsePEMEncodedCerts(data)
+			if err != nil {
+				logrus.Warningf("failed to parse %s in secret (%s): %v", file, sn, err)
+				continue
+			}
+			// A file might contain a chain. It breaks as soon as the first cert expires.
+			notAfter := certs[0].NotAfter
+			for _, c := range certs[1:] {
+				if c.NotAfter.Before(notAfter) {
+					notAfter = c.NotAfter
+				}
+			}
+
+			certNotAfter.WithLabelValues(vr.Namespace, vr.Name, sn, file).Set(float64(notAfter.Unix()))
+			reported[certFile{secret: sn, file: file}] = true
+			if time.Until(notAfter) < window {
+				expiring = append(expiring, fmt.Sprintf("%s/%s expires at %s", sn, file, notAfter.UTC().Format(time.RFC3339)))
+			}
+		}
+	}
+
+	c := api.NewCondition(api.VaultServiceCertificatesExpiring, v1.ConditionFalse, "", "")
+	if len(expiring) != 0 {
+		sort.Strings(expiring)
+		c = api.NewCondition(api.VaultServiceCertificatesExpiring, v1.ConditionTrue, reasonCertsExpiring, strings.Join(expiring, "; "))
+	}
+	return v.updateVaultCRCondition(vr, c)
+}
+
+// deleteCertExpiryMetrics removes the exported cert expiry metrics of the given vault service.
+func deleteCertExpiryMetrics(vr *api.VaultService, reported map[certFile]bool) {
+	for cf := range reported {
+		certNotAfter.DeleteLabelValues(vr.Namespace, vr.Name, cf.secret, cf.file)
+	}
+}
@@ -53,6 +53,12 @@ func (v *Vaults) run(ctx context.Context) {
 		return
 	}
 
+	// The controllers of the objects written to vault look up vault services in the cache.
+	go v.newVaultPolicyController().run(ctx)
+	go v.newVaultAuthMethodController().run(ctx)
+	go v.newVaultSecretEngineController().run(ctx)
+	go v.newVaultBackupController().run(ctx)
+
 	probe.SetReady()
 
 	const numWorkers = 1
@@ -98,6 +104,8 @@ func (v *Vaults) onDeleteVault(obj interface{}) {
 		cancel()
 		delete(v.ctxCancels, vr.Name)
 	}
+	v.deleteVaultTLSConfig(vr.Name)
+	v.deleteOperatorCredential(vr.Name)
 
 	// IndexerInformer uses a delta queue, therefore for deletes we have to use this
 	// key function.
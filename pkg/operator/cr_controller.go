@@ -0,0 +1,132 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// crResyncPeriod is how often the custom resources written to vault are synced again,
+// which retries failed syncs and corrects changes made to vault directly.
+const crResyncPeriod = time.Minute
+
+// requeueAfter is returned by the sync of a custom resource that waits for something to finish,
+// e.g. a snapshot job, to have it synced again after the given duration without counting as a failed sync.
+type requeueAfter time.Duration
+
+func (r requeueAfter) Error() string {
+	return fmt.Sprintf("requeue after %v", time.Duration(r))
+}
+
+// crController watches the custom resources of one kind and syncs them one at a time
+// through a work queue, the same way as the vault service controller.
+type crController struct {
+	kind     string
+	indexer  cache.Indexer
+	informer cache.Controller
+	queue    workqueue.RateLimitingInterface
+	// sync syncs the given custom resource. Deleted custom resources are not synced;
+	// the ones that need cleanup carry a finalizer and are synced while being deleted.
+	sync func(obj interface{}) error
+}
+
+func newCRController(kind string, lw cache.ListerWatcher, objType runtime.Object, sync func(obj interface{}) error) *crController {
+	c := &crController{
+		kind:  kind,
+		queue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), kind),
+		sync:  sync,
+	}
+	enqueue := func(obj interface{}) {
+		key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+		if err != nil {
+			panic(err)
+		}
+		c.queue.Add(key)
+	}
+	c.indexer, c.informer = cache.NewIndexerInformer(lw, objType, crResyncPeriod, cache.ResourceEventHandlerFuncs{
+		AddFunc:    enqueue,
+		UpdateFunc: func(oldObj, newObj interface{}) { enqueue(newObj) },
+		DeleteFunc: enqueue,
+	}, cache.Indexers{})
+	return c
+}
+
+func (c *crController) run(ctx context.Context) {
+	defer c.queue.ShutDown()
+
+	logrus.Infof("starting %s controller", c.kind)
+	go c.informer.Run(ctx.Done())
+
+	if !cache.WaitForCacheSync(ctx.Done(), c.informer.HasSynced) {
+		logrus.Errorf("Timed out waiting for %s caches to sync", c.kind)
+		return
+	}
+
+	go wait.Until(c.runWorker, time.Second, ctx.Done())
+
+	<-ctx.Done()
+	logrus.Infof("stopping %s controller", c.kind)
+}
+
+func (c *crController) runWorker() {
+	for c.processNextItem() {
+	}
+}
+
+func (c *crController) processNextItem() bool {
+	key, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	defer c.queue.Done(key)
+
+	obj, exists, err := c.indexer.GetByKey(key.(string))
+	if err == nil && exists {
+		err = c.sync(obj)
+	}
+	c.handleErr(err, key)
+	return true
+}
+
+// handleErr retries the key up to maxRetries times if the sync failed.
+// Keys that are dropped are synced again with the next resync.
+func (c *crController) handleErr(err error, key interface{}) {
+	if err == nil {
+		c.queue.Forget(key)
+		return
+	}
+	if d, ok := err.(requeueAfter); ok {
+		c.queue.Forget(key)
+		c.queue.AddAfter(key, time.Duration(d))
+		return
+	}
+
+	if c.queue.NumRequeues(key) < maxRetries {
+		logrus.Errorf("error syncing %s (%v): %v", c.kind, key, err)
+		c.queue.AddRateLimited(key)
+		return
+	}
+
+	c.queue.Forget(key)
+	logrus.Infof("Dropping %s (%v) out of the queue: %v", c.kind, key, err)
+}
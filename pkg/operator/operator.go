@@ -17,15 +17,20 @@ package operator
 import (
 	"context"
 	"os"
+	"sync"
 
 	"github.com/coreos/vault-operator/pkg/client"
 	"github.com/coreos/vault-operator/pkg/generated/clientset/versioned"
+	"github.com/coreos/vault-operator/pkg/generated/clientset/versioned/scheme"
 	"github.com/coreos/vault-operator/pkg/util/k8sutil"
 
 	etcdCRClientPkg "github.com/coreos/etcd-operator/pkg/client"
 	etcdCRClient "github.com/coreos/etcd-operator/pkg/generated/clientset/versioned"
+	"k8s.io/api/core/v1"
 	"k8s.io/client-go/kubernetes"
+	v1core "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 )
 
@@ -35,6 +40,16 @@ type Vaults struct {
 	// cancel their goroutines when they are deleted
 	ctxCancels map[string]context.CancelFunc
 
+	// tlsConfigs caches the TLS configs for talking to each vault cluster.
+	tlsConfigsMu sync.Mutex
+	tlsConfigs   map[string]*vaultTLSConfig
+
+	// operatorCreds keeps the vault credential of the operator for each vault cluster.
+	operatorCredsMu sync.Mutex
+	operatorCreds   map[string]*operatorCredential
+	// credentialKeyNamespace is the namespace of the key the operator credentials are encrypted with.
+	credentialKeyNamespace string
+
 	// k8s workqueue pattern
 	indexer  cache.Indexer
 	informer cache.Controller
@@ -43,19 +58,40 @@ type Vaults struct {
 	kubecli     kubernetes.Interface
 	vaultsCRCli versioned.Interface
 	etcdCRCli   etcdCRClient.Interface
+
+	// recorder records events on the vault CRs, e.g. when an upgrade is rolled back.
+	recorder record.EventRecorder
 }
 
 // New creates a vault operator.
 func New() *Vaults {
+	kubecli := k8sutil.MustNewKubeClient()
+	namespace := os.Getenv("MY_POD_NAMESPACE")
+	// The credential key is best kept in a namespace of its own, apart from the credential secrets.
+	keyNamespace := os.Getenv("OPERATOR_CREDENTIAL_KEY_NAMESPACE")
+	if len(keyNamespace) == 0 {
+		keyNamespace = namespace
+	}
 	return &Vaults{
-		namespace:   os.Getenv("MY_POD_NAMESPACE"),
-		ctxCancels:  map[string]context.CancelFunc{},
-		kubecli:     k8sutil.MustNewKubeClient(),
-		vaultsCRCli: client.MustNewInCluster(),
-		etcdCRCli:   etcdCRClientPkg.MustNewInCluster(),
+		namespace:              namespace,
+		ctxCancels:             map[string]context.CancelFunc{},
+		tlsConfigs:             map[string]*vaultTLSConfig{},
+		operatorCreds:          map[string]*operatorCredential{},
+		credentialKeyNamespace: keyNamespace,
+		kubecli:                kubecli,
+		vaultsCRCli:            client.MustNewInCluster(),
+		etcdCRCli:              etcdCRClientPkg.MustNewInCluster(),
+		recorder:               newEventRecorder(kubecli),
 	}
 }
 
+// newEventRecorder returns a recorder of events on the vault CRs, in their namespaces.
+func newEventRecorder(kubecli kubernetes.Interface) record.EventRecorder {
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartRecordingToSink(&v1core.EventSinkImpl{Interface: kubecli.CoreV1().Events("")})
+	return eventBroadcaster.NewRecorder(scheme.Scheme, v1.EventSource{Component: "vault-operator"})
+}
+
 // Start starts the vault operator.
 func (v *Vaults) Start(ctx context.Context) error {
 	v.run(ctx)
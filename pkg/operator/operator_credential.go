@@ -0,0 +1,514 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+	"github.com/coreos/vault-operator/pkg/util/k8sutil"
+
+	vaultapi "github.com/hashicorp/vault/api"
+	"github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// operatorIdentityName is the name of the policy, the auth method path and the role of the operator in vault.
+	operatorIdentityName = "vault-operator"
+
+	// operatorCredentialKeySecret is the secret holding the key the operator credentials are encrypted with.
+	// It is shared by all vault services, and kept in the credential key namespace of the operator.
+	operatorCredentialKeySecret = "vault-operator-credential-key"
+	operatorCredentialKeyKey    = "key"
+	operatorCredentialKey       = "credential"
+
+	serviceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+	reasonOperatorCredentialFailed = "CredentialFailed"
+)
+
+// operatorPolicy covers the vault API calls the operator makes with its own identity: registering plugins,
+// stepping down, enabling its audit devices, and rotating its own credential.
+// Policies, auth methods and secret engines are written with the operator token secret instead,
+// since writing them lets a token grant itself any policy.
+const operatorPolicy = `
+path "auth/token/lookup-self" {
+  capabilities = ["read"]
+}
+path "auth/token/renew-self" {
+  capabilities = ["update"]
+}
+path "auth/token/revoke-self" {
+  capabilities = ["update"]
+}
+path "auth/vault-operator/role/vault-operator/secret-id" {
+  capabilities = ["update"]
+}
+path "auth/vault-operator/role/vault-operator/secret-id-accessor/destroy" {
+  capabilities = ["update"]
+}
+path "sys/step-down" {
+  capabilities = ["update", "sudo"]
+}
+path "sys/plugins/catalog/*" {
+  capabilities = ["create", "update", "sudo"]
+}
+path "sys/audit" {
+  capabilities = ["read", "sudo"]
+}
+path "sys/audit/file" {
+  capabilities = ["create", "update", "delete", "sudo"]
+}
+path "sys/audit/socket" {
+  capabilities = ["create", "update", "delete", "sudo"]
+}
+path "sys/audit/syslog" {
+  capabilities = ["create", "update", "delete", "sudo"]
+}
+`
+
+// operatorCredential is the vault identity of the operator for a vault service, and its current token.
+type operatorCredential struct {
+	Method api.OperatorAuthMethod `json:"method"`
+
+	// RoleID and SecretID log the operator in with the approle auth method.
+	RoleID           string `json:"roleID,omitempty"`
+	SecretID         string `json:"secretID,omitempty"`
+	SecretIDAccessor string `json:"secretIDAccessor,omitempty"`
+
+	Token      string        `json:"token"`
+	TTL        time.Duration `json:"ttl"`
+	ExpireTime time.Time     `json:"expireTime"`
+
+	BootstrapRevoked bool `json:"bootstrapRevoked,omitempty"`
+}
+
+// valid returns true if the token has not expired yet.
+func (c *operatorCredential) valid() bool {
+	return time.Now().Before(c.ExpireTime)
+}
+
+// syncOperatorCredential sets up the vault identity of the operator via the active vault node, keeps its token fresh,
+// and records the result as the OperatorCredentialReady condition of the vault CR.
+func (v *Vaults) syncOperatorCredential(vr *api.VaultService, active string, tlsConfig *tls.Config) error {
+	serr := v.refreshOperatorCredential(vr, active, tlsConfig)
+	c := api.NewCondition(api.VaultServiceOperatorCredentialReady, v1.ConditionTrue, "", "")
+	if serr != nil {
+		c = api.NewCondition(api.VaultServiceOperatorCredentialReady, v1.ConditionFalse, reasonOperatorCredentialFailed, serr.Error())
+	}
+	if err := v.updateVaultCRCondition(vr, c); err != nil {
+		return err
+	}
+	return serr
+}
+
+func (v *Vaults) refreshOperatorCredential(vr *api.VaultService, active string, tlsConfig *tls.Config) error {
+	p := vr.Spec.OperatorAuth
+	ttl, err := parseTTL(p.GetTokenTTL())
+	if err != nil {
+		return fmt.Errorf("invalid operator token TTL: %v", err)
+	}
+	if _, err = parseTTL(p.GetTokenMaxTTL()); err != nil {
+		return fmt.Errorf("invalid operator token max TTL: %v", err)
+	}
+
+	vapi, err := v.newVaultPodClient(vr, active, tlsConfig)
+	if err != nil {
+		return err
+	}
+	cred, err := v.getOperatorCredential(vr)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case cred == nil || cred.Method != p.Method:
+		cred, err = v.bootstrapOperatorCredential(vr, vapi)
+	case !cred.valid():
+		cred, err = v.rotateOperatorCredential(vr, vapi, cred)
+	case time.Until(cred.ExpireTime) < time.Duration(ttl)*time.Second/2:
+		cred, err = v.renewOperatorCredential(vr, vapi, cred)
+	default:
+		if !p.RevokeBootstrapToken || cred.BootstrapRevoked {
+			return nil
+		}
+	}
+	if err != nil {
+		return err
+	}
+	if err = v.saveOperatorCredential(vr, cred); err != nil {
+		return err
+	}
+
+	if p.RevokeBootstrapToken && !cred.BootstrapRevoked {
+		token, err := v.getBootstrapToken(vr)
+		if err != nil {
+			return err
+		}
+		vapi.SetToken(token)
+		if err = vapi.Auth().Token().RevokeSelf(""); err != nil {
+			return fmt.Errorf("revoke bootstrap token failed: %v", err)
+		}
+		cred.BootstrapRevoked = true
+		return v.saveOperatorCredential(vr, cred)
+	}
+	return nil
+}
+
+// bootstrapOperatorCredential sets up the policy, auth method and role of the operator with the bootstrap token,
+// and logs the operator in.
+func (v *Vaults) bootstrapOperatorCredential(vr *api.VaultService, vapi *vaultapi.Client) (*operatorCredential, error) {
+	p := vr.Spec.OperatorAuth
+	token, err := v.getBootstrapToken(vr)
+	if err != nil {
+		return nil, fmt.Errorf("set up operator credential: %v", err)
+	}
+	vapi.SetToken(token)
+
+	_, err = vapi.Logical().Write("sys/policies/acl/"+operatorIdentityName, map[string]interface{}{"policy": operatorPolicy})
+	if err != nil {
+		return nil, fmt.Errorf("write operator policy failed: %v", err)
+	}
+
+	mounts, err := vapi.Sys().ListAuth()
+	if err != nil {
+		return nil, fmt.Errorf("list auth methods failed: %v", err)
+	}
+	m, ok := mounts[operatorIdentityName+"/"]
+	if ok && m.Type != string(p.Method) {
+		// The operator switched to another auth method.
+		if err = vapi.Sys().DisableAuth(operatorIdentityName); err != nil {
+			return nil, fmt.Errorf("disable operator auth method failed: %v", err)
+		}
+		ok = false
+	}
+	if !ok {
+		err = vapi.Sys().EnableAuthWithOptions(operatorIdentityName, &vaultapi.EnableAuthOptions{
+			Type:        string(p.Method),
+			Description: "Auth method of the vault operator",
+		})
+		if err != nil {
+			return nil, fmt.Errorf("enable operator auth method failed: %v", err)
+		}
+	}
+
+	role := map[string]interface{}{
+		"policies": append([]string{operatorIdentityName}, p.Policies...),
+	}
+	rolePath := fmt.Sprintf("auth/%s/role/%s", operatorIdentityName, operatorIdentityName)
+	cred := &operatorCredential{Method: p.Method}
+	switch p.Method {
+	case api.OperatorAuthAppRole:
+		role["token_ttl"] = p.GetTokenTTL()
+		role["token_max_ttl"] = p.GetTokenMaxTTL()
+		if _, err = vapi.Logical().Write(rolePath, role); err != nil {
+			return nil, fmt.Errorf("write operator role failed: %v", err)
+		}
+		s, err := vapi.Logical().Read(rolePath + "/role-id")
+		if err != nil || s == nil {
+			return nil, fmt.Errorf("read operator role ID failed: %v", err)
+		}
+		cred.RoleID, _ = s.Data["role_id"].(string)
+		if err = generateOperatorSecretID(vapi, cred); err != nil {
+			return nil, err
+		}
+	case api.OperatorAuthKubernetes:
+		reviewer, ca, err := v.getTokenReviewerCredential(vr, "")
+		if err != nil {
+			return nil, err
+		}
+		_, err = vapi.Logical().Write(fmt.Sprintf("auth/%s/config", operatorIdentityName), map[string]interface{}{
+			"kubernetes_host":    defaultKubernetesHost,
+			"kubernetes_ca_cert": ca,
+			"token_reviewer_jwt": reviewer,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("write operator auth method config failed: %v", err)
+		}
+		sa, err := v.getOperatorServiceAccount()
+		if err != nil {
+			return nil, err
+		}
+		role["bound_service_account_names"] = []string{sa}
+		role["bound_service_account_namespaces"] = []string{v.namespace}
+		role["ttl"] = p.GetTokenTTL()
+		role["max_ttl"] = p.GetTokenMaxTTL()
+		if _, err = vapi.Logical().Write(rolePath, role); err != nil {
+			return nil, fmt.Errorf("write operator role failed: %v", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported operator auth method (%s)", p.Method)
+	}
+
+	if err = v.loginOperator(vapi, cred); err != nil {
+		return nil, err
+	}
+	logrus.Infof("set up %s credential of the operator for the vault service (%s)", p.Method, vr.Name)
+	return cred, nil
+}
+
+// renewOperatorCredential renews the operator token, or logs in again if it cannot be renewed for a full TTL.
+func (v *Vaults) renewOperatorCredential(vr *api.VaultService, vapi *vaultapi.Client, cred *operatorCredential) (*operatorCredential, error) {
+	vapi.SetToken(cred.Token)
+	s, err := vapi.Auth().Token().RenewSelf(0)
+	if err == nil && s != nil && s.Auth != nil && time.Duration(s.Auth.LeaseDuration)*time.Second >= cred.TTL {
+		cred.ExpireTime = time.Now().Add(time.Duration(s.Auth.LeaseDuration) * time.Second)
+		return cred, nil
+	}
+	// The token has reached its max TTL.
+	return v.rotateOperatorCredential(vr, vapi, cred)
+}
+
+// rotateOperatorCredential logs the operator in again, replaces the approle secret ID, and revokes the old token.
+func (v *Vaults) rotateOperatorCredential(vr *api.VaultService, vapi *vaultapi.Client, cred *operatorCredential) (*operatorCredential, error) {
+	old := *cred
+	next := *cred
+	if err := v.loginOperator(vapi, &next); err != nil {
+		return nil, err
+	}
+	if next.Method == api.OperatorAuthAppRole {
+		vapi.SetToken(next.Token)
+		if err := generateOperatorSecretID(vapi, &next); err != nil {
+			return nil, err
+		}
+		_, err := vapi.Logical().Write(fmt.Sprintf("auth/%s/role/%s/secret-id-accessor/destroy", operatorIdentityName, operatorIdentityName),
+			map[string]interface{}{"secret_id_accessor": old.SecretIDAccessor})
+		if err != nil {
+			logrus.Warningf("failed to destroy the old secret ID of the operator for the vault service (%s): %v", vr.Name, err)
+		}
+	}
+	if old.valid() {
+		vapi.SetToken(old.Token)
+		if err := vapi.Auth().Token().RevokeSelf(""); err != nil {
+			logrus.Warningf("failed to revoke the old token of the operator for the vault service (%s): %v", vr.Name, err)
+		}
+	}
+	return &next, nil
+}
+
+func generateOperatorSecretID(vapi *vaultapi.Client, cred *operatorCredential) error {
+	s, err := vapi.Logical().Write(fmt.Sprintf("auth/%s/role/%s/secret-id", operatorIdentityName, operatorIdentityName), nil)
+	if err != nil || s == nil {
+		return fmt.Errorf("generate operator secret ID failed: %v", err)
+	}
+	cred.SecretID, _ = s.Data["secret_id"].(string)
+	cred.SecretIDAccessor, _ = s.Data["secret_id_accessor"].(string)
+	return nil
+}
+
+// loginOperator logs the operator in with its credential, and sets the new token in the credential.
+func (v *Vaults) loginOperator(vapi *vaultapi.Client, cred *operatorCredential) error {
+	data := map[string]interface{}{}
+	switch cred.Method {
+	case api.OperatorAuthAppRole:
+		data["role_id"] = cred.RoleID
+		data["secret_id"] = cred.SecretID
+	case api.OperatorAuthKubernetes:
+		jwt, err := ioutil.ReadFile(serviceAccountTokenPath)
+		if err != nil {
+			return fmt.Errorf("read operator service account token failed: %v", err)
+		}
+		data["role"] = operatorIdentityName
+		data["jwt"] = string(jwt)
+	}
+	vapi.ClearToken()
+	s, err := vapi.Logical().Write(fmt.Sprintf("auth/%s/login", operatorIdentityName), data)
+	if err != nil {
+		return fmt.Errorf("operator login failed: %v", err)
+	}
+	if s == nil || s.Auth == nil {
+		return errors.New("operator login failed: no token is returned")
+	}
+	cred.Token = s.Auth.ClientToken
+	cred.TTL = time.Duration(s.Auth.LeaseDuration) * time.Second
+	cred.ExpireTime = time.Now().Add(cred.TTL)
+	return nil
+}
+
+// getOperatorServiceAccount returns the name of the service account the operator runs as.
+func (v *Vaults) getOperatorServiceAccount() (string, error) {
+	name := os.Getenv("MY_POD_NAME")
+	// TODO: use pods informer
+	pod, err := v.kubecli.CoreV1().Pods(v.namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get operator pod (%s): %v", name, err)
+	}
+	return pod.Spec.ServiceAccountName, nil
+}
+
+// getOperatorCredential returns the operator credential of the vault service from memory,
+// or from the credential secret after a restart. It returns nil if there is none yet.
+func (v *Vaults) getOperatorCredential(vr *api.VaultService) (*operatorCredential, error) {
+	v.operatorCredsMu.Lock()
+	cred, ok := v.operatorCreds[vr.Name]
+	v.operatorCredsMu.Unlock()
+	if ok {
+		c := *cred
+		return &c, nil
+	}
+
+	secretName := api.OperatorCredentialSecretName(vr.Name)
+	secret, err := v.kubecli.CoreV1().Secrets(vr.Namespace).Get(secretName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get operator credential secret (%s): %v", secretName, err)
+	}
+	key, err := v.getOperatorCredentialKey()
+	if err != nil {
+		return nil, err
+	}
+	data, err := decrypt(key, secret.Data[operatorCredentialKey], credentialAdditionalData(vr))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt operator credential secret (%s): %v", secretName, err)
+	}
+	cred = &operatorCredential{}
+	if err = json.Unmarshal(data, cred); err != nil {
+		return nil, fmt.Errorf("failed to decode operator credential secret (%s): %v", secretName, err)
+	}
+	v.operatorCredsMu.Lock()
+	v.operatorCreds[vr.Name] = cred
+	v.operatorCredsMu.Unlock()
+	c := *cred
+	return &c, nil
+}
+
+// saveOperatorCredential keeps the operator credential of the vault service in memory,
+// and encrypted in the credential secret so that it survives restarts of the operator.
+func (v *Vaults) saveOperatorCredential(vr *api.VaultService, cred *operatorCredential) error {
+	key, err := v.getOperatorCredentialKey()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(cred)
+	if err != nil {
+		return err
+	}
+	data, err = encrypt(key, data, credentialAdditionalData(vr))
+	if err != nil {
+		return err
+	}
+
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   api.OperatorCredentialSecretName(vr.Name),
+			Labels: k8sutil.LabelsForVault(vr.Name),
+		},
+		Data: map[string][]byte{operatorCredentialKey: data},
+	}
+	k8sutil.AddOwnerRefToObject(secret, k8sutil.AsOwner(vr))
+	_, err = v.kubecli.CoreV1().Secrets(vr.Namespace).Update(secret)
+	if apierrors.IsNotFound(err) {
+		_, err = v.kubecli.CoreV1().Secrets(vr.Namespace).Create(secret)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save operator credential secret (%s): %v", secret.Name, err)
+	}
+
+	c := *cred
+	v.operatorCredsMu.Lock()
+	v.operatorCreds[vr.Name] = &c
+	v.operatorCredsMu.Unlock()
+	return nil
+}
+
+// deleteOperatorCredential drops the in-memory operator credential of the given vault cluster.
+func (v *Vaults) deleteOperatorCredential(name string) {
+	v.operatorCredsMu.Lock()
+	delete(v.operatorCreds, name)
+	v.operatorCredsMu.Unlock()
+}
+
+// getOperatorCredentialKey returns the key the operator credentials are encrypted with,
+// generating it on first use.
+func (v *Vaults) getOperatorCredentialKey() ([]byte, error) {
+	secrets := v.kubecli.CoreV1().Secrets(v.credentialKeyNamespace)
+	secret, err := secrets.Get(operatorCredentialKeySecret, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		key := make([]byte, 32)
+		if _, err = rand.Read(key); err != nil {
+			return nil, err
+		}
+		secret, err = secrets.Create(&v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: operatorCredentialKeySecret},
+			Data:       map[string][]byte{operatorCredentialKeyKey: key},
+		})
+		if apierrors.IsAlreadyExists(err) {
+			secret, err = secrets.Get(operatorCredentialKeySecret, metav1.GetOptions{})
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get operator credential key secret (%s/%s): %v", v.credentialKeyNamespace, operatorCredentialKeySecret, err)
+	}
+	key := secret.Data[operatorCredentialKeyKey]
+	if len(key) != 32 {
+		return nil, fmt.Errorf("operator credential key secret (%s/%s) has no 32 byte %s", v.credentialKeyNamespace, operatorCredentialKeySecret, operatorCredentialKeyKey)
+	}
+	return key, nil
+}
+
+// credentialAdditionalData binds the encrypted credential to the secret of the given vault service,
+// so that it cannot be moved to the credential secret of another vault service.
+func credentialAdditionalData(vr *api.VaultService) []byte {
+	return []byte(vr.Namespace + "/" + api.OperatorCredentialSecretName(vr.Name))
+}
+
+// encrypt encrypts the data with AES-GCM, authenticating the additional data with it,
+// and prepends the nonce to the result.
+func encrypt(key, data, ad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return gcm.Seal(nonce, nonce, data, ad), nil
+}
+
+// decrypt decrypts the data encrypted by encrypt.
+func decrypt(key, data, ad []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("data is too short")
+	}
+	n := gcm.NonceSize()
+	return gcm.Open(nil, data[:n], data[n:], ad)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
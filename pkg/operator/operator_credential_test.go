@@ -0,0 +1,159 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+
+	"github.com/hashicorp/hcl"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSaveOperatorCredential(t *testing.T) {
+	vr := newTestVaultService()
+	kubecli := fake.NewSimpleClientset()
+	newVaults := func() *Vaults {
+		return &Vaults{
+			kubecli:                kubecli,
+			operatorCreds:          map[string]*operatorCredential{},
+			credentialKeyNamespace: "vault-operator",
+		}
+	}
+	cred := &operatorCredential{
+		Method:     api.OperatorAuthAppRole,
+		RoleID:     "role-id",
+		SecretID:   "secret-id",
+		Token:      "operator-token",
+		TTL:        time.Hour,
+		ExpireTime: time.Now().Add(time.Hour).Round(0),
+	}
+	if err := newVaults().saveOperatorCredential(vr, cred); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := kubecli.CoreV1().Secrets("vault-operator").Get(operatorCredentialKeySecret, metav1.GetOptions{}); err != nil {
+		t.Errorf("failed to get the key secret from the credential key namespace: %v", err)
+	}
+	if _, err := kubecli.CoreV1().Secrets(vr.Namespace).Get(operatorCredentialKeySecret, metav1.GetOptions{}); err == nil {
+		t.Errorf("key secret is kept next to the credential secret")
+	}
+	secret, err := kubecli.CoreV1().Secrets(vr.Namespace).Get(api.OperatorCredentialSecretName(vr.Name), metav1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{cred.Token, cred.SecretID} {
+		if bytes.Contains(secret.Data[operatorCredentialKey], []byte(s)) {
+			t.Errorf("credential secret contains %q in plaintext", s)
+		}
+	}
+
+	// A restarted operator reads the credential back from the secret.
+	got, err := newVaults().getOperatorCredential(vr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == nil {
+		t.Fatal("credential is not found")
+	}
+	if !got.ExpireTime.Equal(cred.ExpireTime) {
+		t.Errorf("expire time = %v, want %v", got.ExpireTime, cred.ExpireTime)
+	}
+	got.ExpireTime = cred.ExpireTime
+	if *got != *cred {
+		t.Errorf("credential = %+v, want %+v", got, cred)
+	}
+
+	// The credential cannot be moved to the secret of another vault service.
+	other := newTestVaultService()
+	other.Name = "other"
+	secret.Name = api.OperatorCredentialSecretName(other.Name)
+	secret.ResourceVersion = ""
+	if _, err = kubecli.CoreV1().Secrets(other.Namespace).Create(secret); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = newVaults().getOperatorCredential(other); err == nil {
+		t.Errorf("expect error when decrypting a credential moved from another vault service")
+	}
+}
+
+// policyAllows returns true if the vault policy grants the capability on the path.
+// Like vault, an exact path takes precedence over globs, and the longest glob prefix wins.
+func policyAllows(t *testing.T, policy, path, capability string) bool {
+	var p struct {
+		Path map[string]struct {
+			Capabilities []string
+		}
+	}
+	if err := hcl.Decode(&p, policy); err != nil {
+		t.Fatalf("failed to parse policy: %v", err)
+	}
+	caps, ok := p.Path[path]
+	if !ok {
+		prefix := ""
+		for pattern, c := range p.Path {
+			glob := strings.TrimSuffix(pattern, "*")
+			if glob != pattern && strings.HasPrefix(path, glob) && len(glob) >= len(prefix) {
+				prefix, caps, ok = glob, c, true
+			}
+		}
+	}
+	if !ok {
+		return false
+	}
+	allowed := false
+	for _, c := range caps.Capabilities {
+		if c == "deny" {
+			return false
+		}
+		allowed = allowed || c == capability
+	}
+	return allowed
+}
+
+func TestOperatorPolicy(t *testing.T) {
+	tests := []struct {
+		path       string
+		capability string
+		want       bool
+	}{
+		{"auth/token/renew-self", "update", true},
+		{"auth/vault-operator/role/vault-operator/secret-id", "update", true},
+		{"sys/plugins/catalog/example", "create", true},
+		{"sys/audit/file", "sudo", true},
+		// Writing any of these lets the operator grant itself any policy.
+		{"sys/policies/acl/admin", "create", false},
+		{"sys/policies/acl/admin", "update", false},
+		{"sys/policies/acl/vault-operator", "update", false},
+		{"sys/auth/userpass", "sudo", false},
+		{"auth/userpass/users/admin", "create", false},
+		{"auth/vault-operator/role/vault-operator", "update", false},
+		{"auth/approle/role/admin", "create", false},
+		{"auth/token/create", "update", false},
+		{"auth/token/create-orphan", "update", false},
+		{"sys/mounts/secret", "create", false},
+		{"sys/audit/evil", "sudo", false},
+	}
+	for _, tt := range tests {
+		if got := policyAllows(t, operatorPolicy, tt.path, tt.capability); got != tt.want {
+			t.Errorf("%s %s: allowed = %v, want %v", tt.capability, tt.path, got, tt.want)
+		}
+	}
+}
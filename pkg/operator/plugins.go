@@ -0,0 +1,74 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"crypto/tls"
+	"fmt"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+
+	"k8s.io/api/core/v1"
+)
+
+const reasonPluginRegistrationFailed = "RegistrationFailed"
+
+// syncPlugins registers the plugins of the vault service in vault's plugin catalog via the active vault node,
+// and records the result as the PluginsRegistered condition of the vault CR.
+// Plugins are only registered once per operator process; the registered ones are tracked in the given set.
+func (v *Vaults) syncPlugins(vr *api.VaultService, active string, tlsConfig *tls.Config, registered map[string]bool) error {
+	var pending []api.Plugin
+	for _, p := range vr.Spec.Plugins {
+		if !registered[p.Name] {
+			pending = append(pending, p)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	rerr := v.registerPlugins(vr, active, tlsConfig, pending, registered)
+	c := api.NewCondition(api.VaultServicePluginsRegistered, v1.ConditionTrue, "", "")
+	if rerr != nil {
+		c = api.NewCondition(api.VaultServicePluginsRegistered, v1.ConditionFalse, reasonPluginRegistrationFailed, rerr.Error())
+	}
+	if err := v.updateVaultCRCondition(vr, c); err != nil {
+		return err
+	}
+	return rerr
+}
+
+func (v *Vaults) registerPlugins(vr *api.VaultService, active string, tlsConfig *tls.Config, plugins []api.Plugin, registered map[string]bool) error {
+	vapi, err := v.newVaultClient(vr, active, tlsConfig)
+	if err != nil {
+		return err
+	}
+	for _, p := range plugins {
+		// The plugin binaries are named after the plugins in the plugin directory.
+		data := map[string]interface{}{
+			"sha_256": p.SHA256,
+			"command": p.Name,
+		}
+		if len(p.Args) != 0 {
+			data["args"] = p.Args
+		}
+		_, err = vapi.Logical().Write("sys/plugins/catalog/"+p.Name, data)
+		if err != nil {
+			return fmt.Errorf("register plugin (%s) failed: %v", p.Name, err)
+		}
+		registered[p.Name] = true
+	}
+	return nil
+}
@@ -108,12 +108,34 @@ func (v *Vaults) syncVault(key string) (err error) {
 	}
 
 	vr := obj.(*api.VaultService).DeepCopy()
+	cli := v.vaultsCRCli.VaultV1alpha1().VaultServices(vr.Namespace)
+
+	// The token review binding is cluster scoped, and cannot be garbage collected with the vault CR.
+	if vr.DeletionTimestamp != nil {
+		if !api.HasFinalizer(vr.Finalizers) {
+			return nil
+		}
+		err = k8sutil.DeleteVaultTokenReviewBinding(v.kubecli, vr)
+		if err != nil {
+			return err
+		}
+		vr.Finalizers = api.RemoveFinalizer(vr.Finalizers)
+		_, err = cli.Update(vr)
+		return err
+	}
+	if vr.Spec.KubernetesAuth && !api.HasFinalizer(vr.Finalizers) {
+		vr.Finalizers = append(vr.Finalizers, api.VaultFinalizer)
+		vr, err = cli.Update(vr)
+		if err != nil {
+			return err
+		}
+	}
 
 	// Simulate initializer.
 	// TODO: remove this when we have initializers for Vault CR.
 	changed := vr.SetDefaults()
 	if changed {
-		vr, err = v.vaultsCRCli.VaultV1alpha1().VaultServices(vr.Namespace).Update(vr)
+		vr, err = cli.Update(vr)
 		if err != nil {
 			return err
 		}
@@ -126,6 +148,43 @@ func (v *Vaults) syncVault(key string) (err error) {
 // by preparing the TLS secrets, deploying the etcd and vault cluster,
 // and finally updating the vault deployment if needed.
 func (v *Vaults) reconcileVault(vr *api.VaultService) (err error) {
+	err = k8sutil.ValidatePodPolicy(vr.Spec.Pod)
+	if err != nil {
+		return fmt.Errorf("invalid pod policy: %v", err)
+	}
+	err = k8sutil.ValidatePlugins(vr.Spec.Plugins)
+	if err != nil {
+		return fmt.Errorf("invalid plugins: %v", err)
+	}
+	err = k8sutil.ValidateServicePolicy(vr.Spec.Service)
+	if err != nil {
+		return fmt.Errorf("invalid service policy: %v", err)
+	}
+	err = k8sutil.ValidateAudit(vr.Spec.Audit, vr.Spec.Pod)
+	if err != nil {
+		return fmt.Errorf("invalid audit policy: %v", err)
+	}
+	err = k8sutil.ValidateBackupPolicy(vr.Spec.Backup)
+	if err != nil {
+		return fmt.Errorf("invalid backup policy: %v", err)
+	}
+	err = k8sutil.ValidateRestoreSource(vr.Spec.RestoreFrom)
+	if err != nil {
+		return fmt.Errorf("invalid restore source: %v", err)
+	}
+	err = k8sutil.ValidateUpgradePolicy(vr.Spec.Upgrade)
+	if err != nil {
+		return fmt.Errorf("invalid upgrade policy: %v", err)
+	}
+	err = v.validateClientAuthUnchanged(vr)
+	if err != nil {
+		return fmt.Errorf("invalid TLS policy: %v", err)
+	}
+	err = v.validatePodSpecUnchanged(vr)
+	if err != nil {
+		return err
+	}
+
 	// After first time reconcile, phase will switch to "Running".
 	if vr.Status.Phase == api.ClusterPhaseInitial {
 		err = v.prepareEtcdTLSSecrets(vr)
@@ -137,6 +196,13 @@ func (v *Vaults) reconcileVault(vr *api.VaultService) (err error) {
 		if err != nil {
 			return err
 		}
+		// The etcd cluster is restored before vault is deployed, and replaced by the restored one.
+		if vr.Spec.RestoreFrom != nil {
+			err = v.restoreVault(vr)
+			if err != nil {
+				return err
+			}
+		}
 	}
 
 	err = v.prepareDefaultVaultTLSSecrets(vr)
@@ -144,6 +210,17 @@ func (v *Vaults) reconcileVault(vr *api.VaultService) (err error) {
 		return err
 	}
 
+	err = v.prepareVaultClientTLSSecrets(vr)
+	if err != nil {
+		return err
+	}
+
+	// Invalid TLS secrets would only show up as crash looping vault pods.
+	err = v.syncVaultTLSCondition(vr)
+	if err != nil {
+		return err
+	}
+
 	err = v.prepareVaultConfig(vr)
 	if err != nil {
 		return err
@@ -158,6 +235,27 @@ func (v *Vaults) reconcileVault(vr *api.VaultService) (err error) {
 		return err
 	}
 
+	// Keep node drains from evicting too many vault or etcd pods at once.
+	err = k8sutil.SyncVaultPDB(v.kubecli, vr)
+	if err != nil {
+		return err
+	}
+	err = k8sutil.SyncEtcdPDB(v.kubecli, vr)
+	if err != nil {
+		return err
+	}
+
+	err = k8sutil.SyncVaultTokenReviewBinding(v.kubecli, vr)
+	if err != nil {
+		return err
+	}
+
+	// Restrict the traffic to vault and etcd pods if the vault CR asks for it.
+	err = k8sutil.SyncNetworkPolicies(v.kubecli, vr)
+	if err != nil {
+		return err
+	}
+
 	// TODO: make use of deployment informer
 	d, err := v.kubecli.AppsV1beta1().Deployments(vr.Namespace).Get(vr.Name, metav1.GetOptions{})
 	if err != nil {
@@ -186,6 +284,31 @@ func (v *Vaults) reconcileVault(vr *api.VaultService) (err error) {
 	return nil
 }
 
+// validatePodSpecUnchanged rejects updating the pod policy or the plugins of a deployed vault service,
+// which are recorded on the vault deployment. Deployments created before they were recorded get them recorded now.
+func (v *Vaults) validatePodSpecUnchanged(vr *api.VaultService) error {
+	// TODO: make use of deployment informer
+	d, err := v.kubecli.AppsV1beta1().Deployments(vr.Namespace).Get(vr.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	err = k8sutil.ValidateImmutableVaultSpec(d, vr)
+	if err != nil {
+		return err
+	}
+	if !k8sutil.RecordImmutableVaultSpec(d, vr) {
+		return nil
+	}
+	_, err = v.kubecli.AppsV1beta1().Deployments(vr.Namespace).Update(d)
+	if err != nil {
+		return fmt.Errorf("failed to record the pod spec on deployment (%s): %v", d.Name, err)
+	}
+	return nil
+}
+
 // prepareVaultConfig applies our section into Vault config file.
 // - If given user configmap, appends into user provided vault config
 //   and creates another configmap "${configMapName}-copy" for it.
@@ -201,8 +324,15 @@ func (v *Vaults) prepareVaultConfig(vr *api.VaultService) error {
 		}
 		cfgData = cm.Data[filepath.Base(k8sutil.VaultConfigPath)]
 	}
-	cfgData = vaultutil.NewConfigWithDefaultParams(cfgData)
+	var clientCAFile string
+	if api.IsClientAuthEnabled(vr.Spec.TLS) {
+		clientCAFile = filepath.Join(vaultutil.VaultTLSAssetDir, api.CATLSCertName)
+	}
+	cfgData = vaultutil.NewConfigWithDefaultParams(cfgData, clientCAFile)
 	cfgData = vaultutil.NewConfigWithEtcd(cfgData, k8sutil.EtcdURLForVault(vr.Name))
+	if len(vr.Spec.Plugins) != 0 {
+		cfgData = vaultutil.NewConfigWithPluginDir(cfgData, vaultutil.VaultPluginDir)
+	}
 
 	cm := &v1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
@@ -233,6 +363,34 @@ func (v *Vaults) syncUpgrade(vr *api.VaultService, d *appsv1beta1.Deployment) (e
 	// If the deployment version hasn't been updated, roll forward the deployment version
 	// but keep the existing active Vault node alive though.
 	if !k8sutil.IsVaultVersionMatch(d.Spec.Template.Spec, vr.Spec) {
+		image := k8sutil.VaultImage(vr.Spec)
+		u := vr.Status.Upgrade
+		rolling := u != nil && u.Image == image && u.Phase == api.UpgradePhaseRolling
+		switch {
+		case u != nil && u.Image == image && u.Phase == api.UpgradePhaseRolledBack:
+			// A rolled back upgrade is only retried once the version changes.
+			return nil
+		case vr.Spec.Upgrade.IsCanary() && !rolling:
+			// With the canary strategy, the deployment is only rolled forward once the canary passed.
+			return nil
+		case !rolling:
+			// The previous image is recorded, so that a failed upgrade can be rolled back to it.
+			now := metav1.Now()
+			u = &api.UpgradeStatus{
+				Phase:         api.UpgradePhaseRolling,
+				Image:         image,
+				PreviousImage: d.Spec.Template.Spec.Containers[0].Image,
+				StartTime:     &now,
+			}
+			err = v.updateVaultCRCondition(vr, newProgressingCondition(u))
+			if err != nil {
+				return err
+			}
+			err = v.updateUpgradeStatus(vr, u)
+			if err != nil {
+				return err
+			}
+		}
 		err = k8sutil.UpgradeDeployment(v.kubecli, vr, d)
 		if err != nil {
 			return err
@@ -244,17 +402,24 @@ func (v *Vaults) syncUpgrade(vr *api.VaultService, d *appsv1beta1.Deployment) (e
 	// It maps to the following conditions on Status:
 	// 1. check standby == updated
 	// 2. check Available - Updated == Active
+	// The canary pod is left out, as it is deleted once the deployment rolls forward.
 	readyToTriggerStepdown := func() bool {
 		if len(vr.Status.VaultStatus.Active) == 0 {
 			return false
 		}
 
-		if !reflect.DeepEqual(vr.Status.VaultStatus.Standby, vr.Status.UpdatedNodes) {
+		var canary string
+		if vr.Status.Upgrade != nil {
+			canary = vr.Status.Upgrade.Canary
+		}
+		standby := withoutPod(vr.Status.VaultStatus.Standby, canary)
+		updated := withoutPod(vr.Status.UpdatedNodes, canary)
+		if !reflect.DeepEqual(standby, updated) {
 			return false
 		}
 
-		ava := append(vr.Status.VaultStatus.Standby, vr.Status.VaultStatus.Sealed...)
-		if !reflect.DeepEqual(ava, vr.Status.UpdatedNodes) {
+		ava := append(standby, withoutPod(vr.Status.VaultStatus.Sealed, canary)...)
+		if !reflect.DeepEqual(ava, updated) {
 			return false
 		}
 		return true
@@ -264,6 +429,8 @@ func (v *Vaults) syncUpgrade(vr *api.VaultService, d *appsv1beta1.Deployment) (e
 		// This will send SIGTERM to the active Vault pod. It should release HA lock and exit properly.
 		// If it failed for some reason, kubelet will send SIGKILL after default grace period (30s) eventually.
 		// It take longer but the the lock will get released eventually on failure case.
+		// The pod is deleted rather than evicted, since the vault PodDisruptionBudget protects the active node.
+		// The updated standby nodes are unsealed at this point, so this never takes down the last unsealed node.
 		err = v.kubecli.CoreV1().Pods(vr.Namespace).Delete(vr.Status.VaultStatus.Active, nil)
 		if err != nil && !apierrors.IsNotFound(err) {
 			return fmt.Errorf("step down: failed to delete active Vault pod (%s): %v", vr.Status.VaultStatus.Active, err)
@@ -17,6 +17,7 @@ package operator
 import (
 	"crypto/rsa"
 	"crypto/x509"
+	"errors"
 	"fmt"
 
 	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
@@ -24,6 +25,7 @@ import (
 	"github.com/coreos/vault-operator/pkg/util/tlsutil"
 	"github.com/coreos/vault-operator/pkg/util/vaultutil"
 
+	"github.com/sirupsen/logrus"
 	"k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -32,6 +34,20 @@ import (
 var (
 	orgForTLSCert        = []string{"coreos.com"}
 	defaultClusterDomain = "cluster.local"
+
+	serverAuthUsages = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	clientAuthUsages = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	// etcd members dial each other with the peer cert, and etcd's grpc gateway dials
+	// the local member with the server cert. Both must also be valid for client auth.
+	etcdMemberAuthUsages = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+)
+
+const (
+	// Names of the CA cert and key files in the default vault CA secret
+	vaultCACertName = "vault-ca.crt"
+	vaultCAKeyName  = "vault-ca.key"
+
+	operatorClientCommonName = "vault operator"
 )
 
 // prepareDefaultVaultTLSSecrets creates the default secrets for the vault server's TLS assets.
@@ -47,9 +63,29 @@ func (v *Vaults) prepareDefaultVaultTLSSecrets(vr *api.VaultService) (err error)
 	// TODO: we won't need IsTLSConfigured() check once we have initializers.
 	if api.IsTLSConfigured(vr.Spec.TLS) {
 		// TODO: use secrets informer
-		_, err = v.kubecli.CoreV1().Secrets(vr.Namespace).Get(vr.Spec.TLS.Static.ServerSecret, metav1.GetOptions{})
+		var ss *v1.Secret
+		ss, err = v.kubecli.CoreV1().Secrets(vr.Namespace).Get(vr.Spec.TLS.Static.ServerSecret, metav1.GetOptions{})
 		if err == nil {
-			return nil
+			var missing []string
+			missing, err = missingDefaultVaultServerTLSNames(vr, ss)
+			if err != nil {
+				return err
+			}
+			if len(missing) != 0 || api.IsClientAuthEnabled(vr.Spec.TLS) {
+				err = v.backfillDefaultVaultCA(vr)
+				if err != nil {
+					return err
+				}
+			}
+			if len(missing) != 0 {
+				err = v.reissueDefaultVaultServerCert(vr, ss, missing)
+				if err != nil {
+					return err
+				}
+			}
+			// Vault only loads the server cert when it starts. The pod template records the cert the vault pods
+			// were restarted for, so that a restart that failed after the cert was reissued is retried.
+			return k8sutil.RestartVaultPodsForServerCert(v.kubecli, vr, ss.Data[vaultutil.ServerTLSCertName], len(missing) != 0)
 		}
 		if !apierrors.IsNotFound(err) {
 			return err
@@ -78,9 +114,215 @@ func (v *Vaults) prepareDefaultVaultTLSSecrets(vr *api.VaultService) (err error)
 	if err != nil && !apierrors.IsAlreadyExists(err) {
 		return err
 	}
+
+	// The CA key is kept so that client certificates can be issued later on.
+	se = newVaultCATLSSecret(vr, caKey, caCrt)
+	k8sutil.AddOwnerRefToObject(se, k8sutil.AsOwner(vr))
+	_, err = v.kubecli.CoreV1().Secrets(vr.Namespace).Create(se)
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// validateClientAuthUnchanged rejects enabling or disabling client authentication on a deployed vault service,
+// since the vault config and the probes of the vault pods are only set up for it when the vault service is created.
+func (v *Vaults) validateClientAuthUnchanged(vr *api.VaultService) error {
+	// TODO: make use of deployment informer
+	d, err := v.kubecli.AppsV1beta1().Deployments(vr.Namespace).Get(vr.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if k8sutil.IsVaultClientAuthConfigured(d.Spec.Template.Spec) != api.IsClientAuthEnabled(vr.Spec.TLS) {
+		return errors.New("clientAuth cannot be updated once the vault service is created")
+	}
+	return nil
+}
+
+// backfillDefaultVaultCA creates the default vault CA secret for vault services whose default TLS assets
+// were generated by an operator that did not keep the CA, so that client certs and server certs can be issued for them.
+// The key of the original CA is lost, so a new CA is generated and added to the CA bundle in the client secret.
+// The existing server cert, which is signed by the original CA, stays valid.
+func (v *Vaults) backfillDefaultVaultCA(vr *api.VaultService) error {
+	if vr.Spec.TLS.Static.ServerSecret != api.DefaultVaultServerTLSSecretName(vr.Name) {
+		return nil
+	}
+	name := api.DefaultVaultCATLSSecretName(vr.Name)
+	_, err := v.kubecli.CoreV1().Secrets(vr.Namespace).Get(name, metav1.GetOptions{})
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	clientSecretName := vr.Spec.TLS.Static.ClientSecret
+	cs, err := v.kubecli.CoreV1().Secrets(vr.Namespace).Get(clientSecretName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("get secret (%s) failed: %v", clientSecretName, err)
+	}
+
+	caKey, caCrt, err := newCACert()
+	if err != nil {
+		return err
+	}
+	se := newVaultCATLSSecret(vr, caKey, caCrt)
+	k8sutil.AddOwnerRefToObject(se, k8sutil.AsOwner(vr))
+	_, err = v.kubecli.CoreV1().Secrets(vr.Namespace).Create(se)
+	if err != nil {
+		return fmt.Errorf("create secret (%s) failed: %v", name, err)
+	}
+
+	if cs.Data == nil {
+		cs.Data = map[string][]byte{}
+	}
+	cs.Data[api.CATLSCertName] = append(cs.Data[api.CATLSCertName], tlsutil.EncodeCertificatePEM(caCrt)...)
+	_, err = v.kubecli.CoreV1().Secrets(vr.Namespace).Update(cs)
+	if err != nil {
+		return fmt.Errorf("update secret (%s) failed: %v", clientSecretName, err)
+	}
 	return nil
 }
 
+// missingDefaultVaultServerTLSNames returns the names the default vault server cert in the given secret is not valid for,
+// e.g. an external host added to the service policy after the cert was generated.
+// User provided server certs are left to the user.
+func missingDefaultVaultServerTLSNames(vr *api.VaultService, ss *v1.Secret) ([]string, error) {
+	if vr.Spec.TLS.Static.ServerSecret != api.DefaultVaultServerTLSSecretName(vr.Name) {
+		return nil, nil
+	}
+	crt, err := tlsutil.ParsePEMEncodedCACert(ss.Data[vaultutil.ServerTLSCertName])
+	if err != nil {
+		return nil, fmt.Errorf("parse server cert in secret (%s) failed: %v", ss.Name, err)
+	}
+	var missing []string
+	for _, name := range defaultVaultServerTLSNames(vr) {
+		if crt.VerifyHostname(name) != nil {
+			missing = append(missing, name)
+		}
+	}
+	return missing, nil
+}
+
+// reissueDefaultVaultServerCert replaces the default vault server cert in the given secret with one that is valid
+// for all the names of the vault service. Vault loads the new cert when the vault pods restart,
+// which the caller triggers with k8sutil.RestartVaultPodsForServerCert.
+func (v *Vaults) reissueDefaultVaultServerCert(vr *api.VaultService, ss *v1.Secret, missing []string) error {
+	caKey, caCrt, err := v.getDefaultVaultCA(vr)
+	if err != nil {
+		return err
+	}
+	se, err := newVaultServerTLSSecret(vr, caKey, caCrt)
+	if err != nil {
+		return err
+	}
+	ss.Data = se.Data
+	_, err = v.kubecli.CoreV1().Secrets(vr.Namespace).Update(ss)
+	if err != nil {
+		return fmt.Errorf("update secret (%s) failed: %v", ss.Name, err)
+	}
+	logrus.Infof("reissued the vault server cert of vault service (%s/%s) for the names %v", vr.Namespace, vr.Name, missing)
+	return nil
+}
+
+// prepareVaultClientTLSSecrets makes sure the vault client certs exist if client authentication is enabled:
+// - the client secret contains the client cert used by the operator and the vault pod probes.
+// - a client secret is issued for each consumer listed in the client auth policy.
+func (v *Vaults) prepareVaultClientTLSSecrets(vr *api.VaultService) (err error) {
+	defer func() {
+		if err != nil {
+			err = fmt.Errorf("prepare vault client TLS secrets failed: %v", err)
+		}
+	}()
+
+	if !api.IsClientAuthEnabled(vr.Spec.TLS) {
+		return nil
+	}
+
+	// TODO: use secrets informer
+	name := vr.Spec.TLS.Static.ClientSecret
+	clientSecret, err := v.kubecli.CoreV1().Secrets(vr.Namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("get secret (%s) failed: %v", name, err)
+	}
+
+	var caKey *rsa.PrivateKey
+	var caCrt *x509.Certificate
+	// loadCA lazily reads the CA from the default vault CA secret, which only exists for operator generated TLS assets.
+	loadCA := func() error {
+		if caKey != nil {
+			return nil
+		}
+		caKey, caCrt, err = v.getDefaultVaultCA(vr)
+		return err
+	}
+
+	if len(clientSecret.Data[api.ClientTLSCertName]) == 0 || len(clientSecret.Data[api.ClientTLSKeyName]) == 0 {
+		if err = loadCA(); err != nil {
+			return fmt.Errorf("client secret (%s) has no %s and %s: %v", name, api.ClientTLSCertName, api.ClientTLSKeyName, err)
+		}
+		key, crt, err := newKeyAndCert(caCrt, caKey, newClientCertConfig(operatorClientCommonName))
+		if err != nil {
+			return err
+		}
+		if clientSecret.Data == nil {
+			clientSecret.Data = map[string][]byte{}
+		}
+		clientSecret.Data[api.ClientTLSCertName] = tlsutil.EncodeCertificatePEM(crt)
+		clientSecret.Data[api.ClientTLSKeyName] = tlsutil.EncodePrivateKeyPEM(key)
+		_, err = v.kubecli.CoreV1().Secrets(vr.Namespace).Update(clientSecret)
+		if err != nil {
+			return fmt.Errorf("update secret (%s) failed: %v", name, err)
+		}
+	}
+
+	for _, sn := range vr.Spec.TLS.ClientAuth.ClientSecrets {
+		_, err = v.kubecli.CoreV1().Secrets(vr.Namespace).Get(sn, metav1.GetOptions{})
+		if err == nil {
+			continue
+		}
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		if err = loadCA(); err != nil {
+			return fmt.Errorf("cannot issue client secret (%s): %v", sn, err)
+		}
+		se, err := newVaultConsumerTLSSecret(vr, caKey, caCrt, sn)
+		if err != nil {
+			return err
+		}
+		// Consumers verify vault against the same CA bundle as the operator.
+		se.Data[api.CATLSCertName] = clientSecret.Data[api.CATLSCertName]
+		k8sutil.AddOwnerRefToObject(se, k8sutil.AsOwner(vr))
+		_, err = v.kubecli.CoreV1().Secrets(vr.Namespace).Create(se)
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// getDefaultVaultCA reads the CA cert and key generated by the operator for the given vault cluster.
+func (v *Vaults) getDefaultVaultCA(vr *api.VaultService) (*rsa.PrivateKey, *x509.Certificate, error) {
+	name := api.DefaultVaultCATLSSecretName(vr.Name)
+	se, err := v.kubecli.CoreV1().Secrets(vr.Namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("get CA secret (%s) failed: %v", name, err)
+	}
+	caKey, err := tlsutil.ParsePEMEncodedPrivateKey(se.Data[vaultCAKeyName])
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse CA key in secret (%s) failed: %v", name, err)
+	}
+	caCrt, err := tlsutil.ParsePEMEncodedCACert(se.Data[vaultCACertName])
+	if err != nil {
+		return nil, nil, fmt.Errorf("parse CA cert in secret (%s) failed: %v", name, err)
+	}
+	return caKey, caCrt, nil
+}
+
 // prepareEtcdTLSSecrets creates three etcd TLS secrets (client, server, peer) containing TLS assets.
 // Currently we self-generate the CA, and use the self generated CA to sign all the TLS certs.
 func (v *Vaults) prepareEtcdTLSSecrets(vr *api.VaultService) (err error) {
@@ -166,7 +408,7 @@ func (v *Vaults) cleanupEtcdTLSSecrets(vr *api.VaultService) (err error) {
 
 // newEtcdClientTLSSecret returns a secret containing etcd client TLS assets
 func newEtcdClientTLSSecret(vr *api.VaultService, caKey *rsa.PrivateKey, caCrt *x509.Certificate) (*v1.Secret, error) {
-	return newTLSSecret(vr, caKey, caCrt, "etcd client", k8sutil.EtcdClientTLSSecretName(vr.Name), nil,
+	return newTLSSecret(vr, caKey, caCrt, "etcd client", k8sutil.EtcdClientTLSSecretName(vr.Name), nil, clientAuthUsages,
 		map[string]string{
 			"key":  "etcd-client.key",
 			"cert": "etcd-client.crt",
@@ -187,6 +429,7 @@ func newEtcdServerTLSSecret(vr *api.VaultService, caKey *rsa.PrivateKey, caCrt *
 			fmt.Sprintf("*.%s.%s.svc.%s", k8sutil.EtcdNameForVault(vr.Name), vr.Namespace, defaultClusterDomain),
 			fmt.Sprintf("%s-client.%s.svc.%s", k8sutil.EtcdNameForVault(vr.Name), vr.Namespace, defaultClusterDomain),
 		},
+		etcdMemberAuthUsages,
 		map[string]string{
 			"key":  "server.key",
 			"cert": "server.crt",
@@ -202,6 +445,7 @@ func newEtcdPeerTLSSecret(vr *api.VaultService, caKey *rsa.PrivateKey, caCrt *x5
 			// TODO: get rid of cluster domain
 			fmt.Sprintf("*.%s.%s.svc.%s", k8sutil.EtcdNameForVault(vr.Name), vr.Namespace, defaultClusterDomain),
 		},
+		etcdMemberAuthUsages,
 		map[string]string{
 			"key":  "peer.key",
 			"cert": "peer.crt",
@@ -227,17 +471,20 @@ func (v *Vaults) cleanupDefaultVaultTLSSecrets(vr *api.VaultService) (err error)
 	if err != nil && !apierrors.IsNotFound(err) {
 		return fmt.Errorf("delete secret (%s) failed: %v", name, err)
 	}
+
+	name = api.DefaultVaultCATLSSecretName(vr.Name)
+	err = v.kubecli.CoreV1().Secrets(vr.Namespace).Delete(name, nil)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("delete secret (%s) failed: %v", name, err)
+	}
 	return nil
 }
 
 // newVaultServerTLSSecret returns a secret containing vault server TLS assets
 func newVaultServerTLSSecret(vr *api.VaultService, caKey *rsa.PrivateKey, caCrt *x509.Certificate) (*v1.Secret, error) {
 	return newTLSSecret(vr, caKey, caCrt, "vault server", api.DefaultVaultServerTLSSecretName(vr.Name),
-		[]string{
-			"localhost",
-			fmt.Sprintf("*.%s.pod", vr.Namespace),
-			fmt.Sprintf("%s.%s.svc", vr.Name, vr.Namespace),
-		},
+		defaultVaultServerTLSNames(vr),
+		serverAuthUsages,
 		map[string]string{
 			"key":  vaultutil.ServerTLSKeyName,
 			"cert": vaultutil.ServerTLSCertName,
@@ -246,8 +493,32 @@ func newVaultServerTLSSecret(vr *api.VaultService, caKey *rsa.PrivateKey, caCrt
 		})
 }
 
+// defaultVaultServerTLSNames returns the names of the default vault server cert.
+// Besides the names the operator uses, it covers the standby and headless services that in-cluster clients use,
+// and the external host that clients outside of the cluster verify vault against.
+func defaultVaultServerTLSNames(vr *api.VaultService) []string {
+	names := append(vaultServerTLSNames(vr),
+		k8sutil.VaultServiceHost(k8sutil.VaultStandbyServiceName(vr.Name), vr.Namespace),
+		k8sutil.VaultServiceHost(k8sutil.VaultHeadlessServiceName(vr.Name), vr.Namespace),
+	)
+	if host := vr.Spec.Service.GetExternalHost(); len(host) != 0 {
+		names = append(names, host)
+	}
+	return names
+}
+
+// vaultServerTLSNames returns the names that the vault server cert must be valid for.
+// The operator verifies every vault pod against the vault service name.
+func vaultServerTLSNames(vr *api.VaultService) []string {
+	return []string{
+		"localhost",
+		k8sutil.VaultServiceHost(vr.Name, vr.Namespace),
+	}
+}
+
 // newVaultClientTLSSecret returns a secret containing vault client TLS assets.
-// The client key and certificate are not generated since clients are not authenticated at the server
+// The client key and certificate are only generated by prepareVaultClientTLSSecrets
+// when clients are authenticated at the server.
 func newVaultClientTLSSecret(vr *api.VaultService, caCrt *x509.Certificate) *v1.Secret {
 	return &v1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
@@ -260,13 +531,46 @@ func newVaultClientTLSSecret(vr *api.VaultService, caCrt *x509.Certificate) *v1.
 	}
 }
 
+// newVaultCATLSSecret returns a secret containing the CA cert and key used to sign the vault TLS assets
+func newVaultCATLSSecret(vr *api.VaultService, caKey *rsa.PrivateKey, caCrt *x509.Certificate) *v1.Secret {
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   api.DefaultVaultCATLSSecretName(vr.Name),
+			Labels: k8sutil.LabelsForVault(vr.Name),
+		},
+		Data: map[string][]byte{
+			vaultCACertName: tlsutil.EncodeCertificatePEM(caCrt),
+			vaultCAKeyName:  tlsutil.EncodePrivateKeyPEM(caKey),
+		},
+	}
+}
+
+// newVaultConsumerTLSSecret returns a secret containing a client cert for a consumer of the vault service
+func newVaultConsumerTLSSecret(vr *api.VaultService, caKey *rsa.PrivateKey, caCrt *x509.Certificate, secretName string) (*v1.Secret, error) {
+	return newTLSSecret(vr, caKey, caCrt, secretName, secretName, nil, clientAuthUsages,
+		map[string]string{
+			"key":  api.ClientTLSKeyName,
+			"cert": api.ClientTLSCertName,
+			"ca":   api.CATLSCertName,
+		})
+}
+
+func newClientCertConfig(commonName string) tlsutil.CertConfig {
+	return tlsutil.CertConfig{
+		CommonName:   commonName,
+		Organization: orgForTLSCert,
+		ExtKeyUsages: clientAuthUsages,
+	}
+}
+
 // newTLSSecret is a common utility for creating a secret containing TLS assets.
 func newTLSSecret(vr *api.VaultService, caKey *rsa.PrivateKey, caCrt *x509.Certificate, commonName, secretName string,
-	addrs []string, fieldMap map[string]string) (*v1.Secret, error) {
+	addrs []string, usages []x509.ExtKeyUsage, fieldMap map[string]string) (*v1.Secret, error) {
 	tc := tlsutil.CertConfig{
 		CommonName:   commonName,
 		Organization: orgForTLSCert,
 		AltNames:     tlsutil.NewAltNames(addrs),
+		ExtKeyUsages: usages,
 	}
 	key, crt, err := newKeyAndCert(caCrt, caKey, tc)
 	if err != nil {
@@ -310,7 +614,6 @@ func newKeyAndCert(caCert *x509.Certificate, caPrivKey *rsa.PrivateKey, config t
 	if err != nil {
 		return nil, nil, err
 	}
-	// TODO: tlsutil.NewSignedCertificate()create certs for both client and server auth. We can limit it stricter.
 	cert, err := tlsutil.NewSignedCertificate(config, key, caCert, caPrivKey)
 	if err != nil {
 		return nil, nil, err
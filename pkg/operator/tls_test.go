@@ -0,0 +1,70 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"reflect"
+	"testing"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+)
+
+func TestMissingDefaultVaultServerTLSNames(t *testing.T) {
+	vr := newTestVaultService()
+	caKey, caCrt, err := newCACert()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ss, err := newVaultServerTLSSecret(vr, caKey, caCrt)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		service *api.ServicePolicy
+		secret  string
+		want    []string
+	}{{
+		name: "unchanged",
+	}, {
+		name:    "ingress host added",
+		service: &api.ServicePolicy{Ingress: &api.IngressPolicy{Host: "vault.example.com"}},
+		want:    []string{"vault.example.com"},
+	}, {
+		name:    "route host added",
+		service: &api.ServicePolicy{Route: &api.RoutePolicy{Host: "vault.apps.example.com"}},
+		want:    []string{"vault.apps.example.com"},
+	}, {
+		name:    "user provided server cert",
+		service: &api.ServicePolicy{ExternalAddress: "https://vault.example.com:8200"},
+		secret:  "my-vault-tls",
+	}}
+	for _, tt := range tests {
+		vr := newTestVaultService()
+		vr.Spec.Service = tt.service
+		if len(tt.secret) != 0 {
+			vr.Spec.TLS.Static.ServerSecret = tt.secret
+		}
+		got, err := missingDefaultVaultServerTLSNames(vr, ss)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: missing names = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"crypto/x509"
+	"fmt"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+	"github.com/coreos/vault-operator/pkg/util/tlsutil"
+	"github.com/coreos/vault-operator/pkg/util/vaultutil"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const reasonInvalidTLSSecret = "InvalidTLSSecret"
+
+// syncVaultTLSCondition validates the vault TLS secrets and records the result
+// as the TLSValid condition of the vault CR.
+func (v *Vaults) syncVaultTLSCondition(vr *api.VaultService) error {
+	verr := v.validateVaultTLSSecrets(vr)
+	if err := v.updateVaultCRCondition(vr, newTLSValidCondition(verr)); err != nil {
+		return err
+	}
+	return verr
+}
+
+// newTLSValidCondition returns the TLSValid condition for the given result of validating the vault TLS secrets.
+func newTLSValidCondition(verr error) api.VaultServiceCondition {
+	if verr != nil {
+		return api.NewCondition(api.VaultServiceTLSValid, v1.ConditionFalse, reasonInvalidTLSSecret, verr.Error())
+	}
+	return api.NewCondition(api.VaultServiceTLSValid, v1.ConditionTrue, "", "")
+}
+
+// validateVaultTLSSecrets gets the vault TLS secrets and validates them with validateVaultTLS.
+func (v *Vaults) validateVaultTLSSecrets(vr *api.VaultService) error {
+	// TODO: use secrets informer
+	clientSecretName := vr.Spec.TLS.Static.ClientSecret
+	clientSecret, err := v.kubecli.CoreV1().Secrets(vr.Namespace).Get(clientSecretName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("get client secret (%s) failed: %v", clientSecretName, err)
+	}
+	serverSecretName := vr.Spec.TLS.Static.ServerSecret
+	serverSecret, err := v.kubecli.CoreV1().Secrets(vr.Namespace).Get(serverSecretName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("get server secret (%s) failed: %v", serverSecretName, err)
+	}
+	return validateVaultTLS(vr, serverSecret, clientSecret)
+}
+
+// validateVaultTLS checks the vault TLS secrets before they get mounted into vault pods:
+// - server.crt in the server secret only contains the server cert, which matches server.key.
+// - the server cert is signed by the CA in the client secret, and covers the names vault is reached at.
+// - if client auth is enabled, the client cert in the client secret matches its key and is signed by the same CA.
+func validateVaultTLS(vr *api.VaultService, serverSecret, clientSecret *v1.Secret) error {
+	clientSecretName, serverSecretName := clientSecret.Name, serverSecret.Name
+	caData, ok := clientSecret.Data[api.CATLSCertName]
+	if !ok {
+		return fmt.Errorf("client secret (%s) has no %s", clientSecretName, api.CATLSCertName)
+	}
+	// The CA file is a bundle if the default vault CA was backfilled.
+	cas, err := tlsutil.ParsePEMEncodedCerts(caData)
+	if err != nil {
+		return fmt.Errorf("parse %s in client secret (%s) failed: %v", api.CATLSCertName, clientSecretName, err)
+	}
+	roots := x509.NewCertPool()
+	for _, ca := range cas {
+		roots.AddCert(ca)
+	}
+
+	cert, err := verifyTLSKeyPair(serverSecret, vaultutil.ServerTLSCertName, vaultutil.ServerTLSKeyName)
+	if err != nil {
+		return fmt.Errorf("server secret (%s): %v", serverSecretName, err)
+	}
+	for _, name := range vaultServerTLSNames(vr) {
+		_, err = cert.Verify(x509.VerifyOptions{
+			DNSName:   name,
+			Roots:     roots,
+			KeyUsages: serverAuthUsages,
+		})
+		if err != nil {
+			return fmt.Errorf("server secret (%s): %s is not valid for %s with the CA in client secret (%s): %v",
+				serverSecretName, vaultutil.ServerTLSCertName, name, clientSecretName, err)
+		}
+	}
+
+	if !api.IsClientAuthEnabled(vr.Spec.TLS) {
+		return nil
+	}
+	cert, err = verifyTLSKeyPair(clientSecret, api.ClientTLSCertName, api.ClientTLSKeyName)
+	if err != nil {
+		return fmt.Errorf("client secret (%s): %v", clientSecretName, err)
+	}
+	_, err = cert.Verify(x509.VerifyOptions{
+		Roots:     roots,
+		KeyUsages: clientAuthUsages,
+	})
+	if err != nil {
+		return fmt.Errorf("client secret (%s): %s is not a valid client cert for %s: %v",
+			clientSecretName, api.ClientTLSCertName, api.CATLSCertName, err)
+	}
+	return nil
+}
+
+// verifyTLSKeyPair checks that the cert file in the secret contains a single certificate
+// matching the key file, and returns the parsed certificate.
+func verifyTLSKeyPair(se *v1.Secret, certName, keyName string) (*x509.Certificate, error) {
+	certData, ok := se.Data[certName]
+	if !ok {
+		return nil, fmt.Errorf("missing %s", certName)
+	}
+	keyData, ok := se.Data[keyName]
+	if !ok {
+		return nil, fmt.Errorf("missing %s", keyName)
+	}
+	certs, err := tlsutil.ParsePEMEncodedCerts(certData)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s failed: %v", certName, err)
+	}
+	if len(certs) != 1 {
+		return nil, fmt.Errorf("%s should only contain one certificate, found %d (it must not be concatenated with the CA)", certName, len(certs))
+	}
+	if err = tlsutil.VerifyKeyPair(certData, keyData); err != nil {
+		return nil, fmt.Errorf("%s does not match %s: %v", certName, keyName, err)
+	}
+	return certs[0], nil
+}
@@ -0,0 +1,231 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"strings"
+	"testing"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+	"github.com/coreos/vault-operator/pkg/util/tlsutil"
+	"github.com/coreos/vault-operator/pkg/util/vaultutil"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// testTLSAssets holds the TLS secrets of a vault service, signed by the same CA.
+type testTLSAssets struct {
+	caKey        *rsa.PrivateKey
+	caCrt        *x509.Certificate
+	serverSecret *v1.Secret
+	clientSecret *v1.Secret
+}
+
+func newTestVaultService() *api.VaultService {
+	vr := &api.VaultService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example",
+			Namespace: "default",
+		},
+	}
+	vr.SetDefaults()
+	return vr
+}
+
+func newTestCert(t *testing.T, caKey *rsa.PrivateKey, caCrt *x509.Certificate, names []string, usages []x509.ExtKeyUsage) ([]byte, []byte) {
+	key, crt, err := newKeyAndCert(caCrt, caKey, tlsutil.CertConfig{
+		CommonName:   "test",
+		Organization: orgForTLSCert,
+		AltNames:     tlsutil.NewAltNames(names),
+		ExtKeyUsages: usages,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tlsutil.EncodeCertificatePEM(crt), tlsutil.EncodePrivateKeyPEM(key)
+}
+
+// newTestTLSAssets returns valid TLS secrets for the given vault service, including a client cert.
+func newTestTLSAssets(t *testing.T, vr *api.VaultService) *testTLSAssets {
+	caKey, caCrt, err := newCACert()
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverCrt, serverKey := newTestCert(t, caKey, caCrt, vaultServerTLSNames(vr), serverAuthUsages)
+	clientCrt, clientKey := newTestCert(t, caKey, caCrt, nil, clientAuthUsages)
+	return &testTLSAssets{
+		caKey: caKey,
+		caCrt: caCrt,
+		serverSecret: &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: vr.Spec.TLS.Static.ServerSecret},
+			Data: map[string][]byte{
+				vaultutil.ServerTLSCertName: serverCrt,
+				vaultutil.ServerTLSKeyName:  serverKey,
+			},
+		},
+		clientSecret: &v1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: vr.Spec.TLS.Static.ClientSecret},
+			Data: map[string][]byte{
+				api.CATLSCertName:     tlsutil.EncodeCertificatePEM(caCrt),
+				api.ClientTLSCertName: clientCrt,
+				api.ClientTLSKeyName:  clientKey,
+			},
+		},
+	}
+}
+
+func TestValidateVaultTLS(t *testing.T) {
+	otherCAKey, otherCACrt, err := newCACert()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		clientAuth bool
+		// modify breaks the valid TLS assets of the test case.
+		modify func(vr *api.VaultService, a *testTLSAssets)
+		// wantErr is a substring of the expected error, or empty if the assets are valid.
+		wantErr string
+	}{{
+		name:   "valid",
+		modify: func(*api.VaultService, *testTLSAssets) {},
+	}, {
+		name:       "valid with client auth",
+		clientAuth: true,
+		modify:     func(*api.VaultService, *testTLSAssets) {},
+	}, {
+		name: "CA bundle",
+		modify: func(_ *api.VaultService, a *testTLSAssets) {
+			a.clientSecret.Data[api.CATLSCertName] = append(tlsutil.EncodeCertificatePEM(otherCACrt), a.clientSecret.Data[api.CATLSCertName]...)
+		},
+	}, {
+		name: "missing CA",
+		modify: func(_ *api.VaultService, a *testTLSAssets) {
+			delete(a.clientSecret.Data, api.CATLSCertName)
+		},
+		wantErr: "has no " + api.CATLSCertName,
+	}, {
+		name: "missing server key",
+		modify: func(_ *api.VaultService, a *testTLSAssets) {
+			delete(a.serverSecret.Data, vaultutil.ServerTLSKeyName)
+		},
+		wantErr: "missing " + vaultutil.ServerTLSKeyName,
+	}, {
+		name: "server cert concatenated with the CA",
+		modify: func(_ *api.VaultService, a *testTLSAssets) {
+			a.serverSecret.Data[vaultutil.ServerTLSCertName] = append(a.serverSecret.Data[vaultutil.ServerTLSCertName], tlsutil.EncodeCertificatePEM(a.caCrt)...)
+		},
+		wantErr: "should only contain one certificate",
+	}, {
+		name: "server key does not match",
+		modify: func(vr *api.VaultService, a *testTLSAssets) {
+			_, key := newTestCert(t, a.caKey, a.caCrt, vaultServerTLSNames(vr), serverAuthUsages)
+			a.serverSecret.Data[vaultutil.ServerTLSKeyName] = key
+		},
+		wantErr: "does not match",
+	}, {
+		name: "server cert signed by another CA",
+		modify: func(vr *api.VaultService, a *testTLSAssets) {
+			crt, key := newTestCert(t, otherCAKey, otherCACrt, vaultServerTLSNames(vr), serverAuthUsages)
+			a.serverSecret.Data[vaultutil.ServerTLSCertName] = crt
+			a.serverSecret.Data[vaultutil.ServerTLSKeyName] = key
+		},
+		wantErr: "is not valid for localhost",
+	}, {
+		name: "server cert without the vault service name",
+		modify: func(_ *api.VaultService, a *testTLSAssets) {
+			crt, key := newTestCert(t, a.caKey, a.caCrt, []string{"localhost"}, serverAuthUsages)
+			a.serverSecret.Data[vaultutil.ServerTLSCertName] = crt
+			a.serverSecret.Data[vaultutil.ServerTLSKeyName] = key
+		},
+		wantErr: "is not valid for example.default.svc",
+	}, {
+		name: "server cert only valid for client auth",
+		modify: func(vr *api.VaultService, a *testTLSAssets) {
+			crt, key := newTestCert(t, a.caKey, a.caCrt, vaultServerTLSNames(vr), clientAuthUsages)
+			a.serverSecret.Data[vaultutil.ServerTLSCertName] = crt
+			a.serverSecret.Data[vaultutil.ServerTLSKeyName] = key
+		},
+		wantErr: "is not valid for localhost",
+	}, {
+		name: "missing client cert without client auth",
+		modify: func(_ *api.VaultService, a *testTLSAssets) {
+			delete(a.clientSecret.Data, api.ClientTLSCertName)
+		},
+	}, {
+		name:       "missing client cert with client auth",
+		clientAuth: true,
+		modify: func(_ *api.VaultService, a *testTLSAssets) {
+			delete(a.clientSecret.Data, api.ClientTLSCertName)
+		},
+		wantErr: "missing " + api.ClientTLSCertName,
+	}, {
+		name:       "client cert signed by another CA",
+		clientAuth: true,
+		modify: func(_ *api.VaultService, a *testTLSAssets) {
+			crt, key := newTestCert(t, otherCAKey, otherCACrt, nil, clientAuthUsages)
+			a.clientSecret.Data[api.ClientTLSCertName] = crt
+			a.clientSecret.Data[api.ClientTLSKeyName] = key
+		},
+		wantErr: "is not a valid client cert",
+	}, {
+		name:       "client cert only valid for server auth",
+		clientAuth: true,
+		modify: func(_ *api.VaultService, a *testTLSAssets) {
+			crt, key := newTestCert(t, a.caKey, a.caCrt, nil, serverAuthUsages)
+			a.clientSecret.Data[api.ClientTLSCertName] = crt
+			a.clientSecret.Data[api.ClientTLSKeyName] = key
+		},
+		wantErr: "is not a valid client cert",
+	}}
+	for _, tt := range tests {
+		vr := newTestVaultService()
+		if tt.clientAuth {
+			vr.Spec.TLS.ClientAuth = &api.ClientAuthPolicy{}
+		}
+		a := newTestTLSAssets(t, vr)
+		tt.modify(vr, a)
+
+		err := validateVaultTLS(vr, a.serverSecret, a.clientSecret)
+		switch {
+		case len(tt.wantErr) == 0 && err != nil:
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+		case len(tt.wantErr) != 0 && err == nil:
+			t.Errorf("%s: want error containing %q, got nil", tt.name, tt.wantErr)
+		case len(tt.wantErr) != 0 && !strings.Contains(err.Error(), tt.wantErr):
+			t.Errorf("%s: want error containing %q, got: %v", tt.name, tt.wantErr, err)
+		}
+	}
+}
+
+func TestNewTLSValidCondition(t *testing.T) {
+	c := newTLSValidCondition(nil)
+	if c.Type != api.VaultServiceTLSValid || c.Status != v1.ConditionTrue || len(c.Reason) != 0 {
+		t.Errorf("valid TLS secrets: unexpected condition %+v", c)
+	}
+
+	vr := newTestVaultService()
+	a := newTestTLSAssets(t, vr)
+	delete(a.clientSecret.Data, api.CATLSCertName)
+	verr := validateVaultTLS(vr, a.serverSecret, a.clientSecret)
+	c = newTLSValidCondition(verr)
+	if c.Type != api.VaultServiceTLSValid || c.Status != v1.ConditionFalse || c.Reason != reasonInvalidTLSSecret || c.Message != verr.Error() {
+		t.Errorf("invalid TLS secrets: unexpected condition %+v", c)
+	}
+}
@@ -0,0 +1,350 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+	"github.com/coreos/vault-operator/pkg/util/k8sutil"
+
+	"github.com/sirupsen/logrus"
+	appsv1beta1 "k8s.io/api/apps/v1beta1"
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	reasonCanaryFailed      = "CanaryFailed"
+	reasonUpgradeRolledBack = "UpgradeRolledBack"
+
+	// maxHealthErrors is the number of health checks in a row an upgraded vault pod may fail
+	// before the upgrade is rolled back.
+	maxHealthErrors = 3
+)
+
+// syncUpgradeProgress follows the upgrade of vault, given the status of this pass and the number of
+// health checks in a row each vault pod failed. It drives the canary of an upgrade with the canary strategy,
+// and once the deployment rolls to the new image, completes the upgrade or rolls it back.
+func (v *Vaults) syncUpgradeProgress(vr *api.VaultService, s *api.VaultServiceStatus, healthErrors map[string]int) error {
+	u := vr.Status.Upgrade
+	image := k8sutil.VaultImage(vr.Spec)
+	if u != nil && u.Phase == api.UpgradePhaseRolling && u.Image == image {
+		return v.syncRollout(vr, s, healthErrors)
+	}
+	if u != nil && u.Phase == api.UpgradePhaseRolledBack && u.PreviousImage == image {
+		// The version was set back to the one rolled back to, so that setting it again retries the upgrade.
+		return v.updateUpgradeStatus(vr, nil)
+	}
+	if vr.Spec.Upgrade.IsCanary() || (u != nil && (u.Phase == api.UpgradePhaseCanary || u.Phase == api.UpgradePhasePaused)) {
+		return v.syncCanaryUpgrade(vr, s)
+	}
+	return nil
+}
+
+// syncCanaryUpgrade drives an upgrade of vault with the canary strategy, given the status of this pass.
+// When the vault deployment runs another image than the spec, a canary pod of the new image is brought up.
+// Once it has been a healthy unsealed node for the soak time, the canary passed, and syncUpgrade rolls
+// the deployment to the new image. If the canary crash loops, does not become an unsealed standby in time,
+// or stops being healthy while it soaks, the upgrade is paused until the canary pod is deleted or the version changes.
+func (v *Vaults) syncCanaryUpgrade(vr *api.VaultService, s *api.VaultServiceStatus) error {
+	u := vr.Status.Upgrade
+	if !vr.Spec.Upgrade.IsCanary() {
+		// The strategy changed before the canary passed, and syncUpgrade rolls the deployment without the canary.
+		if err := v.deleteCanaryPod(vr, u); err != nil {
+			return err
+		}
+		return v.updateUpgradeStatus(vr, nil)
+	}
+
+	d, err := v.kubecli.AppsV1beta1().Deployments(vr.Namespace).Get(vr.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get vault deployment: %v", err)
+	}
+	image := k8sutil.VaultImage(vr.Spec)
+
+	if k8sutil.IsVaultVersionMatch(d.Spec.Template.Spec, vr.Spec) {
+		if u == nil {
+			return nil
+		}
+		switch u.Phase {
+		case api.UpgradePhaseCanary, api.UpgradePhasePaused:
+			// The version was changed back before the canary passed.
+			if err := v.deleteCanaryPod(vr, u); err != nil {
+				return err
+			}
+			logrus.Infof("called off the upgrade of the vault service (%s) to %s", vr.Name, u.Image)
+			c := api.NewCondition(api.VaultServiceUpgradePaused, v1.ConditionFalse, "", fmt.Sprintf("upgrade to %s was called off", u.Image))
+			if err := v.updateVaultCRCondition(vr, c); err != nil {
+				return err
+			}
+			return v.updateUpgradeStatus(vr, nil)
+		}
+		return nil
+	}
+
+	if u == nil || u.Image != image || u.Phase == api.UpgradePhaseCompleted {
+		return v.startCanary(vr, d, u)
+	}
+	if u.Phase == api.UpgradePhaseRolledBack {
+		// A rolled back upgrade is only retried once the version changes.
+		return nil
+	}
+
+	p, err := v.kubecli.CoreV1().Pods(vr.Namespace).Get(u.Canary, metav1.GetOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to get canary pod (%s): %v", u.Canary, err)
+	}
+	if apierrors.IsNotFound(err) || p.DeletionTimestamp != nil {
+		// A paused upgrade is retried by deleting the canary pod.
+		return v.startCanary(vr, d, u)
+	}
+	if u.Phase == api.UpgradePhasePaused {
+		return nil
+	}
+
+	if k8sutil.IsPodCrashLooping(p) {
+		return v.pauseUpgrade(vr, u, fmt.Sprintf("canary pod (%s) is crash looping", u.Canary))
+	}
+	now := time.Now()
+	healthy := s.VaultStatus.Active == u.Canary || presentIn(u.Canary, s.VaultStatus.Standby...)
+	if !healthy {
+		if u.CanaryStandbyTime != nil {
+			return v.pauseUpgrade(vr, u, fmt.Sprintf("canary pod (%s) stopped being a healthy unsealed node while soaking", u.Canary))
+		}
+		if timeout := vr.Spec.Upgrade.GetTimeout(); now.Sub(u.StartTime.Time) > timeout {
+			return v.pauseUpgrade(vr, u, fmt.Sprintf("canary pod (%s) did not become an unsealed standby within %v", u.Canary, timeout))
+		}
+		return nil
+	}
+
+	u = u.DeepCopy()
+	if u.CanaryStandbyTime == nil {
+		u.CanaryStandbyTime = &metav1.Time{Time: now}
+		return v.updateUpgradeStatus(vr, u)
+	}
+	if now.Sub(u.CanaryStandbyTime.Time) < vr.Spec.Upgrade.GetSoakTime() {
+		return nil
+	}
+	logrus.Infof("canary pod (%s) passed, upgrading the vault service (%s) to %s", u.Canary, vr.Name, u.Image)
+	u.Phase = api.UpgradePhaseRolling
+	u.StartTime = &metav1.Time{Time: now}
+	c := api.NewCondition(api.VaultServiceUpgradePaused, v1.ConditionFalse, "",
+		fmt.Sprintf("canary pod (%s) passed, upgrading all vault pods to %s", u.Canary, u.Image))
+	if err := v.updateVaultCRCondition(vr, c); err != nil {
+		return err
+	}
+	if err := v.updateVaultCRCondition(vr, newProgressingCondition(u)); err != nil {
+		return err
+	}
+	return v.updateUpgradeStatus(vr, u)
+}
+
+// syncRollout follows the vault deployment rolling to the image of the upgrade. The upgrade is completed
+// once the active node runs the new image. With an upgrade policy, the upgrade is rolled back if an upgraded pod
+// crash loops or keeps failing health checks, or if the active node doesn't run the new image within the timeout, if set.
+func (v *Vaults) syncRollout(vr *api.VaultService, s *api.VaultServiceStatus, healthErrors map[string]int) error {
+	u := vr.Status.Upgrade
+	// The canary is not needed once the deployment rolls to its image.
+	if len(u.Canary) != 0 {
+		if err := v.deleteCanaryPod(vr, u); err != nil {
+			return err
+		}
+		u = u.DeepCopy()
+		u.Canary = ""
+		if err := v.updateUpgradeStatus(vr, u); err != nil {
+			return err
+		}
+	}
+
+	// The canary pod is not counted as an updated node, even while it terminates.
+	active := s.VaultStatus.Active
+	if len(active) != 0 && presentIn(active, s.UpdatedNodes...) {
+		logrus.Infof("upgraded the vault service (%s) to %s", vr.Name, u.Image)
+		c := api.NewCondition(api.VaultServiceProgressing, v1.ConditionTrue, "", fmt.Sprintf("vault pods are upgraded to %s", u.Image))
+		if err := v.updateVaultCRCondition(vr, c); err != nil {
+			return err
+		}
+		u = u.DeepCopy()
+		u.Phase = api.UpgradePhaseCompleted
+		return v.updateUpgradeStatus(vr, u)
+	}
+
+	// Without an upgrade policy, a failed upgrade is left to be fixed by hand.
+	if vr.Spec.Upgrade == nil || len(u.PreviousImage) == 0 {
+		return nil
+	}
+	msg, err := v.rolloutFailure(vr, u, healthErrors)
+	if err != nil || len(msg) == 0 {
+		return err
+	}
+	return v.rollbackUpgrade(vr, u, msg)
+}
+
+// rolloutFailure returns why the rollout of the upgrade failed, or "" if it has not failed.
+func (v *Vaults) rolloutFailure(vr *api.VaultService, u *api.UpgradeStatus, healthErrors map[string]int) (string, error) {
+	sel := k8sutil.LabelsForVault(vr.Name)
+	pods, err := v.kubecli.CoreV1().Pods(vr.Namespace).List(metav1.ListOptions{LabelSelector: labels.SelectorFromSet(sel).String()})
+	if err != nil {
+		return "", fmt.Errorf("failed to list vault pods: %v", err)
+	}
+	for i := range pods.Items {
+		p := &pods.Items[i]
+		if p.Labels[k8sutil.VaultCanaryLabel] == "true" || p.DeletionTimestamp != nil || p.Spec.Containers[0].Image != u.Image {
+			continue
+		}
+		if k8sutil.IsPodCrashLooping(p) {
+			return fmt.Sprintf("vault pod (%s) is crash looping", p.Name), nil
+		}
+		if n := healthErrors[p.Name]; n >= maxHealthErrors {
+			return fmt.Sprintf("health checks of vault pod (%s) failed %d times in a row", p.Name, n), nil
+		}
+	}
+	// Upgraded pods may wait to be unsealed by hand, so the rollout only times out with an explicit timeout.
+	if len(vr.Spec.Upgrade.Timeout) != 0 {
+		if timeout := vr.Spec.Upgrade.GetTimeout(); time.Since(u.StartTime.Time) > timeout {
+			return fmt.Sprintf("the active vault pod was not upgraded within %v", timeout), nil
+		}
+	}
+	return "", nil
+}
+
+// rollbackUpgrade rolls the vault deployment back to the image before the failed upgrade,
+// and records why with an event and the Progressing condition.
+func (v *Vaults) rollbackUpgrade(vr *api.VaultService, u *api.UpgradeStatus, msg string) error {
+	d, err := v.kubecli.AppsV1beta1().Deployments(vr.Namespace).Get(vr.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get vault deployment: %v", err)
+	}
+	err = k8sutil.RollbackDeployment(v.kubecli, d, u.PreviousImage)
+	if err != nil {
+		return err
+	}
+	logrus.Errorf("rolled back the upgrade of the vault service (%s) to %s: %s", vr.Name, u.Image, msg)
+	v.recorder.Eventf(vr, v1.EventTypeWarning, reasonUpgradeRolledBack, "Rolled back the upgrade to %s to %s: %s", u.Image, u.PreviousImage, msg)
+
+	c := api.NewCondition(api.VaultServiceProgressing, v1.ConditionFalse, reasonUpgradeRolledBack,
+		fmt.Sprintf("upgrade to %s was rolled back to %s: %s", u.Image, u.PreviousImage, msg))
+	if err := v.updateVaultCRCondition(vr, c); err != nil {
+		return err
+	}
+	u = u.DeepCopy()
+	u.Phase = api.UpgradePhaseRolledBack
+	return v.updateUpgradeStatus(vr, u)
+}
+
+// newProgressingCondition returns the Progressing condition of the deployment rolling to the image of the upgrade.
+func newProgressingCondition(u *api.UpgradeStatus) api.VaultServiceCondition {
+	return api.NewCondition(api.VaultServiceProgressing, v1.ConditionTrue, "", fmt.Sprintf("upgrading vault pods to %s", u.Image))
+}
+
+// startCanary replaces the canary pod of the previous upgrade, if any, with a new one of the image of the spec.
+func (v *Vaults) startCanary(vr *api.VaultService, d *appsv1beta1.Deployment, prev *api.UpgradeStatus) error {
+	if err := v.deleteCanaryPod(vr, prev); err != nil {
+		return err
+	}
+	p, err := v.kubecli.CoreV1().Pods(vr.Namespace).Create(k8sutil.NewVaultCanaryPod(vr, d))
+	if err != nil {
+		return fmt.Errorf("failed to create canary pod: %v", err)
+	}
+	image := k8sutil.VaultImage(vr.Spec)
+	logrus.Infof("started canary pod (%s) to upgrade the vault service (%s) to %s", p.Name, vr.Name, image)
+
+	c := api.NewCondition(api.VaultServiceUpgradePaused, v1.ConditionFalse, "",
+		fmt.Sprintf("canary pod (%s) is upgrading to %s", p.Name, image))
+	if err := v.updateVaultCRCondition(vr, c); err != nil {
+		return err
+	}
+	now := metav1.Now()
+	return v.updateUpgradeStatus(vr, &api.UpgradeStatus{
+		Phase:         api.UpgradePhaseCanary,
+		Image:         image,
+		PreviousImage: d.Spec.Template.Spec.Containers[0].Image,
+		Canary:        p.Name,
+		StartTime:     &now,
+	})
+}
+
+// pauseUpgrade stops the upgrade after its canary failed, and records why as the UpgradePaused condition.
+// The canary pod is kept for inspection.
+func (v *Vaults) pauseUpgrade(vr *api.VaultService, u *api.UpgradeStatus, msg string) error {
+	logrus.Errorf("paused the upgrade of the vault service (%s) to %s: %s", vr.Name, u.Image, msg)
+	v.recorder.Eventf(vr, v1.EventTypeWarning, reasonCanaryFailed, "Paused the upgrade to %s: %s", u.Image, msg)
+	c := api.NewCondition(api.VaultServiceUpgradePaused, v1.ConditionTrue, reasonCanaryFailed, msg)
+	if err := v.updateVaultCRCondition(vr, c); err != nil {
+		return err
+	}
+	u = u.DeepCopy()
+	u.Phase = api.UpgradePhasePaused
+	return v.updateUpgradeStatus(vr, u)
+}
+
+// deleteCanaryPod deletes the canary pod of the given upgrade, if any.
+func (v *Vaults) deleteCanaryPod(vr *api.VaultService, u *api.UpgradeStatus) error {
+	if u == nil || len(u.Canary) == 0 {
+		return nil
+	}
+	err := v.kubecli.CoreV1().Pods(vr.Namespace).Delete(u.Canary, nil)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete canary pod (%s): %v", u.Canary, err)
+	}
+	return nil
+}
+
+// updateUpgradeStatus sets the upgrade status of the Vault CR, and of vr.
+func (v *Vaults) updateUpgradeStatus(vr *api.VaultService, u *api.UpgradeStatus) error {
+	vault, err := v.vaultsCRCli.VaultV1alpha1().VaultServices(vr.Namespace).Get(vr.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to update upgrade status: %v", err)
+	}
+	if reflect.DeepEqual(vault.Status.Upgrade, u) {
+		vr.Status.Upgrade = u
+		return nil
+	}
+	vault.Status.Upgrade = u
+	_, err = v.vaultsCRCli.VaultV1alpha1().VaultServices(vr.Namespace).Update(vault)
+	if err != nil {
+		return fmt.Errorf("failed to update upgrade status: %v", err)
+	}
+	vr.Status.Upgrade = u
+	return nil
+}
+
+func presentIn(a string, list ...string) bool {
+	for _, l := range list {
+		if a == l {
+			return true
+		}
+	}
+	return false
+}
+
+// withoutPod returns the pod names without the given one.
+func withoutPod(pods []string, name string) []string {
+	if len(name) == 0 {
+		return pods
+	}
+	var out []string
+	for _, p := range pods {
+		if p != name {
+			out = append(out, p)
+		}
+	}
+	return out
+}
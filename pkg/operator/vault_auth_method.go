@@ -0,0 +1,338 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+	"github.com/coreos/vault-operator/pkg/util/k8sutil"
+
+	vaultapi "github.com/hashicorp/vault/api"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/tools/cache"
+)
+
+const defaultKubernetesHost = "https://kubernetes.default.svc"
+
+func (v *Vaults) newVaultAuthMethodController() *crController {
+	source := cache.NewListWatchFromClient(
+		v.vaultsCRCli.VaultV1alpha1().RESTClient(),
+		api.VaultAuthMethodPlural,
+		v.namespace,
+		fields.Everything())
+	return newCRController(api.VaultAuthMethodKind, source, &api.VaultAuthMethod{}, v.syncVaultAuthMethod)
+}
+
+// syncVaultAuthMethod enables the auth method in the vault service it references, writes its config and roles,
+// and records the result in its status. An auth method being deleted is disabled before its finalizer is removed.
+func (v *Vaults) syncVaultAuthMethod(obj interface{}) error {
+	am := obj.(*api.VaultAuthMethod).DeepCopy()
+	cli := v.vaultsCRCli.VaultV1alpha1().VaultAuthMethods(am.Namespace)
+
+	if am.DeletionTimestamp != nil {
+		if !api.HasFinalizer(am.Finalizers) {
+			return nil
+		}
+		err := v.disableVaultAuthMethod(am)
+		if err != nil {
+			return v.updateVaultAuthMethodStatus(am, am.Status, err)
+		}
+		am.Finalizers = api.RemoveFinalizer(am.Finalizers)
+		_, err = cli.Update(am)
+		return err
+	}
+
+	if !api.HasFinalizer(am.Finalizers) {
+		am.Finalizers = append(am.Finalizers, api.VaultFinalizer)
+		var err error
+		am, err = cli.Update(am)
+		if err != nil {
+			return err
+		}
+	}
+	s, err := v.writeVaultAuthMethod(am)
+	return v.updateVaultAuthMethodStatus(am, s, err)
+}
+
+// writeVaultAuthMethod brings the auth method in vault to its spec.
+// It returns the status of the auth method in vault, which is updated as far as the sync succeeded.
+func (v *Vaults) writeVaultAuthMethod(am *api.VaultAuthMethod) (api.VaultAuthMethodStatus, error) {
+	s := am.Status
+	err := validateAuthMethod(&am.Spec)
+	if err != nil {
+		return s, fmt.Errorf("invalid auth method: %v", err)
+	}
+	path := am.Spec.GetPath()
+	if len(s.Path) != 0 && s.Path != path {
+		return s, fmt.Errorf("path cannot be changed from (%s) to (%s)", s.Path, path)
+	}
+
+	vr, err := v.getVaultService(am.Namespace, am.Spec.VaultService)
+	if err != nil {
+		return s, err
+	}
+	vapi, err := v.newActiveVaultClient(vr)
+	if err != nil {
+		return s, err
+	}
+
+	mounts, err := vapi.Sys().ListAuth()
+	if err != nil {
+		return s, fmt.Errorf("list auth methods failed: %v", err)
+	}
+	if m, ok := mounts[path+"/"]; !ok {
+		err = vapi.Sys().EnableAuthWithOptions(path, &vaultapi.EnableAuthOptions{
+			Type:        string(am.Spec.Type),
+			Description: am.Spec.Description,
+		})
+		if err != nil {
+			return s, fmt.Errorf("enable auth method (%s) failed: %v", path, err)
+		}
+	} else if m.Type != string(am.Spec.Type) {
+		return s, fmt.Errorf("path (%s) is used by an auth method of type (%s)", path, m.Type)
+	}
+	s.Path = path
+
+	config, err := v.authMethodConfig(vr, am)
+	if err != nil {
+		return s, err
+	}
+	if config != nil {
+		_, err = vapi.Logical().Write("auth/"+path+"/config", config)
+		if err != nil {
+			return s, fmt.Errorf("write config of auth method (%s) failed: %v", path, err)
+		}
+	}
+
+	var roles []string
+	written := map[string]bool{}
+	for _, r := range am.Spec.Roles {
+		data, err := v.authRoleData(am, r)
+		if err != nil {
+			return s, err
+		}
+		_, err = vapi.Logical().Write(authRolePath(am, r.Name), data)
+		if err != nil {
+			return s, fmt.Errorf("write role (%s) of auth method (%s) failed: %v", r.Name, path, err)
+		}
+		roles = append(roles, r.Name)
+		written[r.Name] = true
+	}
+	for _, name := range s.Roles {
+		if written[name] {
+			continue
+		}
+		_, err = vapi.Logical().Delete(authRolePath(am, name))
+		if err != nil {
+			return s, fmt.Errorf("delete role (%s) of auth method (%s) failed: %v", name, path, err)
+		}
+	}
+	s.Roles = roles
+	return s, nil
+}
+
+// disableVaultAuthMethod disables the auth method in vault. There is nothing to disable if the vault service is gone
+// or being deleted.
+func (v *Vaults) disableVaultAuthMethod(am *api.VaultAuthMethod) error {
+	path := am.Status.Path
+	if len(path) == 0 {
+		// The auth method was never enabled by the operator.
+		return nil
+	}
+	vr, err := v.getVaultServiceToCleanUp(am.Namespace, am.Spec.VaultService)
+	if err == errVaultServiceNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	vapi, err := v.newActiveVaultClient(vr)
+	if err != nil {
+		return err
+	}
+	err = vapi.Sys().DisableAuth(path)
+	if err != nil {
+		return fmt.Errorf("disable auth method (%s) failed: %v", path, err)
+	}
+	return nil
+}
+
+func validateAuthMethod(s *api.VaultAuthMethodSpec) error {
+	switch s.Type {
+	case api.AuthMethodKubernetes, api.AuthMethodAppRole, api.AuthMethodUserpass, api.AuthMethodJWT:
+	default:
+		return fmt.Errorf("unsupported type (%s)", s.Type)
+	}
+	if s.Kubernetes != nil && s.Type != api.AuthMethodKubernetes {
+		return fmt.Errorf("kubernetes config is set for an auth method of type (%s)", s.Type)
+	}
+	if s.JWT != nil && s.Type != api.AuthMethodJWT {
+		return fmt.Errorf("jwt config is set for an auth method of type (%s)", s.Type)
+	}
+	if s.Type == api.AuthMethodJWT && (s.JWT == nil || len(s.JWT.OIDCDiscoveryURL) == 0 && len(s.JWT.JWTValidationPubKeys) == 0) {
+		return fmt.Errorf("jwt config needs an OIDC discovery URL or validation public keys")
+	}
+	if strings.Trim(s.GetPath(), "/") != s.GetPath() {
+		return fmt.Errorf("path (%s) must not start or end with a slash", s.GetPath())
+	}
+
+	names := map[string]bool{}
+	for _, r := range s.Roles {
+		if len(r.Name) == 0 {
+			return fmt.Errorf("role name is empty")
+		}
+		if names[r.Name] {
+			return fmt.Errorf("role (%s) is specified more than once", r.Name)
+		}
+		names[r.Name] = true
+		if s.Type == api.AuthMethodUserpass && r.PasswordSecret == nil {
+			return fmt.Errorf("user (%s) has no password secret", r.Name)
+		}
+	}
+	return nil
+}
+
+// authMethodConfig returns the config of the auth method to write to vault, or nil if the auth method has no config.
+func (v *Vaults) authMethodConfig(vr *api.VaultService, am *api.VaultAuthMethod) (map[string]interface{}, error) {
+	switch am.Spec.Type {
+	case api.AuthMethodKubernetes:
+		c := am.Spec.Kubernetes
+		if c == nil {
+			c = &api.KubernetesAuthConfig{}
+		}
+		token, ca, err := v.getTokenReviewerCredential(vr, c.TokenReviewerSecret)
+		if err != nil {
+			return nil, err
+		}
+		host := c.Host
+		if len(host) == 0 {
+			host = defaultKubernetesHost
+		}
+		if len(c.CACert) != 0 {
+			ca = c.CACert
+		}
+		return map[string]interface{}{
+			"kubernetes_host":    host,
+			"kubernetes_ca_cert": ca,
+			"token_reviewer_jwt": token,
+		}, nil
+	case api.AuthMethodJWT:
+		c := am.Spec.JWT
+		config := map[string]interface{}{
+			"bound_issuer": c.BoundIssuer,
+		}
+		if len(c.OIDCDiscoveryURL) != 0 {
+			config["oidc_discovery_url"] = c.OIDCDiscoveryURL
+			config["oidc_discovery_ca_pem"] = c.OIDCDiscoveryCACert
+		} else {
+			config["jwt_validation_pubkeys"] = c.JWTValidationPubKeys
+		}
+		return config, nil
+	}
+	return nil, nil
+}
+
+// getTokenReviewerCredential returns the service account token and the CA cert of the Kubernetes API server
+// from the given service account token secret, or from the token secret of the service account of the vault pods.
+func (v *Vaults) getTokenReviewerCredential(vr *api.VaultService, secretName string) (token, ca string, err error) {
+	if len(secretName) == 0 {
+		saName := k8sutil.VaultPodServiceAccountName(vr)
+		// TODO: use service accounts informer
+		sa, err := v.kubecli.CoreV1().ServiceAccounts(vr.Namespace).Get(saName, metav1.GetOptions{})
+		if err != nil {
+			return "", "", fmt.Errorf("failed to get service account (%s): %v", saName, err)
+		}
+		if len(sa.Secrets) == 0 {
+			return "", "", fmt.Errorf("service account (%s) has no token secret yet", saName)
+		}
+		secretName = sa.Secrets[0].Name
+	}
+	// TODO: use secrets informer
+	secret, err := v.kubecli.CoreV1().Secrets(vr.Namespace).Get(secretName, metav1.GetOptions{})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get token reviewer secret (%s): %v", secretName, err)
+	}
+	return string(secret.Data[v1.ServiceAccountTokenKey]), string(secret.Data[v1.ServiceAccountRootCAKey]), nil
+}
+
+func authRolePath(am *api.VaultAuthMethod, name string) string {
+	if am.Spec.Type == api.AuthMethodUserpass {
+		return fmt.Sprintf("auth/%s/users/%s", am.Spec.GetPath(), name)
+	}
+	return fmt.Sprintf("auth/%s/role/%s", am.Spec.GetPath(), name)
+}
+
+// authRoleData returns the parameters of the role to write to vault.
+// The typed fields of the role take precedence over its options.
+func (v *Vaults) authRoleData(am *api.VaultAuthMethod, r api.AuthRole) (map[string]interface{}, error) {
+	data := map[string]interface{}{}
+	for k, val := range r.Options {
+		data[k] = val
+	}
+	if len(r.Policies) != 0 {
+		data["policies"] = r.Policies
+	}
+	ttlKey, maxTTLKey := "ttl", "max_ttl"
+	if am.Spec.Type == api.AuthMethodAppRole {
+		ttlKey, maxTTLKey = "token_ttl", "token_max_ttl"
+	}
+	if len(r.TTL) != 0 {
+		data[ttlKey] = r.TTL
+	}
+	if len(r.MaxTTL) != 0 {
+		data[maxTTLKey] = r.MaxTTL
+	}
+
+	switch am.Spec.Type {
+	case api.AuthMethodKubernetes:
+		data["bound_service_account_names"] = r.BoundServiceAccountNames
+		data["bound_service_account_namespaces"] = r.BoundServiceAccountNamespaces
+	case api.AuthMethodJWT:
+		data["bound_audiences"] = r.BoundAudiences
+		data["user_claim"] = r.UserClaim
+	case api.AuthMethodUserpass:
+		ref := r.PasswordSecret
+		// TODO: use secrets informer
+		secret, err := v.kubecli.CoreV1().Secrets(am.Namespace).Get(ref.Name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get password secret (%s) of user (%s): %v", ref.Name, r.Name, err)
+		}
+		password, ok := secret.Data[ref.Key]
+		if !ok {
+			return nil, fmt.Errorf("password secret (%s) of user (%s) has no key (%s)", ref.Name, r.Name, ref.Key)
+		}
+		data["password"] = string(password)
+	}
+	return data, nil
+}
+
+// updateVaultAuthMethodStatus records the given status and the result of the last sync in the status of the auth method,
+// and returns the sync error so that the auth method is synced again.
+func (v *Vaults) updateVaultAuthMethodStatus(am *api.VaultAuthMethod, s api.VaultAuthMethodStatus, syncErr error) error {
+	s.SyncStatus = newSyncStatus(syncErr)
+	if !reflect.DeepEqual(am.Status, s) {
+		am.Status = s
+		_, err := v.vaultsCRCli.VaultV1alpha1().VaultAuthMethods(am.Namespace).Update(am)
+		if err != nil {
+			return fmt.Errorf("failed to update status of VaultAuthMethod (%s): %v", am.Name, err)
+		}
+	}
+	return syncErr
+}
@@ -0,0 +1,347 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+	"github.com/coreos/vault-operator/pkg/util/k8sutil"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/s3"
+	backuputil "github.com/coreos/etcd-operator/pkg/backup/util"
+	"github.com/coreos/etcd-operator/pkg/util/awsutil/s3factory"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// storageEtcd is the storage backend of the vault services managed by the operator.
+	storageEtcd = "etcd"
+
+	reasonBackupFailed   = "BackupFailed"
+	reasonBackupTimedOut = "BackupTimedOut"
+
+	// backupPollInterval is how often a running backup is checked on.
+	backupPollInterval = 10 * time.Second
+)
+
+// backupFailedError is returned when a backup cannot succeed any more, and is not retried.
+type backupFailedError struct {
+	error
+}
+
+func (v *Vaults) newVaultBackupController() *crController {
+	source := cache.NewListWatchFromClient(
+		v.vaultsCRCli.VaultV1alpha1().RESTClient(),
+		api.VaultBackupPlural,
+		v.namespace,
+		fields.Everything())
+	return newCRController(api.VaultBackupKind, source, &api.VaultBackup{}, v.syncVaultBackup)
+}
+
+// syncVaultBackup starts the snapshot of the storage of the vault service that the backup references,
+// and records its progress in the status of the backup until the snapshot succeeds or fails.
+// The EtcdBackup or job taking the snapshot is owned by the backup, and deleted with it.
+// With spec.deleteSnapshot, the snapshot is deleted before the finalizer of the backup is removed.
+func (v *Vaults) syncVaultBackup(obj interface{}) error {
+	b := obj.(*api.VaultBackup).DeepCopy()
+	cli := v.vaultsCRCli.VaultV1alpha1().VaultBackups(b.Namespace)
+
+	if b.DeletionTimestamp != nil {
+		if !api.HasFinalizer(b.Finalizers) {
+			return nil
+		}
+		deleted, err := v.deleteSnapshot(b)
+		if err != nil || !deleted {
+			return err
+		}
+		b.Finalizers = api.RemoveFinalizer(b.Finalizers)
+		_, err = cli.Update(b)
+		return err
+	}
+
+	if b.Spec.DeleteSnapshot && !api.HasFinalizer(b.Finalizers) {
+		b.Finalizers = append(b.Finalizers, api.VaultFinalizer)
+		var err error
+		b, err = cli.Update(b)
+		if err != nil {
+			return err
+		}
+	}
+	if isBackupDone(b) {
+		return nil
+	}
+
+	s := b.Status.DeepCopy()
+	s.Reason, s.Message = "", ""
+	err := v.runVaultBackup(b, s)
+	if err != nil {
+		s.Message = err.Error()
+		switch err.(type) {
+		case backupFailedError:
+			s.Phase = api.BackupPhaseFailed
+			s.Reason = reasonBackupFailed
+			err = nil
+		default:
+			s.Reason = reasonSyncFailed
+			if err == errVaultServiceNotFound {
+				s.Reason = reasonVaultServiceNotFound
+			}
+		}
+	}
+
+	if !reflect.DeepEqual(&b.Status, s) {
+		b.Status = *s
+		_, uerr := cli.Update(b)
+		if uerr != nil {
+			return fmt.Errorf("failed to update status of VaultBackup (%s): %v", b.Name, uerr)
+		}
+	}
+	if err == nil && s.Phase == api.BackupPhaseRunning {
+		return requeueAfter(backupPollInterval)
+	}
+	return err
+}
+
+// runVaultBackup starts the snapshot, or checks on the one started earlier, and fills in the given status.
+func (v *Vaults) runVaultBackup(b *api.VaultBackup, s *api.VaultBackupStatus) error {
+	if err := validateVaultBackup(b); err != nil {
+		return backupFailedError{err}
+	}
+	vr, err := v.getVaultService(b.Namespace, b.Spec.VaultService)
+	if err != nil {
+		return err
+	}
+	s.Storage = storageEtcd
+	if b.Spec.S3 != nil {
+		return v.runS3Backup(b, vr, s)
+	}
+	return v.runPVCBackup(b, vr, s)
+}
+
+// runS3Backup saves the snapshot to S3 through an EtcdBackup of the etcd backup operator.
+func (v *Vaults) runS3Backup(b *api.VaultBackup, vr *api.VaultService, s *api.VaultBackupStatus) error {
+	s.Location = "s3://" + b.Spec.S3.Path
+	ebCli := v.etcdCRCli.EtcdV1beta2().EtcdBackups(b.Namespace)
+	eb, err := ebCli.Get(k8sutil.BackupResourceName(b.Name), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = ebCli.Create(k8sutil.NewEtcdBackup(b, vr))
+		if err != nil {
+			return fmt.Errorf("failed to create etcd backup: %v", err)
+		}
+		s.Phase = api.BackupPhaseRunning
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get etcd backup: %v", err)
+	}
+
+	if len(eb.Status.Reason) != 0 {
+		return backupFailedError{fmt.Errorf("etcd backup failed: %s", eb.Status.Reason)}
+	}
+	if !eb.Status.Succeeded {
+		s.Phase = api.BackupPhaseRunning
+		return nil
+	}
+	size, err := v.s3ObjectSize(b.Namespace, b.Spec.S3)
+	if err != nil {
+		return err
+	}
+	s.Size = size
+	s.Revision = eb.Status.EtcdRevision
+	s.EtcdVersion = eb.Status.EtcdVersion
+	completeBackup(s)
+	return nil
+}
+
+// runPVCBackup saves the snapshot to the persistent volume claim with a job running etcdctl.
+func (v *Vaults) runPVCBackup(b *api.VaultBackup, vr *api.VaultService, s *api.VaultBackupStatus) error {
+	s.Location = fmt.Sprintf("pvc://%s/%s", b.Spec.PVC.ClaimName, b.Spec.PVC.GetPath(b.Name))
+	jobCli := v.kubecli.BatchV1().Jobs(b.Namespace)
+	job, err := jobCli.Get(k8sutil.BackupResourceName(b.Name), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = jobCli.Create(k8sutil.NewEtcdSnapshotJob(b, vr))
+		if err != nil {
+			return fmt.Errorf("failed to create snapshot job: %v", err)
+		}
+		s.Phase = api.BackupPhaseRunning
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get snapshot job: %v", err)
+	}
+
+	for _, c := range job.Status.Conditions {
+		if c.Type == batchv1.JobFailed && c.Status == v1.ConditionTrue {
+			msg, _ := v.snapshotPodMessage(job, v1.PodFailed)
+			return backupFailedError{fmt.Errorf("snapshot job failed: %s: %s", c.Message, msg)}
+		}
+	}
+	if job.Status.Succeeded == 0 {
+		s.Phase = api.BackupPhaseRunning
+		return nil
+	}
+
+	msg, err := v.snapshotPodMessage(job, v1.PodSucceeded)
+	if err != nil {
+		return err
+	}
+	// The status of the snapshot, as printed by "etcdctl snapshot status -w json".
+	var ss struct {
+		Revision  int64 `json:"revision"`
+		TotalSize int64 `json:"totalSize"`
+	}
+	if err := json.Unmarshal([]byte(msg), &ss); err != nil {
+		return backupFailedError{fmt.Errorf("failed to parse snapshot status (%s): %v", msg, err)}
+	}
+	ec, err := v.etcdCRCli.EtcdV1beta2().EtcdClusters(vr.Namespace).Get(k8sutil.EtcdNameForVault(vr.Name), metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get etcd cluster: %v", err)
+	}
+	s.Size = ss.TotalSize
+	s.Revision = ss.Revision
+	s.EtcdVersion = ec.Status.CurrentVersion
+	completeBackup(s)
+	return nil
+}
+
+// snapshotPodMessage returns the termination message of a pod of the snapshot job in the given phase.
+func (v *Vaults) snapshotPodMessage(job *batchv1.Job, phase v1.PodPhase) (string, error) {
+	sel := labels.SelectorFromSet(job.Spec.Selector.MatchLabels)
+	pods, err := v.kubecli.CoreV1().Pods(job.Namespace).List(metav1.ListOptions{LabelSelector: sel.String()})
+	if err != nil {
+		return "", fmt.Errorf("failed to list pods of snapshot job: %v", err)
+	}
+	for _, p := range pods.Items {
+		if p.Status.Phase != phase {
+			continue
+		}
+		for _, cs := range p.Status.ContainerStatuses {
+			if cs.State.Terminated != nil && len(cs.State.Terminated.Message) != 0 {
+				return cs.State.Terminated.Message, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no termination message found in the pods of snapshot job (%s)", job.Name)
+}
+
+// deleteSnapshot deletes the snapshot of the backup, and returns true once it is gone.
+// A snapshot on a volume is deleted by a job, which is checked on again with the next resync.
+func (v *Vaults) deleteSnapshot(b *api.VaultBackup) (bool, error) {
+	if len(b.Status.Location) == 0 {
+		// The backup never started.
+		return true, nil
+	}
+	if b.Spec.S3 != nil {
+		return true, v.deleteS3Object(b.Namespace, b.Spec.S3)
+	}
+
+	jobCli := v.kubecli.BatchV1().Jobs(b.Namespace)
+	job, err := jobCli.Get(k8sutil.SnapshotDeleteJobName(b.Name), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		_, err = jobCli.Create(k8sutil.NewSnapshotDeleteJob(b))
+		if err != nil {
+			return false, fmt.Errorf("failed to create snapshot delete job: %v", err)
+		}
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get snapshot delete job: %v", err)
+	}
+	for _, c := range job.Status.Conditions {
+		if c.Type == batchv1.JobFailed && c.Status == v1.ConditionTrue {
+			return false, fmt.Errorf("snapshot delete job (%s) failed: %s", job.Name, c.Message)
+		}
+	}
+	return job.Status.Succeeded != 0, nil
+}
+
+// newS3Client returns an S3 client with the credentials of the given S3 target.
+// The client must be closed after use.
+func (v *Vaults) newS3Client(namespace string, t *api.S3BackupTarget) (*s3factory.S3Client, string, string, error) {
+	bucket, key, err := backuputil.ParseBucketAndKey(t.Path)
+	if err != nil {
+		return nil, "", "", backupFailedError{err}
+	}
+	cli, err := s3factory.NewClientFromSecret(v.kubecli, namespace, t.Endpoint, t.AWSSecret)
+	if err != nil {
+		return nil, "", "", err
+	}
+	return cli, bucket, key, nil
+}
+
+// deleteS3Object deletes the snapshot saved to the given S3 target. Deleting a missing snapshot succeeds.
+func (v *Vaults) deleteS3Object(namespace string, t *api.S3BackupTarget) error {
+	cli, bucket, key, err := v.newS3Client(namespace, t)
+	if err != nil {
+		return err
+	}
+	defer cli.Close()
+	_, err = cli.S3.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete snapshot (%s) from S3: %v", t.Path, err)
+	}
+	return nil
+}
+
+// s3ObjectSize returns the size of the snapshot saved to the given S3 target.
+func (v *Vaults) s3ObjectSize(namespace string, t *api.S3BackupTarget) (int64, error) {
+	cli, bucket, key, err := v.newS3Client(namespace, t)
+	if err != nil {
+		return 0, err
+	}
+	defer cli.Close()
+	resp, err := cli.S3.HeadObject(&s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to get snapshot (%s) from S3: %v", t.Path, err)
+	}
+	return aws.Int64Value(resp.ContentLength), nil
+}
+
+func completeBackup(s *api.VaultBackupStatus) {
+	now := metav1.NewTime(time.Now())
+	s.Phase = api.BackupPhaseSucceeded
+	s.CompletionTime = &now
+}
+
+func validateVaultBackup(b *api.VaultBackup) error {
+	if (b.Spec.S3 == nil) == (b.Spec.PVC == nil) {
+		return errors.New("exactly one of spec.s3 and spec.pvc must be set")
+	}
+	if b.Spec.S3 != nil && (len(b.Spec.S3.Path) == 0 || len(b.Spec.S3.AWSSecret) == 0) {
+		return errors.New("spec.s3.path and spec.s3.awsSecret must be set")
+	}
+	if b.Spec.PVC != nil && len(b.Spec.PVC.ClaimName) == 0 {
+		return errors.New("spec.pvc.claimName must be set")
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+	"github.com/coreos/vault-operator/pkg/util/k8sutil"
+
+	vaultapi "github.com/hashicorp/vault/api"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// vaultTLSConfig is the TLS config for talking to a vault cluster,
+// built from a given revision of the vault client secret.
+type vaultTLSConfig struct {
+	secretName      string
+	resourceVersion string
+	clientAuth      bool
+	config          *tls.Config
+}
+
+// getVaultTLSConfig returns the TLS config for talking to the given vault cluster.
+// The config is built in memory from the vault client secret, and shared by all
+// vault API calls of the operator. It is rebuilt when the secret changes, e.g. on CA rotation.
+func (v *Vaults) getVaultTLSConfig(vr *api.VaultService) (*tls.Config, error) {
+	secretName := vr.Spec.TLS.Static.ClientSecret
+	clientAuth := api.IsClientAuthEnabled(vr.Spec.TLS)
+
+	// TODO: use secrets informer
+	secret, err := v.kubecli.CoreV1().Secrets(vr.Namespace).Get(secretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("read client tls failed: failed to get secret (%s): %v", secretName, err)
+	}
+
+	v.tlsConfigsMu.Lock()
+	defer v.tlsConfigsMu.Unlock()
+
+	c, ok := v.tlsConfigs[vr.Name]
+	if ok && c.secretName == secretName && c.resourceVersion == secret.ResourceVersion && c.clientAuth == clientAuth {
+		return c.config, nil
+	}
+
+	config, err := k8sutil.NewVaultTLSConfig(secret, clientAuth)
+	if err != nil {
+		return nil, err
+	}
+	v.tlsConfigs[vr.Name] = &vaultTLSConfig{
+		secretName:      secretName,
+		resourceVersion: secret.ResourceVersion,
+		clientAuth:      clientAuth,
+		config:          config,
+	}
+	return config, nil
+}
+
+// deleteVaultTLSConfig drops the cached TLS config of the given vault cluster.
+func (v *Vaults) deleteVaultTLSConfig(name string) {
+	v.tlsConfigsMu.Lock()
+	delete(v.tlsConfigs, name)
+	v.tlsConfigsMu.Unlock()
+}
+
+// getOperatorToken returns the vault token the operator authenticates with: the token of its own vault identity
+// if spec.operatorAuth is set and the identity is set up, or the token of the operator token secret otherwise.
+func (v *Vaults) getOperatorToken(vr *api.VaultService) (string, error) {
+	if p := vr.Spec.OperatorAuth; p != nil {
+		cred, err := v.getOperatorCredential(vr)
+		if err != nil {
+			return "", err
+		}
+		if cred != nil && cred.Method == p.Method && cred.valid() {
+			return cred.Token, nil
+		}
+	}
+	return v.getBootstrapToken(vr)
+}
+
+// getBootstrapToken reads the vault token from the operator token secret.
+func (v *Vaults) getBootstrapToken(vr *api.VaultService) (string, error) {
+	secretName := vr.Spec.OperatorTokenSecret
+	if len(secretName) == 0 {
+		return "", fmt.Errorf("no operator token secret is specified")
+	}
+	// TODO: use secrets informer
+	secret, err := v.kubecli.CoreV1().Secrets(vr.Namespace).Get(secretName, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("read operator token failed: failed to get secret (%s): %v", secretName, err)
+	}
+	token, ok := secret.Data[api.OperatorTokenKey]
+	if !ok {
+		return "", fmt.Errorf("read operator token failed: secret (%s) has no %s", secretName, api.OperatorTokenKey)
+	}
+	return string(token), nil
+}
+
+// newVaultClient returns a vault client for the given vault pod, authenticated with the operator token.
+func (v *Vaults) newVaultClient(vr *api.VaultService, podName string, tlsConfig *tls.Config) (*vaultapi.Client, error) {
+	token, err := v.getOperatorToken(vr)
+	if err != nil {
+		return nil, err
+	}
+	vapi, err := v.newVaultPodClient(vr, podName, tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+	vapi.SetToken(token)
+	return vapi, nil
+}
+
+// newVaultPodClient returns an unauthenticated vault client for the given vault pod.
+func (v *Vaults) newVaultPodClient(vr *api.VaultService, podName string, tlsConfig *tls.Config) (*vaultapi.Client, error) {
+	// TODO: use pods informer
+	pod, err := v.kubecli.CoreV1().Pods(vr.Namespace).Get(podName, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get vault pod (%s): %v", podName, err)
+	}
+	vapi, err := k8sutil.NewVaultPodClient(pod, tlsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating client for the vault pod (%s): %v", podName, err)
+	}
+	vapi.ClearToken()
+	return vapi, nil
+}
+
+// errVaultServiceNotFound is returned when the vault service that an object is written to doesn't exist.
+var errVaultServiceNotFound = errors.New("vault service not found")
+
+// vaultUnavailableError is returned when the vault service that an object is written to cannot be talked to.
+type vaultUnavailableError struct {
+	error
+}
+
+// getVaultService returns the vault service of the given name and namespace from the informer cache.
+func (v *Vaults) getVaultService(namespace, name string) (*api.VaultService, error) {
+	obj, exists, err := v.indexer.GetByKey(namespace + "/" + name)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, errVaultServiceNotFound
+	}
+	return obj.(*api.VaultService), nil
+}
+
+// getVaultServiceToCleanUp returns the vault service that an object being deleted is to be removed from.
+// It returns errVaultServiceNotFound if the vault service is gone or being deleted,
+// since its data goes away with it and there is nothing to clean up.
+func (v *Vaults) getVaultServiceToCleanUp(namespace, name string) (*api.VaultService, error) {
+	vr, err := v.getVaultService(namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	if vr.DeletionTimestamp != nil {
+		return nil, errVaultServiceNotFound
+	}
+	return vr, nil
+}
+
+// newActiveVaultClient returns a vault client for the active node of the given vault service,
+// authenticated with the token of the operator token secret.
+// Policies, auth methods and secret engines are not written with the vault identity of the operator,
+// as whoever can write them can grant itself any policy.
+func (v *Vaults) newActiveVaultClient(vr *api.VaultService) (*vaultapi.Client, error) {
+	active := vr.Status.VaultStatus.Active
+	if len(active) == 0 {
+		return nil, vaultUnavailableError{fmt.Errorf("vault service (%s) has no active node", vr.Name)}
+	}
+	tlsConfig, err := v.getVaultTLSConfig(vr)
+	if err != nil {
+		return nil, vaultUnavailableError{err}
+	}
+	token, err := v.getBootstrapToken(vr)
+	if err != nil {
+		return nil, vaultUnavailableError{err}
+	}
+	vapi, err := v.newVaultPodClient(vr, active, tlsConfig)
+	if err != nil {
+		return nil, vaultUnavailableError{err}
+	}
+	vapi.SetToken(token)
+	return vapi, nil
+}
+
+const (
+	reasonVaultServiceNotFound = "VaultServiceNotFound"
+	reasonVaultUnavailable     = "VaultUnavailable"
+	reasonSyncFailed           = "SyncFailed"
+	reasonDrifted              = "Drifted"
+)
+
+// newSyncStatus returns the sync status for the result of writing an object to vault.
+func newSyncStatus(err error) api.SyncStatus {
+	if err == nil {
+		return api.SyncStatus{Synced: true}
+	}
+	reason := reasonSyncFailed
+	if err == errVaultServiceNotFound {
+		reason = reasonVaultServiceNotFound
+	} else if _, ok := err.(vaultUnavailableError); ok {
+		reason = reasonVaultUnavailable
+	} else if _, ok := err.(driftError); ok {
+		reason = reasonDrifted
+	}
+	return api.SyncStatus{Reason: reason, Message: err.Error()}
+}
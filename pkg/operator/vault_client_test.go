@@ -0,0 +1,97 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"testing"
+
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetVaultTLSConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		// update changes the vault service or its client secret after the first config is built.
+		update func(v *Vaults, a *testTLSAssets)
+		// delete drops the cached config before the second call.
+		delete      bool
+		wantRebuilt bool
+	}{{
+		name:   "unchanged",
+		update: func(*Vaults, *testTLSAssets) {},
+	}, {
+		name: "client secret updated",
+		update: func(v *Vaults, a *testTLSAssets) {
+			a.clientSecret.ResourceVersion = "2"
+			if _, err := v.kubecli.CoreV1().Secrets("default").Update(a.clientSecret); err != nil {
+				t.Fatal(err)
+			}
+		},
+		wantRebuilt: true,
+	}, {
+		name:        "cache entry deleted",
+		update:      func(*Vaults, *testTLSAssets) {},
+		delete:      true,
+		wantRebuilt: true,
+	}}
+	for _, tt := range tests {
+		vr := newTestVaultService()
+		a := newTestTLSAssets(t, vr)
+		a.clientSecret.Namespace = vr.Namespace
+		a.clientSecret.ResourceVersion = "1"
+		v := &Vaults{
+			kubecli:    fake.NewSimpleClientset(a.clientSecret),
+			tlsConfigs: map[string]*vaultTLSConfig{},
+		}
+
+		first, err := v.getVaultTLSConfig(vr)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		tt.update(v, a)
+		if tt.delete {
+			v.deleteVaultTLSConfig(vr.Name)
+			if _, ok := v.tlsConfigs[vr.Name]; ok {
+				t.Errorf("%s: cache entry still exists after delete", tt.name)
+			}
+		}
+		second, err := v.getVaultTLSConfig(vr)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if rebuilt := first != second; rebuilt != tt.wantRebuilt {
+			t.Errorf("%s: config rebuilt = %v, want %v", tt.name, rebuilt, tt.wantRebuilt)
+		}
+		if c := v.tlsConfigs[vr.Name]; c == nil || c.config != second {
+			t.Errorf("%s: cache entry does not hold the returned config", tt.name)
+		}
+	}
+}
+
+func TestGetVaultTLSConfigMissingSecret(t *testing.T) {
+	vr := newTestVaultService()
+	v := &Vaults{
+		kubecli:    fake.NewSimpleClientset(),
+		tlsConfigs: map[string]*vaultTLSConfig{},
+	}
+	if _, err := v.getVaultTLSConfig(vr); err == nil {
+		t.Fatal("expected an error for a missing client secret")
+	}
+	if len(v.tlsConfigs) != 0 {
+		t.Errorf("cache has %d entries, want none", len(v.tlsConfigs))
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"fmt"
+	"reflect"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/tools/cache"
+)
+
+func (v *Vaults) newVaultPolicyController() *crController {
+	source := cache.NewListWatchFromClient(
+		v.vaultsCRCli.VaultV1alpha1().RESTClient(),
+		api.VaultPolicyPlural,
+		v.namespace,
+		fields.Everything())
+	return newCRController(api.VaultPolicyKind, source, &api.VaultPolicy{}, v.syncVaultPolicy)
+}
+
+// syncVaultPolicy writes the policy to the vault service it references, and records the result in its status.
+// A policy being deleted is removed from vault before its finalizer is removed.
+func (v *Vaults) syncVaultPolicy(obj interface{}) error {
+	p := obj.(*api.VaultPolicy).DeepCopy()
+	cli := v.vaultsCRCli.VaultV1alpha1().VaultPolicies(p.Namespace)
+
+	if p.DeletionTimestamp != nil {
+		if !api.HasFinalizer(p.Finalizers) {
+			return nil
+		}
+		err := v.deleteVaultPolicy(p)
+		if err != nil {
+			return v.updateVaultPolicyStatus(p, err)
+		}
+		p.Finalizers = api.RemoveFinalizer(p.Finalizers)
+		_, err = cli.Update(p)
+		return err
+	}
+
+	if !api.HasFinalizer(p.Finalizers) {
+		p.Finalizers = append(p.Finalizers, api.VaultFinalizer)
+		var err error
+		p, err = cli.Update(p)
+		if err != nil {
+			return err
+		}
+	}
+	return v.updateVaultPolicyStatus(p, v.writeVaultPolicy(p))
+}
+
+// isBuiltinPolicy reports whether the given policy is one of the policies vault creates itself.
+// The default policy can be updated, but not deleted; the root policy can be neither.
+func isBuiltinPolicy(name string) bool {
+	return name == "root" || name == "default"
+}
+
+func (v *Vaults) writeVaultPolicy(p *api.VaultPolicy) error {
+	name := p.GetPolicyName()
+	if name == "root" {
+		return fmt.Errorf("the root policy cannot be modified")
+	}
+	vr, err := v.getVaultService(p.Namespace, p.Spec.VaultService)
+	if err != nil {
+		return err
+	}
+	vapi, err := v.newActiveVaultClient(vr)
+	if err != nil {
+		return err
+	}
+	_, err = vapi.Logical().Write("sys/policies/acl/"+name, map[string]interface{}{"policy": p.Spec.Policy})
+	if err != nil {
+		return fmt.Errorf("write policy (%s) failed: %v", name, err)
+	}
+	return nil
+}
+
+// deleteVaultPolicy deletes the policy from vault. There is nothing to delete if the vault service is gone
+// or being deleted. The built-in policies are left in place, since vault refuses to delete them.
+func (v *Vaults) deleteVaultPolicy(p *api.VaultPolicy) error {
+	name := p.GetPolicyName()
+	if isBuiltinPolicy(name) {
+		return nil
+	}
+	vr, err := v.getVaultServiceToCleanUp(p.Namespace, p.Spec.VaultService)
+	if err == errVaultServiceNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	vapi, err := v.newActiveVaultClient(vr)
+	if err != nil {
+		return err
+	}
+	_, err = vapi.Logical().Delete("sys/policies/acl/" + name)
+	if err != nil {
+		return fmt.Errorf("delete policy (%s) failed: %v", name, err)
+	}
+	return nil
+}
+
+// updateVaultPolicyStatus records the result of the last sync in the status of the policy,
+// and returns the sync error so that the policy is synced again.
+func (v *Vaults) updateVaultPolicyStatus(p *api.VaultPolicy, syncErr error) error {
+	s := newSyncStatus(syncErr)
+	if !reflect.DeepEqual(p.Status, s) {
+		p.Status = s
+		_, err := v.vaultsCRCli.VaultV1alpha1().VaultPolicies(p.Namespace).Update(p)
+		if err != nil {
+			return fmt.Errorf("failed to update status of VaultPolicy (%s): %v", p.Name, err)
+		}
+	}
+	return syncErr
+}
@@ -0,0 +1,79 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"testing"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestDeleteVaultPolicyWithoutVault(t *testing.T) {
+	deleting := newTestVaultService()
+	deleting.Name = "deleting"
+	now := metav1.Now()
+	deleting.DeletionTimestamp = &now
+	sealed := newTestVaultService()
+	sealed.Name = "sealed"
+
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, vr := range []*api.VaultService{deleting, sealed} {
+		if err := indexer.Add(vr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	v := &Vaults{indexer: indexer}
+
+	tests := []struct {
+		name         string
+		vaultService string
+		policyName   string
+		wantErr      bool
+	}{{
+		name:         "vault service gone",
+		vaultService: "gone",
+		policyName:   "demo",
+	}, {
+		name:         "vault service being deleted",
+		vaultService: deleting.Name,
+		policyName:   "demo",
+	}, {
+		name:         "default policy",
+		vaultService: sealed.Name,
+		policyName:   "default",
+	}, {
+		name:         "root policy",
+		vaultService: sealed.Name,
+		policyName:   "root",
+	}, {
+		name:         "sealed vault service",
+		vaultService: sealed.Name,
+		policyName:   "demo",
+		wantErr:      true,
+	}}
+	for _, tt := range tests {
+		p := &api.VaultPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "demo", Namespace: "default"},
+			Spec:       api.VaultPolicySpec{VaultService: tt.vaultService, Name: tt.policyName},
+		}
+		err := v.deleteVaultPolicy(p)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: error = %v, want error %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"fmt"
+	"time"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+	"github.com/coreos/vault-operator/pkg/util/k8sutil"
+
+	"github.com/coreos/etcd-operator/pkg/util/retryutil"
+	"github.com/sirupsen/logrus"
+	"k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	reasonRestoring     = "Restoring"
+	reasonRestoreFailed = "RestoreFailed"
+)
+
+// restoreVault replaces the etcd cluster of the vault service with one restored from spec.restoreFrom,
+// and waits for the restored etcd cluster to be ready. It runs before vault is deployed,
+// so that vault never sees the empty etcd cluster that is replaced.
+// The Restored condition is only set to "True" once vault sees the restored data, by completeRestore.
+func (v *Vaults) restoreVault(vr *api.VaultService) error {
+	erCli := v.etcdCRCli.EtcdV1beta2().EtcdRestores(vr.Namespace)
+	name := k8sutil.EtcdRestoreName(vr.Name)
+
+	_, err := erCli.Get(name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		src, err := v.restoreSource(vr)
+		if err != nil {
+			return v.failRestore(vr, err)
+		}
+		_, err = erCli.Create(k8sutil.NewEtcdRestore(vr, src))
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create etcd restore (%s): %v", name, err)
+		}
+		logrus.Infof("restoring the vault service (%s) from s3://%s", vr.Name, src.Path)
+		c := api.NewCondition(api.VaultServiceRestored, v1.ConditionFalse, reasonRestoring,
+			fmt.Sprintf("restoring etcd from s3://%s", src.Path))
+		if err := v.updateVaultCRCondition(vr, c); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return fmt.Errorf("failed to get etcd restore (%s): %v", name, err)
+	}
+
+	var reason string
+	err = retryutil.Retry(10*time.Second, 10, func() (bool, error) {
+		er, err := erCli.Get(name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		reason = er.Status.Reason
+		return er.Status.Succeeded || len(reason) != 0, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to wait for etcd restore (%s): %v", name, err)
+	}
+	if len(reason) != 0 {
+		return v.failRestore(vr, fmt.Errorf("etcd restore (%s) failed: %s", name, reason))
+	}
+
+	err = k8sutil.WaitEtcdClusterReady(v.etcdCRCli, vr)
+	if err != nil {
+		return fmt.Errorf("failed to wait for the restored etcd cluster: %v", err)
+	}
+	return nil
+}
+
+// restoreSource returns where on S3 the snapshot to restore the vault service from is.
+func (v *Vaults) restoreSource(vr *api.VaultService) (*api.S3BackupTarget, error) {
+	r := vr.Spec.RestoreFrom
+	if r.S3 != nil {
+		return r.S3, nil
+	}
+	b, err := v.vaultsCRCli.VaultV1alpha1().VaultBackups(vr.Namespace).Get(r.Backup, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get backup (%s): %v", r.Backup, err)
+	}
+	if b.Status.Phase != api.BackupPhaseSucceeded {
+		return nil, fmt.Errorf("backup (%s) has not succeeded", r.Backup)
+	}
+	if b.Spec.S3 == nil {
+		return nil, fmt.Errorf("backup (%s) is not saved to S3, the only storage etcd can be restored from", r.Backup)
+	}
+	return b.Spec.S3, nil
+}
+
+// failRestore records why the restore of the vault service failed as the Restored condition, and returns the error.
+func (v *Vaults) failRestore(vr *api.VaultService, err error) error {
+	c := api.NewCondition(api.VaultServiceRestored, v1.ConditionFalse, reasonRestoreFailed, err.Error())
+	if cerr := v.updateVaultCRCondition(vr, c); cerr != nil {
+		logrus.Errorf("failed to record the failed restore of the vault service (%s): %v", vr.Name, cerr)
+	}
+	return err
+}
+
+// completeRestore sets the Restored condition once vault sees the restored data.
+// vault reports itself as initialized as soon as it reads the restored data from etcd, even while sealed.
+func (v *Vaults) completeRestore(vr *api.VaultService) error {
+	c := api.NewCondition(api.VaultServiceRestored, v1.ConditionTrue, "", "vault sees the restored data")
+	return v.updateVaultCRCondition(vr, c)
+}
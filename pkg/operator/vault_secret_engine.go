@@ -0,0 +1,606 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+
+	vaultapi "github.com/hashicorp/vault/api"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/tools/cache"
+)
+
+// driftError is returned when a secret engine was changed in vault directly and the change is not corrected.
+type driftError struct {
+	drift []string
+}
+
+func (e driftError) Error() string {
+	return "secret engine drifted from its spec: " + strings.Join(e.drift, "; ")
+}
+
+func (v *Vaults) newVaultSecretEngineController() *crController {
+	source := cache.NewListWatchFromClient(
+		v.vaultsCRCli.VaultV1alpha1().RESTClient(),
+		api.VaultSecretEnginePlural,
+		v.namespace,
+		fields.Everything())
+	return newCRController(api.VaultSecretEngineKind, source, &api.VaultSecretEngine{}, v.syncVaultSecretEngine)
+}
+
+// syncVaultSecretEngine mounts the secret engine in the vault service it references, writes its config,
+// and records the result in its status. A secret engine being deleted is unmounted before its finalizer is removed.
+func (v *Vaults) syncVaultSecretEngine(obj interface{}) error {
+	se := obj.(*api.VaultSecretEngine).DeepCopy()
+	cli := v.vaultsCRCli.VaultV1alpha1().VaultSecretEngines(se.Namespace)
+
+	if se.DeletionTimestamp != nil {
+		if !api.HasFinalizer(se.Finalizers) {
+			return nil
+		}
+		err := v.unmountVaultSecretEngine(se)
+		if err != nil {
+			return v.updateVaultSecretEngineStatus(se, se.Status, err)
+		}
+		se.Finalizers = api.RemoveFinalizer(se.Finalizers)
+		_, err = cli.Update(se)
+		return err
+	}
+
+	if !api.HasFinalizer(se.Finalizers) {
+		se.Finalizers = append(se.Finalizers, api.VaultFinalizer)
+		var err error
+		se, err = cli.Update(se)
+		if err != nil {
+			return err
+		}
+	}
+	s, err := v.writeVaultSecretEngine(se)
+	err = v.updateVaultSecretEngineStatus(se, s, err)
+	if _, ok := err.(driftError); ok {
+		// Retrying doesn't help; the drift is checked again with the next resync.
+		return nil
+	}
+	return err
+}
+
+// writeVaultSecretEngine brings the secret engine in vault to its spec.
+// It returns the status of the secret engine in vault, which is updated as far as the sync succeeded.
+func (v *Vaults) writeVaultSecretEngine(se *api.VaultSecretEngine) (api.VaultSecretEngineStatus, error) {
+	s := se.Status
+	s.Drift = nil
+	err := validateSecretEngine(&se.Spec)
+	if err != nil {
+		return s, fmt.Errorf("invalid secret engine: %v", err)
+	}
+	path := se.Spec.GetPath()
+	if len(s.Path) != 0 && s.Path != path {
+		return s, fmt.Errorf("path cannot be changed from (%s) to (%s)", s.Path, path)
+	}
+
+	vr, err := v.getVaultService(se.Namespace, se.Spec.VaultService)
+	if err != nil {
+		return s, err
+	}
+	if se.Spec.Type == api.SecretEngineKVv2 && !vaultVersionAtLeast(vr.Spec.Version, 0, 10) {
+		return s, fmt.Errorf("secret engine of type (%s) needs vault 0.10 or later, vault service (%s) runs version (%s)",
+			se.Spec.Type, vr.Name, vr.Spec.Version)
+	}
+	vapi, err := v.newActiveVaultClient(vr)
+	if err != nil {
+		return s, err
+	}
+
+	mounts, err := vapi.Sys().ListMounts()
+	if err != nil {
+		return s, fmt.Errorf("list secret engines failed: %v", err)
+	}
+	m, ok := mounts[path+"/"]
+	if !ok {
+		if len(s.Path) != 0 && !se.Spec.CorrectDrift {
+			s.Drift = []string{"secret engine is disabled"}
+			return s, driftError{s.Drift}
+		}
+		err = mountSecretEngine(vapi, path, &se.Spec)
+		if err != nil {
+			return s, err
+		}
+		// Nothing written to the secret engine before survives it being disabled.
+		s = api.VaultSecretEngineStatus{
+			Path:            path,
+			DefaultLeaseTTL: se.Spec.DefaultLeaseTTL,
+			MaxLeaseTTL:     se.Spec.MaxLeaseTTL,
+		}
+	} else {
+		if m.Type != secretEngineMountType(se.Spec.Type) {
+			return s, fmt.Errorf("path (%s) is used by a secret engine of type (%s)", path, m.Type)
+		}
+		if len(s.Path) != 0 {
+			s.Drift = leaseTTLDrift(&m.Config, &s)
+		}
+		if len(s.Drift) == 0 || se.Spec.CorrectDrift {
+			s.Drift = nil
+			if !leaseTTLsMatch(&m.Config, se.Spec.DefaultLeaseTTL, se.Spec.MaxLeaseTTL) {
+				err = vapi.Sys().TuneMount(path, vaultapi.MountConfigInput{
+					DefaultLeaseTTL: tuneTTL(se.Spec.DefaultLeaseTTL),
+					MaxLeaseTTL:     tuneTTL(se.Spec.MaxLeaseTTL),
+				})
+				if err != nil {
+					return s, fmt.Errorf("tune secret engine (%s) failed: %v", path, err)
+				}
+			}
+			s.DefaultLeaseTTL = se.Spec.DefaultLeaseTTL
+			s.MaxLeaseTTL = se.Spec.MaxLeaseTTL
+		}
+		s.Path = path
+	}
+
+	switch se.Spec.Type {
+	case api.SecretEngineKVv2:
+		err = writeKVConfig(vapi, path, se.Spec.KV)
+	case api.SecretEnginePKI:
+		err = writePKIConfig(vapi, path, se.Spec.PKI, &s)
+	case api.SecretEngineTransit:
+		err = writeTransitConfig(vapi, path, se.Spec.Transit)
+	case api.SecretEngineDatabase:
+		err = v.writeDatabaseConfig(vapi, se.Namespace, path, se.Spec.Database, &s)
+	}
+	if err != nil {
+		return s, err
+	}
+	if len(s.Drift) != 0 {
+		return s, driftError{s.Drift}
+	}
+	return s, nil
+}
+
+// unmountVaultSecretEngine unmounts the secret engine from vault. There is nothing to unmount if the vault service is gone
+// or being deleted.
+func (v *Vaults) unmountVaultSecretEngine(se *api.VaultSecretEngine) error {
+	path := se.Status.Path
+	if len(path) == 0 {
+		// The secret engine was never mounted by the operator.
+		return nil
+	}
+	vr, err := v.getVaultServiceToCleanUp(se.Namespace, se.Spec.VaultService)
+	if err == errVaultServiceNotFound {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	vapi, err := v.newActiveVaultClient(vr)
+	if err != nil {
+		return err
+	}
+	err = vapi.Sys().Unmount(path)
+	if err != nil {
+		return fmt.Errorf("unmount secret engine (%s) failed: %v", path, err)
+	}
+	return nil
+}
+
+func validateSecretEngine(s *api.VaultSecretEngineSpec) error {
+	switch s.Type {
+	case api.SecretEngineKVv2, api.SecretEnginePKI, api.SecretEngineTransit, api.SecretEngineDatabase:
+	default:
+		return fmt.Errorf("unsupported type (%s)", s.Type)
+	}
+	if s.KV != nil && s.Type != api.SecretEngineKVv2 {
+		return fmt.Errorf("kv config is set for a secret engine of type (%s)", s.Type)
+	}
+	if s.PKI != nil && s.Type != api.SecretEnginePKI {
+		return fmt.Errorf("pki config is set for a secret engine of type (%s)", s.Type)
+	}
+	if s.Transit != nil && s.Type != api.SecretEngineTransit {
+		return fmt.Errorf("transit config is set for a secret engine of type (%s)", s.Type)
+	}
+	if s.Database != nil && s.Type != api.SecretEngineDatabase {
+		return fmt.Errorf("database config is set for a secret engine of type (%s)", s.Type)
+	}
+	if strings.Trim(s.GetPath(), "/") != s.GetPath() {
+		return fmt.Errorf("path (%s) must not start or end with a slash", s.GetPath())
+	}
+	if _, err := parseTTL(s.DefaultLeaseTTL); err != nil {
+		return fmt.Errorf("invalid default lease TTL: %v", err)
+	}
+	if _, err := parseTTL(s.MaxLeaseTTL); err != nil {
+		return fmt.Errorf("invalid max lease TTL: %v", err)
+	}
+
+	var names []string
+	switch {
+	case s.PKI != nil:
+		if s.PKI.RootCA != nil && len(s.PKI.RootCA.CommonName) == 0 {
+			return fmt.Errorf("root CA has no common name")
+		}
+		for _, r := range s.PKI.Roles {
+			names = append(names, r.Name)
+		}
+	case s.Transit != nil:
+		for _, k := range s.Transit.Keys {
+			names = append(names, k.Name)
+		}
+	case s.Database != nil:
+		var conns []string
+		for _, c := range s.Database.Connections {
+			if len(c.PluginName) == 0 || len(c.ConnectionURL) == 0 {
+				return fmt.Errorf("connection (%s) needs a plugin name and a connection URL", c.Name)
+			}
+			conns = append(conns, c.Name)
+		}
+		if err := validateNames("connection", conns); err != nil {
+			return err
+		}
+		for _, r := range s.Database.Roles {
+			if len(r.DBName) == 0 || len(r.CreationStatements) == 0 {
+				return fmt.Errorf("role (%s) needs a db name and creation statements", r.Name)
+			}
+			names = append(names, r.Name)
+		}
+	}
+	return validateNames("name", names)
+}
+
+// validateNames checks that the given names of roles, keys or connections are set and unique.
+func validateNames(kind string, names []string) error {
+	seen := map[string]bool{}
+	for _, n := range names {
+		if len(n) == 0 {
+			return fmt.Errorf("%s is empty", kind)
+		}
+		if seen[n] {
+			return fmt.Errorf("%s (%s) is specified more than once", kind, n)
+		}
+		seen[n] = true
+	}
+	return nil
+}
+
+// vaultVersionAtLeast returns false if the given vault version, such as "0.9.1-0", is older than major.minor.
+// A version that doesn't start with major.minor, such as a custom image tag, cannot be told apart and is taken as new enough.
+func vaultVersionAtLeast(version string, major, minor int) bool {
+	parts := strings.SplitN(strings.SplitN(version, "-", 2)[0], ".", 3)
+	if len(parts) < 2 {
+		return true
+	}
+	ma, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return true
+	}
+	mi, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return true
+	}
+	return ma > major || (ma == major && mi >= minor)
+}
+
+// secretEngineMountType returns the type of the vault mount of the given secret engine type.
+func secretEngineMountType(t api.SecretEngineType) string {
+	if t == api.SecretEngineKVv2 {
+		return "kv"
+	}
+	return string(t)
+}
+
+func mountSecretEngine(vapi *vaultapi.Client, path string, s *api.VaultSecretEngineSpec) error {
+	data := map[string]interface{}{
+		"type":        secretEngineMountType(s.Type),
+		"description": s.Description,
+		"config": map[string]interface{}{
+			"default_lease_ttl": s.DefaultLeaseTTL,
+			"max_lease_ttl":     s.MaxLeaseTTL,
+		},
+	}
+	if s.Type == api.SecretEngineKVv2 {
+		data["options"] = map[string]interface{}{"version": "2"}
+	}
+	// The vault API client doesn't support mount options yet.
+	_, err := vapi.Logical().Write("sys/mounts/"+path, data)
+	if err != nil {
+		return fmt.Errorf("mount secret engine (%s) failed: %v", path, err)
+	}
+	return nil
+}
+
+// parseTTL returns the given TTL, either a duration or a number of seconds, in seconds.
+// An empty TTL is the default TTL of vault and returns zero, the same as vault reports it.
+func parseTTL(ttl string) (int, error) {
+	if len(ttl) == 0 {
+		return 0, nil
+	}
+	if secs, err := strconv.Atoi(ttl); err == nil {
+		return secs, nil
+	}
+	d, err := time.ParseDuration(ttl)
+	if err != nil {
+		return 0, err
+	}
+	return int(d / time.Second), nil
+}
+
+// tuneTTL returns the value to tune a lease TTL of a secret engine to.
+// An empty value would leave the TTL unchanged, so the default TTL of vault is asked for explicitly.
+func tuneTTL(ttl string) string {
+	if len(ttl) == 0 {
+		return "system"
+	}
+	return ttl
+}
+
+// leaseTTLsMatch returns true if the lease TTLs of the mount in vault are the given TTLs.
+// The TTLs are validated before, so parse errors are ignored.
+func leaseTTLsMatch(c *vaultapi.MountConfigOutput, defaultTTL, maxTTL string) bool {
+	d, _ := parseTTL(defaultTTL)
+	m, _ := parseTTL(maxTTL)
+	return c.DefaultLeaseTTL == d && c.MaxLeaseTTL == m
+}
+
+// leaseTTLDrift returns the lease TTLs of the mount in vault that differ from the ones last set by the operator.
+func leaseTTLDrift(c *vaultapi.MountConfigOutput, s *api.VaultSecretEngineStatus) []string {
+	var drift []string
+	if d, _ := parseTTL(s.DefaultLeaseTTL); c.DefaultLeaseTTL != d {
+		drift = append(drift, fmt.Sprintf("default lease TTL is tuned to %ds", c.DefaultLeaseTTL))
+	}
+	if m, _ := parseTTL(s.MaxLeaseTTL); c.MaxLeaseTTL != m {
+		drift = append(drift, fmt.Sprintf("max lease TTL is tuned to %ds", c.MaxLeaseTTL))
+	}
+	return drift
+}
+
+func writeKVConfig(vapi *vaultapi.Client, path string, c *api.KVConfig) error {
+	if c == nil {
+		return nil
+	}
+	_, err := vapi.Logical().Write(path+"/config", map[string]interface{}{
+		"max_versions": c.MaxVersions,
+		"cas_required": c.CASRequired,
+	})
+	if err != nil {
+		return fmt.Errorf("write config of secret engine (%s) failed: %v", path, err)
+	}
+	return nil
+}
+
+func writePKIConfig(vapi *vaultapi.Client, path string, c *api.PKIConfig, s *api.VaultSecretEngineStatus) error {
+	if c == nil {
+		c = &api.PKIConfig{}
+	}
+	if c.RootCA != nil && !s.RootCAGenerated {
+		// The status may be lost or stale; a CA already in vault must not be replaced.
+		exists, err := pkiCAExists(vapi, path)
+		if err != nil {
+			return err
+		}
+		if exists {
+			s.RootCAGenerated = true
+		}
+	}
+	if c.RootCA != nil && !s.RootCAGenerated {
+		_, err := vapi.Logical().Write(path+"/root/generate/internal", map[string]interface{}{
+			"common_name": c.RootCA.CommonName,
+			"ttl":         c.RootCA.TTL,
+		})
+		if err != nil {
+			return fmt.Errorf("generate root CA of secret engine (%s) failed: %v", path, err)
+		}
+		s.RootCAGenerated = true
+	}
+	if len(c.IssuingCertificates) != 0 || len(c.CRLDistributionPoints) != 0 {
+		_, err := vapi.Logical().Write(path+"/config/urls", map[string]interface{}{
+			"issuing_certificates":    c.IssuingCertificates,
+			"crl_distribution_points": c.CRLDistributionPoints,
+		})
+		if err != nil {
+			return fmt.Errorf("write URLs of secret engine (%s) failed: %v", path, err)
+		}
+	}
+
+	var roles []vaultObject
+	for _, r := range c.Roles {
+		data := map[string]interface{}{}
+		for k, val := range r.Options {
+			data[k] = val
+		}
+		data["allowed_domains"] = r.AllowedDomains
+		data["allow_subdomains"] = r.AllowSubdomains
+		if len(r.MaxTTL) != 0 {
+			data["max_ttl"] = r.MaxTTL
+		}
+		roles = append(roles, vaultObject{r.Name, data})
+	}
+	names, err := writeVaultObjects(vapi, path+"/roles/", "role", roles)
+	if err != nil {
+		return err
+	}
+	err = deleteVaultObjects(vapi, path+"/roles/", "role", s.Roles, names)
+	if err != nil {
+		return err
+	}
+	s.Roles = names
+	return nil
+}
+
+// pkiCAExists returns true if the pki secret engine at the given path has a CA certificate.
+func pkiCAExists(vapi *vaultapi.Client, path string) (bool, error) {
+	secret, err := vapi.Logical().Read(path + "/cert/ca")
+	if err != nil {
+		return false, fmt.Errorf("read CA of secret engine (%s) failed: %v", path, err)
+	}
+	if secret == nil {
+		return false, nil
+	}
+	cert, _ := secret.Data["certificate"].(string)
+	return len(cert) != 0, nil
+}
+
+func writeTransitConfig(vapi *vaultapi.Client, path string, c *api.TransitConfig) error {
+	if c == nil {
+		return nil
+	}
+	var keys []vaultObject
+	for _, k := range c.Keys {
+		data := map[string]interface{}{}
+		if len(k.Type) != 0 {
+			data["type"] = k.Type
+		}
+		keys = append(keys, vaultObject{k.Name, data})
+	}
+	// Creating a key that exists already leaves it as is.
+	_, err := writeVaultObjects(vapi, path+"/keys/", "key", keys)
+	return err
+}
+
+func (v *Vaults) writeDatabaseConfig(vapi *vaultapi.Client, namespace, path string, c *api.DatabaseConfig, s *api.VaultSecretEngineStatus) error {
+	if c == nil {
+		c = &api.DatabaseConfig{}
+	}
+	var conns []vaultObject
+	for _, conn := range c.Connections {
+		data := map[string]interface{}{}
+		for k, val := range conn.Options {
+			data[k] = val
+		}
+		data["plugin_name"] = conn.PluginName
+		data["connection_url"] = conn.ConnectionURL
+		data["allowed_roles"] = conn.AllowedRoles
+		if conn.UsernameSecret != nil {
+			username, err := v.getSecretKey(namespace, conn.UsernameSecret)
+			if err != nil {
+				return fmt.Errorf("read username of connection (%s) failed: %v", conn.Name, err)
+			}
+			data["username"] = username
+		}
+		if conn.PasswordSecret != nil {
+			password, err := v.getSecretKey(namespace, conn.PasswordSecret)
+			if err != nil {
+				return fmt.Errorf("read password of connection (%s) failed: %v", conn.Name, err)
+			}
+			data["password"] = password
+		}
+		conns = append(conns, vaultObject{conn.Name, data})
+	}
+
+	var roles []vaultObject
+	for _, r := range c.Roles {
+		// Vault splits the statements at semicolons.
+		data := map[string]interface{}{
+			"db_name":               r.DBName,
+			"creation_statements":   strings.Join(r.CreationStatements, ";"),
+			"revocation_statements": strings.Join(r.RevocationStatements, ";"),
+		}
+		if len(r.DefaultTTL) != 0 {
+			data["default_ttl"] = r.DefaultTTL
+		}
+		if len(r.MaxTTL) != 0 {
+			data["max_ttl"] = r.MaxTTL
+		}
+		roles = append(roles, vaultObject{r.Name, data})
+	}
+
+	// Roles refer to connections, so connections are written first and deleted last.
+	connNames, err := writeVaultObjects(vapi, path+"/config/", "connection", conns)
+	if err != nil {
+		return err
+	}
+	roleNames, err := writeVaultObjects(vapi, path+"/roles/", "role", roles)
+	if err != nil {
+		return err
+	}
+	err = deleteVaultObjects(vapi, path+"/roles/", "role", s.Roles, roleNames)
+	if err != nil {
+		return err
+	}
+	s.Roles = roleNames
+	err = deleteVaultObjects(vapi, path+"/config/", "connection", s.Connections, connNames)
+	if err != nil {
+		return err
+	}
+	s.Connections = connNames
+	return nil
+}
+
+// vaultObject is a named object of a secret engine, such as a role, with the parameters to write to vault.
+type vaultObject struct {
+	name string
+	data map[string]interface{}
+}
+
+// writeVaultObjects writes the given objects under the given path prefix, and returns their names.
+func writeVaultObjects(vapi *vaultapi.Client, prefix, kind string, objs []vaultObject) ([]string, error) {
+	var names []string
+	for _, o := range objs {
+		_, err := vapi.Logical().Write(prefix+o.name, o.data)
+		if err != nil {
+			return nil, fmt.Errorf("write %s (%s%s) failed: %v", kind, prefix, o.name, err)
+		}
+		names = append(names, o.name)
+	}
+	return names, nil
+}
+
+// deleteVaultObjects deletes the objects of the old names that are not kept from under the given path prefix.
+func deleteVaultObjects(vapi *vaultapi.Client, prefix, kind string, old, keep []string) error {
+	kept := map[string]bool{}
+	for _, name := range keep {
+		kept[name] = true
+	}
+	for _, name := range old {
+		if kept[name] {
+			continue
+		}
+		_, err := vapi.Logical().Delete(prefix + name)
+		if err != nil {
+			return fmt.Errorf("delete %s (%s%s) failed: %v", kind, prefix, name, err)
+		}
+	}
+	return nil
+}
+
+// getSecretKey returns the value of the selected key of a secret in the given namespace.
+func (v *Vaults) getSecretKey(namespace string, ref *v1.SecretKeySelector) (string, error) {
+	// TODO: use secrets informer
+	secret, err := v.kubecli.CoreV1().Secrets(namespace).Get(ref.Name, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get secret (%s): %v", ref.Name, err)
+	}
+	val, ok := secret.Data[ref.Key]
+	if !ok {
+		return "", fmt.Errorf("secret (%s) has no key (%s)", ref.Name, ref.Key)
+	}
+	return string(val), nil
+}
+
+// updateVaultSecretEngineStatus records the given status and the result of the last sync in the status of the secret engine,
+// and returns the sync error so that the secret engine is synced again.
+func (v *Vaults) updateVaultSecretEngineStatus(se *api.VaultSecretEngine, s api.VaultSecretEngineStatus, syncErr error) error {
+	s.SyncStatus = newSyncStatus(syncErr)
+	if !reflect.DeepEqual(se.Status, s) {
+		se.Status = s
+		_, err := v.vaultsCRCli.VaultV1alpha1().VaultSecretEngines(se.Namespace).Update(se)
+		if err != nil {
+			return fmt.Errorf("failed to update status of VaultSecretEngine (%s): %v", se.Name, err)
+		}
+	}
+	return syncErr
+}
@@ -0,0 +1,37 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package operator
+
+import "testing"
+
+func TestVaultVersionAtLeast(t *testing.T) {
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{"0.9.1-0", false},
+		{"0.9.6", false},
+		{"0.10.0", true},
+		{"0.10.1-0", true},
+		{"1.0.0", true},
+		{"latest", true},
+	}
+
+	for _, tt := range tests {
+		if got := vaultVersionAtLeast(tt.version, 0, 10); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.version, got, tt.want)
+		}
+	}
+}
@@ -16,30 +16,42 @@ package operator
 
 import (
 	"context"
+	"crypto/tls"
+	"fmt"
 	"reflect"
 	"time"
 
 	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
 	"github.com/coreos/vault-operator/pkg/util/k8sutil"
-	"github.com/coreos/vault-operator/pkg/util/vaultutil"
 
-	vaultapi "github.com/hashicorp/vault/api"
 	"github.com/sirupsen/logrus"
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
 )
 
+// certExpiryCheckInterval is how often the expiry of the TLS certs of a vault service is checked.
+const certExpiryCheckInterval = time.Minute
+
 // monitorAndUpdateStatus monitors the vault service and replicas statuses, and
 // updates the status resource in the vault CR item.
 func (vs *Vaults) monitorAndUpdateStatus(ctx context.Context, vr *api.VaultService) {
-	var tlsConfig *vaultapi.TLSConfig
-
 	s := api.VaultServiceStatus{
 		Phase:       api.ClusterPhaseRunning,
 		ServiceName: vr.GetName(),
 		ClientPort:  k8sutil.VaultClientPort,
+		APIAddress:  k8sutil.VaultAPIAddress(vr),
+	}
+	// A restored vault service is only running once vault sees the restored data.
+	if vr.Spec.RestoreFrom != nil && vr.Status.Phase == api.ClusterPhaseInitial {
+		s.Phase = api.ClusterPhaseInitial
 	}
+	var lastCertCheck time.Time
+	reportedCerts := map[certFile]bool{}
+	registeredPlugins := map[string]bool{}
+	healthErrors := map[string]int{}
+	defer deleteCertExpiryMetrics(vr, reportedCerts)
 
 	for {
 		// Do not wait to update Phase ASAP.
@@ -58,20 +70,71 @@ func (vs *Vaults) monitorAndUpdateStatus(ctx context.Context, vr *api.VaultServi
 		case <-time.After(10 * time.Second):
 		}
 
-		if tlsConfig == nil {
-			var err error
-			tlsConfig, err = k8sutil.VaultTLSFromSecret(vs.kubecli, vr)
-			if err != nil {
-				logrus.Errorf("failed to read TLS config for vault client: %v", err)
-				continue
+		if time.Since(lastCertCheck) >= certExpiryCheckInterval {
+			if err := vs.syncCertExpiry(vr, reportedCerts); err != nil {
+				logrus.Errorf("failed to check TLS cert expiry for the vault service (%s): %v", vr.GetName(), err)
+			} else {
+				lastCertCheck = time.Now()
+			}
+		}
+
+		// The TLS config is re-read on every pass so that rotated client secrets are picked up.
+		tlsConfig, err := vs.getVaultTLSConfig(vr)
+		if err != nil {
+			logrus.Errorf("failed to read TLS config for vault client: %v", err)
+			continue
+		}
+		vs.updateLocalVaultCRStatus(ctx, vr, &s, tlsConfig, healthErrors)
+
+		if s.Phase == api.ClusterPhaseInitial && s.Initialized {
+			if err := vs.completeRestore(vr); err != nil {
+				logrus.Errorf("failed to complete the restore of the vault service (%s): %v", vr.GetName(), err)
+			} else {
+				logrus.Infof("vault service (%s) is restored", vr.GetName())
+				s.Phase = api.ClusterPhaseRunning
+			}
+		}
+
+		// Upgrades are checked on every pass, so that the soak time of the canary and the upgrade timeout are kept.
+		if vr.Spec.Upgrade.IsCanary() || vr.Status.Upgrade != nil {
+			if err := vs.syncUpgradeProgress(vr, &s, healthErrors); err != nil {
+				logrus.Errorf("failed to sync the upgrade of the vault service (%s): %v", vr.GetName(), err)
+			}
+		}
+
+		// The operator credential is refreshed first, so that the vault API calls below use a valid token.
+		if vr.Spec.OperatorAuth != nil && len(s.VaultStatus.Active) != 0 {
+			if err := vs.syncOperatorCredential(vr, s.VaultStatus.Active, tlsConfig); err != nil {
+				logrus.Errorf("failed to sync the operator credential for the vault service (%s): %v", vr.GetName(), err)
+			}
+		}
+
+		// Plugins can only be registered once vault is unsealed.
+		if len(vr.Spec.Plugins) != 0 && len(s.VaultStatus.Active) != 0 {
+			if err := vs.syncPlugins(vr, s.VaultStatus.Active, tlsConfig, registeredPlugins); err != nil {
+				logrus.Errorf("failed to register plugins for the vault service (%s): %v", vr.GetName(), err)
+			}
+		}
+
+		// Vault is audited by the active node, which is checked on every pass.
+		if vr.Spec.Audit != nil && len(s.VaultStatus.Active) != 0 {
+			if err := vs.syncAudit(vr, s.VaultStatus.Active, tlsConfig); err != nil {
+				logrus.Errorf("failed to sync audit devices for the vault service (%s): %v", vr.GetName(), err)
+			}
+		}
+
+		// Backups are taken from etcd, so they don't need vault to be unsealed.
+		if vr.Spec.Backup != nil {
+			if err := vs.syncScheduledBackups(vr); err != nil {
+				logrus.Errorf("failed to sync scheduled backups for the vault service (%s): %v", vr.GetName(), err)
 			}
 		}
-		vs.updateLocalVaultCRStatus(ctx, vr, &s, tlsConfig)
 	}
 }
 
 // updateLocalVaultCRStatus updates local vault CR status by querying each vault pod's API.
-func (vs *Vaults) updateLocalVaultCRStatus(ctx context.Context, vr *api.VaultService, s *api.VaultServiceStatus, tlsConfig *vaultapi.TLSConfig) {
+// healthErrors counts the health checks in a row that failed for each running vault pod.
+func (vs *Vaults) updateLocalVaultCRStatus(ctx context.Context, vr *api.VaultService, s *api.VaultServiceStatus, tlsConfig *tls.Config, healthErrors map[string]int) {
 	name, namespace := vr.Name, vr.Namespace
 	sel := k8sutil.LabelsForVault(name)
 	// TODO: handle upgrades when pods from two replicaset can co-exist :(
@@ -89,13 +152,15 @@ func (vs *Vaults) updateLocalVaultCRStatus(ctx context.Context, vr *api.VaultSer
 	// If it can't talk to any vault pod, we are not going to change the status.
 	changed := false
 
+	running := map[string]bool{}
 	for _, p := range pods.Items {
 		// If a pod is Terminating, it is still Running but has no IP.
 		if p.Status.Phase != v1.PodRunning || p.DeletionTimestamp != nil {
 			continue
 		}
+		running[p.GetName()] = true
 
-		vapi, err := vaultutil.NewClient(k8sutil.PodDNSName(p), "8200", tlsConfig)
+		vapi, err := k8sutil.NewVaultPodClient(&p, tlsConfig)
 		if err != nil {
 			logrus.Errorf("failed to update vault replica status: failed creating client for the vault pod (%s/%s): %v", namespace, p.GetName(), err)
 			continue
@@ -103,23 +168,35 @@ func (vs *Vaults) updateLocalVaultCRStatus(ctx context.Context, vr *api.VaultSer
 
 		hr, err := vapi.Sys().Health()
 		if err != nil {
+			healthErrors[p.GetName()]++
 			logrus.Errorf("failed to update vault replica status: failed requesting health info for the vault pod (%s/%s): %v", namespace, p.GetName(), err)
+			// Take the pod out of the vault services until it answers again.
+			if err := vs.syncVaultActiveLabel(&p, ""); err != nil {
+				logrus.Errorf("failed to update vault replica status: %v", err)
+			}
 			continue
 		}
 
 		changed = true
+		delete(healthErrors, p.GetName())
 
-		if k8sutil.IsVaultVersionMatch(p.Spec, vr.Spec) {
+		// The canary of an upgrade is not a node of the vault deployment.
+		if k8sutil.IsVaultVersionMatch(p.Spec, vr.Spec) && p.Labels[k8sutil.VaultCanaryLabel] != "true" {
 			updated = append(updated, p.GetName())
 		}
 
 		// TODO: add to vaultutil?
-		if hr.Initialized && !hr.Sealed && !hr.Standby {
+		isActive := hr.Initialized && !hr.Sealed && !hr.Standby
+		if isActive {
 			s.VaultStatus.Active = p.GetName()
 		}
-		if hr.Initialized && !hr.Sealed && hr.Standby {
+		isStandby := hr.Initialized && !hr.Sealed && hr.Standby
+		if isStandby {
 			standByNodes = append(standByNodes, p.GetName())
 		}
+		if err := vs.syncVaultActiveLabel(&p, vaultActiveLabelValue(isActive, isStandby)); err != nil {
+			logrus.Errorf("failed to update vault replica status: %v", err)
+		}
 		if hr.Sealed {
 			sealNodes = append(sealNodes, p.GetName())
 		}
@@ -128,6 +205,12 @@ func (vs *Vaults) updateLocalVaultCRStatus(ctx context.Context, vr *api.VaultSer
 		}
 	}
 
+	for name := range healthErrors {
+		if !running[name] {
+			delete(healthErrors, name)
+		}
+	}
+
 	if !changed {
 		return
 	}
@@ -138,12 +221,46 @@ func (vs *Vaults) updateLocalVaultCRStatus(ctx context.Context, vr *api.VaultSer
 	s.UpdatedNodes = updated
 }
 
+// vaultActiveLabelValue returns the value of the active label for a vault node,
+// or "" if the node is neither active nor an unsealed standby.
+func vaultActiveLabelValue(active, standby bool) string {
+	switch {
+	case active:
+		return "true"
+	case standby:
+		return "false"
+	default:
+		return ""
+	}
+}
+
+// syncVaultActiveLabel sets the active label of the vault pod to the given value, or removes it if the value is empty,
+// so that the vault services follow the active and standby nodes on leader changes.
+func (vs *Vaults) syncVaultActiveLabel(p *v1.Pod, val string) error {
+	cur, ok := p.Labels[k8sutil.VaultActiveLabel]
+	if cur == val && ok == (val != "") {
+		return nil
+	}
+	patch := fmt.Sprintf(`{"metadata":{"labels":{%q:%q}}}`, k8sutil.VaultActiveLabel, val)
+	if val == "" {
+		patch = fmt.Sprintf(`{"metadata":{"labels":{%q:null}}}`, k8sutil.VaultActiveLabel)
+	}
+	_, err := vs.kubecli.CoreV1().Pods(p.Namespace).Patch(p.Name, types.MergePatchType, []byte(patch))
+	if err != nil {
+		return fmt.Errorf("failed to set label (%s=%s) on vault pod (%s/%s): %v", k8sutil.VaultActiveLabel, val, p.Namespace, p.Name, err)
+	}
+	return nil
+}
+
 // updateVaultCRStatus updates the status field of the Vault CR.
 func (vs *Vaults) updateVaultCRStatus(ctx context.Context, name, namespace string, status api.VaultServiceStatus) (*api.VaultService, error) {
 	vault, err := vs.vaultsCRCli.VaultV1alpha1().VaultServices(namespace).Get(name, metav1.GetOptions{})
 	if err != nil {
 		return nil, err
 	}
+	// Conditions and the upgrade status are maintained separately via updateVaultCRCondition and updateUpgradeStatus.
+	status.Conditions = vault.Status.Conditions
+	status.Upgrade = vault.Status.Upgrade
 	if reflect.DeepEqual(vault.Status, status) {
 		return vault, nil
 	}
@@ -151,3 +268,19 @@ func (vs *Vaults) updateVaultCRStatus(ctx context.Context, name, namespace strin
 	_, err = vs.vaultsCRCli.VaultV1alpha1().VaultServices(namespace).Update(vault)
 	return vault, err
 }
+
+// updateVaultCRCondition sets the given condition in the status of the Vault CR.
+func (vs *Vaults) updateVaultCRCondition(vr *api.VaultService, c api.VaultServiceCondition) error {
+	vault, err := vs.vaultsCRCli.VaultV1alpha1().VaultServices(vr.Namespace).Get(vr.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to update condition (%s): %v", c.Type, err)
+	}
+	if !vault.Status.SetCondition(c) {
+		return nil
+	}
+	_, err = vs.vaultsCRCli.VaultV1alpha1().VaultServices(vr.Namespace).Update(vault)
+	if err != nil {
+		return fmt.Errorf("failed to update condition (%s): %v", c.Type, err)
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cronutil
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNext(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		from string
+		want string
+	}{{
+		name: "step",
+		spec: "*/15 * * * *",
+		from: "2018-01-01T10:07:30Z",
+		want: "2018-01-01T10:15:00Z",
+	}, {
+		name: "step of a range",
+		spec: "10-50/20 * * * *",
+		from: "2018-01-01T10:31:00Z",
+		want: "2018-01-01T10:50:00Z",
+	}, {
+		name: "hour step rolls over the day",
+		spec: "0 */6 * * *",
+		from: "2018-01-01T18:00:00Z",
+		want: "2018-01-02T00:00:00Z",
+	}, {
+		name: "range",
+		spec: "30 9-17 * * *",
+		from: "2018-01-01T17:30:00Z",
+		want: "2018-01-02T09:30:00Z",
+	}, {
+		name: "list",
+		spec: "0 1,13 * * *",
+		from: "2018-01-01T02:00:00Z",
+		want: "2018-01-01T13:00:00Z",
+	}, {
+		name: "weekdays",
+		spec: "0 0 * * 1-5",
+		// Friday
+		from: "2018-01-05T12:00:00Z",
+		want: "2018-01-08T00:00:00Z",
+	}, {
+		name: "day of month or day of week",
+		spec: "0 0 10 * 1",
+		// Tuesday; Wednesday the 10th matches the day of month only.
+		from: "2018-01-09T00:00:00Z",
+		want: "2018-01-10T00:00:00Z",
+	}, {
+		name: "day of week matches before the day of month",
+		spec: "0 0 28 * 1",
+		from: "2018-01-16T00:00:00Z",
+		want: "2018-01-22T00:00:00Z",
+	}, {
+		name: "day of month with any day of week",
+		spec: "0 0 28 * *",
+		from: "2018-01-16T00:00:00Z",
+		want: "2018-01-28T00:00:00Z",
+	}, {
+		name: "month rollover",
+		spec: "0 0 1 * *",
+		from: "2018-01-31T23:59:00Z",
+		want: "2018-02-01T00:00:00Z",
+	}, {
+		name: "year rollover",
+		spec: "@yearly",
+		from: "2018-12-31T23:59:00Z",
+		want: "2019-01-01T00:00:00Z",
+	}, {
+		name: "day of month missing in some months",
+		spec: "0 0 31 * *",
+		from: "2018-04-01T00:00:00Z",
+		want: "2018-05-31T00:00:00Z",
+	}, {
+		name: "leap day",
+		spec: "0 0 29 2 *",
+		from: "2018-03-01T00:00:00Z",
+		want: "2020-02-29T00:00:00Z",
+	}, {
+		name: "7 is Sunday",
+		spec: "0 0 * * 7",
+		// Monday
+		from: "2018-01-01T00:00:00Z",
+		want: "2018-01-07T00:00:00Z",
+	}, {
+		name: "never",
+		spec: "0 0 30 2 *",
+		from: "2018-01-01T00:00:00Z",
+	}}
+
+	for _, tt := range tests {
+		s, err := Parse(tt.spec)
+		if err != nil {
+			t.Errorf("%s: failed to parse (%s): %v", tt.name, tt.spec, err)
+			continue
+		}
+		from, err := time.Parse(time.RFC3339, tt.from)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var want time.Time
+		if len(tt.want) != 0 {
+			if want, err = time.Parse(time.RFC3339, tt.want); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if got := s.Next(from); !got.Equal(want) {
+			t.Errorf("%s: next of (%s) after %s = %s, want %s", tt.name, tt.spec, tt.from, got, want)
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+	}{
+		{"too few fields", "0 0 * *"},
+		{"out of range", "60 * * * *"},
+		{"day of week out of range", "0 0 * * 8"},
+		{"inverted range", "0 17-9 * * *"},
+		{"zero step", "*/0 * * * *"},
+		{"not a number", "a * * * *"},
+		{"unknown macro", "@never"},
+	}
+
+	for _, tt := range tests {
+		if _, err := Parse(tt.spec); err == nil {
+			t.Errorf("%s: expect (%s) to be invalid", tt.name, tt.spec)
+		}
+	}
+}
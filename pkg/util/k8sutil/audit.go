@@ -0,0 +1,116 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sutil
+
+import (
+	"fmt"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+	"github.com/coreos/vault-operator/pkg/util/vaultutil"
+
+	"k8s.io/api/core/v1"
+)
+
+const (
+	vaultAuditVolName      = "vault-audit"
+	vaultAuditLinkVolName  = "vault-audit-link"
+	auditLinkContainerName = "link-audit-log"
+	envPodName             = "POD_NAME"
+)
+
+// ValidateAudit checks that the audit devices can be set up in vault pods.
+func ValidateAudit(a *api.AuditPolicy, p *api.PodPolicy) error {
+	if a == nil {
+		return nil
+	}
+	if a.Socket != nil {
+		if len(a.Socket.Address) == 0 {
+			return fmt.Errorf("socket audit device has no address")
+		}
+		if !presentIn(a.Socket.SocketType, "", "tcp", "udp") {
+			return fmt.Errorf("socket audit device has an invalid socket type (%s)", a.Socket.SocketType)
+		}
+	}
+	if a.File == nil || a.File.Sidecar == nil {
+		return nil
+	}
+	name := a.File.Sidecar.Name
+	if len(name) == 0 {
+		return fmt.Errorf("audit log sidecar has no name")
+	}
+	if presentIn(name, vaultContainerName, exporterContainerName, auditLinkContainerName) {
+		return fmt.Errorf("audit log sidecar name (%s) is reserved", name)
+	}
+	if p != nil {
+		for _, c := range p.ExtraContainers {
+			if c.Name == name {
+				return fmt.Errorf("audit log sidecar name (%s) is used by an extra container", name)
+			}
+		}
+	}
+	return nil
+}
+
+// configVaultAudit adds the volume the file audit device writes to, and the audit log sidecar, to vault pods.
+// The config of the file audit device is shared by all vault nodes, so vault writes to a link that
+// an init container points at the audit log of the pod, "<pod-name>/audit.log" on the audit log volume.
+// Vault pods sharing a volume therefore never write to the same file.
+func configVaultAudit(pt *v1.PodTemplateSpec, v *api.VaultService) {
+	if v.Spec.Audit == nil || v.Spec.Audit.File == nil {
+		return
+	}
+	f := v.Spec.Audit.File
+	src := v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}
+	if len(f.PersistentVolumeClaimName) != 0 {
+		src = v1.VolumeSource{PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{ClaimName: f.PersistentVolumeClaimName}}
+	}
+	pt.Spec.Volumes = append(pt.Spec.Volumes, v1.Volume{
+		Name:         vaultAuditVolName,
+		VolumeSource: src,
+	}, v1.Volume{
+		Name:         vaultAuditLinkVolName,
+		VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}},
+	})
+	mount := v1.VolumeMount{
+		Name:      vaultAuditVolName,
+		MountPath: vaultutil.VaultAuditDir,
+	}
+	linkMount := v1.VolumeMount{
+		Name:      vaultAuditLinkVolName,
+		MountPath: vaultutil.VaultAuditLinkDir,
+	}
+	podDir := fmt.Sprintf("%s/$%s", vaultutil.VaultAuditDir, envPodName)
+	pt.Spec.InitContainers = append(pt.Spec.InitContainers, v1.Container{
+		Name:  auditLinkContainerName,
+		Image: VaultImage(v.Spec),
+		Command: []string{"/bin/sh", "-c",
+			fmt.Sprintf("mkdir -p %s && ln -sf %s/audit.log %s", podDir, podDir, vaultutil.VaultAuditLogPath)},
+		Env: []v1.EnvVar{{
+			Name: envPodName,
+			ValueFrom: &v1.EnvVarSource{
+				FieldRef: &v1.ObjectFieldSelector{FieldPath: "metadata.name"},
+			},
+		}},
+		VolumeMounts: []v1.VolumeMount{mount, linkMount},
+	})
+	pt.Spec.Containers[0].VolumeMounts = append(pt.Spec.Containers[0].VolumeMounts, mount, linkMount)
+
+	if f.Sidecar != nil {
+		c := *f.Sidecar.DeepCopy()
+		mount.ReadOnly = true
+		c.VolumeMounts = append(c.VolumeMounts, mount)
+		pt.Spec.Containers = append(pt.Spec.Containers, c)
+	}
+}
@@ -0,0 +1,334 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sutil
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"time"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+	"github.com/coreos/vault-operator/pkg/util/cronutil"
+
+	etcdCRAPI "github.com/coreos/etcd-operator/pkg/apis/etcd/v1beta2"
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	snapshotVolName   = "snapshot"
+	snapshotDir       = "/snapshot"
+	etcdClientTLSVol  = "etcd-client-tls"
+	etcdClientTLSDir  = "/etc/etcd-client-tls"
+	snapshotJobLimit  = 2
+	etcdImageRepo     = "quay.io/coreos/etcd"
+	snapshotContainer = "etcd-snapshot"
+)
+
+// snapshotScript saves a snapshot of etcd to $SNAPSHOT, and writes its status,
+// e.g. {"hash":123,"revision":10,"totalKey":5,"totalSize":20480}, to the termination message.
+// The snapshot is first written next to its path, so that a failed snapshot doesn't replace an older one.
+const snapshotScript = `set -e
+export ETCDCTL_API=3
+mkdir -p "$(dirname "$SNAPSHOT")"
+etcdctl --endpoints="$ENDPOINT" --cacert=` + etcdClientTLSDir + `/etcd-client-ca.crt --cert=` + etcdClientTLSDir + `/etcd-client.crt --key=` + etcdClientTLSDir + `/etcd-client.key snapshot save "$SNAPSHOT.part"
+mv "$SNAPSHOT.part" "$SNAPSHOT"
+etcdctl snapshot status "$SNAPSHOT" -w json > /dev/termination-log
+`
+
+// ScheduledBackupLabel is the label of the VaultBackups taken on the schedule of a vault service.
+const ScheduledBackupLabel = "vault-scheduled-backup"
+
+// ValidateBackupPolicy checks the schedule, targets and retention of the backups of a vault service.
+func ValidateBackupPolicy(b *api.BackupPolicy) error {
+	if b == nil {
+		return nil
+	}
+	sched, err := cronutil.Parse(b.Schedule)
+	if err != nil {
+		return err
+	}
+	if sched.Next(time.Now()).IsZero() {
+		return fmt.Errorf("cron schedule (%s) never fires", b.Schedule)
+	}
+	if (b.S3 == nil) == (b.PVC == nil) {
+		return fmt.Errorf("exactly one of s3 and pvc must be set")
+	}
+	if b.S3 != nil && (len(b.S3.Path) == 0 || len(b.S3.AWSSecret) == 0) {
+		return fmt.Errorf("s3 path and awsSecret must be set")
+	}
+	if b.PVC != nil && len(b.PVC.ClaimName) == 0 {
+		return fmt.Errorf("pvc claimName must be set")
+	}
+	if r := b.Retention; r != nil {
+		if r.MaxBackups < 0 {
+			return fmt.Errorf("retention maxBackups (%d) must not be negative", r.MaxBackups)
+		}
+		if r.MaxFailedBackups < 0 {
+			return fmt.Errorf("retention maxFailedBackups (%d) must not be negative", r.MaxFailedBackups)
+		}
+		if len(r.MaxAge) != 0 {
+			if _, err := time.ParseDuration(r.MaxAge); err != nil {
+				return fmt.Errorf("invalid retention maxAge (%s): %v", r.MaxAge, err)
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateRestoreSource checks that exactly one backup of a vault service to restore from is given.
+func ValidateRestoreSource(r *api.RestoreSource) error {
+	if r == nil {
+		return nil
+	}
+	if (len(r.Backup) == 0) == (r.S3 == nil) {
+		return fmt.Errorf("exactly one of backup and s3 must be set")
+	}
+	if r.S3 != nil && (len(r.S3.Path) == 0 || len(r.S3.AWSSecret) == 0) {
+		return fmt.Errorf("s3 path and awsSecret must be set")
+	}
+	return nil
+}
+
+// NewScheduledBackup returns the VaultBackup taken at the given time on the schedule of the given vault.
+// Its snapshot is deleted with it, when it is pruned.
+func NewScheduledBackup(v *api.VaultService, t time.Time) *api.VaultBackup {
+	p := v.Spec.Backup
+	name := fmt.Sprintf("%s-%s", v.Name, t.UTC().Format("20060102-150405"))
+	b := &api.VaultBackup{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: v.Namespace,
+			Labels:    LabelsForVault(v.Name),
+		},
+		Spec: api.VaultBackupSpec{
+			VaultService:   v.Name,
+			DeleteSnapshot: true,
+		},
+	}
+	b.Labels[ScheduledBackupLabel] = "true"
+	if p.S3 != nil {
+		b.Spec.S3 = &api.S3BackupTarget{
+			Path:      path.Join(p.S3.Path, name+".backup"),
+			AWSSecret: p.S3.AWSSecret,
+			Endpoint:  p.S3.Endpoint,
+		}
+	} else {
+		b.Spec.PVC = &api.PVCBackupTarget{
+			ClaimName: p.PVC.ClaimName,
+			Path:      filepath.Join(p.PVC.Path, name+".db"),
+		}
+	}
+	return b
+}
+
+// BackupResourceName returns the name of the EtcdBackup or the job that takes the snapshot of the given vault backup.
+func BackupResourceName(backupName string) string {
+	return backupName + "-vault-backup"
+}
+
+// labelsForBackupJob returns the labels of the pods of the snapshot jobs of the given vault name.
+func labelsForBackupJob(vaultName string) map[string]string {
+	return map[string]string{"app": "vault-backup", vaultClusterLabel: vaultName}
+}
+
+// AsBackupOwner returns an owner reference set as the vault backup CR
+func AsBackupOwner(b *api.VaultBackup) metav1.OwnerReference {
+	trueVar := true
+	return metav1.OwnerReference{
+		APIVersion: api.SchemeGroupVersion.String(),
+		Kind:       api.VaultBackupKind,
+		Name:       b.Name,
+		UID:        b.UID,
+		Controller: &trueVar,
+	}
+}
+
+// NewEtcdBackup returns the EtcdBackup that saves a snapshot of the etcd cluster of the given vault to S3.
+func NewEtcdBackup(b *api.VaultBackup, v *api.VaultService) *etcdCRAPI.EtcdBackup {
+	eb := &etcdCRAPI.EtcdBackup{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       etcdCRAPI.EtcdBackupResourceKind,
+			APIVersion: etcdCRAPI.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      BackupResourceName(b.Name),
+			Namespace: b.Namespace,
+			Labels:    LabelsForVault(v.Name),
+		},
+		Spec: etcdCRAPI.BackupSpec{
+			EtcdEndpoints: []string{EtcdURLForVault(v.Name)},
+			StorageType:   etcdCRAPI.BackupStorageTypeS3,
+			BackupSource: etcdCRAPI.BackupSource{
+				S3: &etcdCRAPI.S3BackupSource{
+					Path:      b.Spec.S3.Path,
+					AWSSecret: b.Spec.S3.AWSSecret,
+					Endpoint:  b.Spec.S3.Endpoint,
+				},
+			},
+			ClientTLSSecret: EtcdClientTLSSecretName(v.Name),
+		},
+	}
+	AddOwnerRefToObject(eb, AsBackupOwner(b))
+	return eb
+}
+
+// EtcdRestoreName returns the name of the EtcdRestore that restores the etcd cluster of the given vault.
+// The etcd restore operator looks up the EtcdRestore by the name of the etcd cluster
+// when the seed member downloads the snapshot, so the EtcdRestore is named after the etcd cluster.
+func EtcdRestoreName(vaultName string) string {
+	return EtcdNameForVault(vaultName)
+}
+
+// NewEtcdRestore returns the EtcdRestore that replaces the etcd cluster of the given vault
+// with one restored from the snapshot on S3.
+func NewEtcdRestore(v *api.VaultService, src *api.S3BackupTarget) *etcdCRAPI.EtcdRestore {
+	er := &etcdCRAPI.EtcdRestore{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       etcdCRAPI.EtcdRestoreResourceKind,
+			APIVersion: etcdCRAPI.SchemeGroupVersion.String(),
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      EtcdRestoreName(v.Name),
+			Namespace: v.Namespace,
+			Labels:    LabelsForVault(v.Name),
+		},
+		Spec: etcdCRAPI.RestoreSpec{
+			BackupStorageType: etcdCRAPI.BackupStorageTypeS3,
+			RestoreSource: etcdCRAPI.RestoreSource{
+				S3: &etcdCRAPI.S3RestoreSource{
+					Path:      src.Path,
+					AWSSecret: src.AWSSecret,
+					Endpoint:  src.Endpoint,
+				},
+			},
+			// The restored etcd cluster is created from the one deployed for the vault, including its owner.
+			EtcdCluster: etcdCRAPI.EtcdClusterRef{Name: EtcdNameForVault(v.Name)},
+		},
+	}
+	AddOwnerRefToObject(er, AsOwner(v))
+	return er
+}
+
+// SnapshotDeleteJobName returns the name of the job that deletes the snapshot of the given vault backup from its volume.
+func SnapshotDeleteJobName(backupName string) string {
+	return BackupResourceName(backupName) + "-delete"
+}
+
+// NewEtcdSnapshotJob returns the job that saves a snapshot of the etcd cluster of the given vault
+// to the persistent volume claim of the given vault backup.
+// The job writes the status of the snapshot to the termination message of its pod.
+func NewEtcdSnapshotJob(b *api.VaultBackup, v *api.VaultService) *batchv1.Job {
+	backoffLimit := int32(snapshotJobLimit)
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      BackupResourceName(b.Name),
+			Namespace: b.Namespace,
+			Labels:    LabelsForVault(v.Name),
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labelsForBackupJob(v.Name),
+				},
+				Spec: v1.PodSpec{
+					RestartPolicy: v1.RestartPolicyNever,
+					Containers: []v1.Container{{
+						Name:    snapshotContainer,
+						Image:   etcdImageRepo + ":v" + etcdCRAPI.DefaultEtcdVersion,
+						Command: []string{"/bin/sh", "-c", snapshotScript},
+						// The output of etcdctl says why a snapshot failed.
+						TerminationMessagePolicy: v1.TerminationMessageFallbackToLogsOnError,
+						Env: []v1.EnvVar{
+							{Name: "ENDPOINT", Value: EtcdURLForVault(v.Name)},
+							{Name: "SNAPSHOT", Value: filepath.Join(snapshotDir, b.Spec.PVC.GetPath(b.Name))},
+						},
+						VolumeMounts: []v1.VolumeMount{
+							{Name: snapshotVolName, MountPath: snapshotDir},
+							{Name: etcdClientTLSVol, MountPath: etcdClientTLSDir, ReadOnly: true},
+						},
+					}},
+					Volumes: []v1.Volume{
+						snapshotVolume(b),
+						{
+							Name: etcdClientTLSVol,
+							VolumeSource: v1.VolumeSource{
+								Secret: &v1.SecretVolumeSource{
+									SecretName: EtcdClientTLSSecretName(v.Name),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	if v.Spec.Pod != nil {
+		job.Spec.Template.Spec.Containers[0].Resources = v.Spec.Pod.Resources
+	}
+	AddOwnerRefToObject(job, AsBackupOwner(b))
+	return job
+}
+
+// NewSnapshotDeleteJob returns the job that deletes the snapshot of the given vault backup from its volume.
+func NewSnapshotDeleteJob(b *api.VaultBackup) *batchv1.Job {
+	backoffLimit := int32(snapshotJobLimit)
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      SnapshotDeleteJobName(b.Name),
+			Namespace: b.Namespace,
+			Labels:    LabelsForVault(b.Spec.VaultService),
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: labelsForBackupJob(b.Spec.VaultService),
+				},
+				Spec: v1.PodSpec{
+					RestartPolicy: v1.RestartPolicyNever,
+					Containers: []v1.Container{{
+						Name:    snapshotContainer,
+						Image:   etcdImageRepo + ":v" + etcdCRAPI.DefaultEtcdVersion,
+						Command: []string{"/bin/sh", "-c", `rm -f "$SNAPSHOT" "$SNAPSHOT.part"`},
+						Env: []v1.EnvVar{
+							{Name: "SNAPSHOT", Value: filepath.Join(snapshotDir, b.Spec.PVC.GetPath(b.Name))},
+						},
+						VolumeMounts: []v1.VolumeMount{
+							{Name: snapshotVolName, MountPath: snapshotDir},
+						},
+					}},
+					Volumes: []v1.Volume{snapshotVolume(b)},
+				},
+			},
+		},
+	}
+	AddOwnerRefToObject(job, AsBackupOwner(b))
+	return job
+}
+
+func snapshotVolume(b *api.VaultBackup) v1.Volume {
+	return v1.Volume{
+		Name: snapshotVolName,
+		VolumeSource: v1.VolumeSource{
+			PersistentVolumeClaim: &v1.PersistentVolumeClaimVolumeSource{
+				ClaimName: b.Spec.PVC.ClaimName,
+			},
+		},
+	}
+}
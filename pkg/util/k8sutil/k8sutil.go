@@ -15,12 +15,8 @@
 package k8sutil
 
 import (
-	"fmt"
-	"strings"
-
 	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
 
-	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -34,12 +30,6 @@ func CascadeDeleteBackground() *metav1.DeleteOptions {
 	}
 }
 
-// PodDNSName constructs the dns name on which a pod can be addressed
-func PodDNSName(p v1.Pod) string {
-	podIP := strings.Replace(p.Status.PodIP, ".", "-", -1)
-	return fmt.Sprintf("%s.%s.pod", podIP, p.Namespace)
-}
-
 // AddOwnerRefToObject appends the desired OwnerReference to the object
 func AddOwnerRefToObject(o metav1.Object, r metav1.OwnerReference) {
 	o.SetOwnerReferences(append(o.GetOwnerReferences(), r))
@@ -0,0 +1,180 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sutil
+
+import (
+	"fmt"
+	"reflect"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+
+	"k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	etcdClientPort = 2379
+	etcdPeerPort   = 2380
+)
+
+// EtcdNetworkPolicyName returns the name of the NetworkPolicy of the etcd pods for the given vault name
+func EtcdNetworkPolicyName(vaultName string) string {
+	return EtcdNameForVault(vaultName)
+}
+
+// SyncNetworkPolicies makes sure the NetworkPolicies of the vault and etcd pods match the network policy of the vault service.
+// If the vault service has no network policy, the NetworkPolicies are deleted.
+func SyncNetworkPolicies(kubecli kubernetes.Interface, v *api.VaultService) error {
+	np := v.Spec.NetworkPolicy
+	if np == nil {
+		err := deleteNetworkPolicies(kubecli, v)
+		if err != nil {
+			return fmt.Errorf("sync network policies failed: %v", err)
+		}
+		return nil
+	}
+
+	for _, p := range []*networkingv1.NetworkPolicy{vaultNetworkPolicy(v, np), etcdNetworkPolicy(v, np)} {
+		err := syncNetworkPolicy(kubecli, v, p)
+		if err != nil {
+			return fmt.Errorf("sync network policies failed: %v", err)
+		}
+	}
+	return nil
+}
+
+// vaultNetworkPolicy only allows clients to the vault client port, vault peers to the vault cluster port,
+// the vault operator to the vault client port, and scrapers to the prometheus port.
+func vaultNetworkPolicy(v *api.VaultService, np *api.NetworkPolicy) *networkingv1.NetworkPolicy {
+	vaultPods := &metav1.LabelSelector{MatchLabels: LabelsForVault(v.Name)}
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   v.Name,
+			Labels: LabelsForVault(v.Name),
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: *vaultPods,
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{
+				{
+					Ports: tcpPorts(VaultClientPort),
+					From:  peersOrAll(np.Clients),
+				},
+				{
+					Ports: tcpPorts(VaultClientPort),
+					From:  []networkingv1.NetworkPolicyPeer{{PodSelector: np.GetOperatorSelector()}},
+				},
+				{
+					Ports: tcpPorts(vaultClusterPort),
+					From:  []networkingv1.NetworkPolicyPeer{{PodSelector: vaultPods}},
+				},
+				{
+					Ports: tcpPorts(exporterPromPort),
+					From:  peersOrAll(np.Scrapers),
+				},
+			},
+		},
+	}
+}
+
+// etcdNetworkPolicy only allows vault pods, etcd members, the etcd operator, the etcd backup operator
+// and the snapshot jobs of vault backups to the etcd client port, and etcd members to the etcd peer port.
+func etcdNetworkPolicy(v *api.VaultService, np *api.NetworkPolicy) *networkingv1.NetworkPolicy {
+	etcdPods := &metav1.LabelSelector{MatchLabels: labelsForEtcd(v.Name)}
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   EtcdNetworkPolicyName(v.Name),
+			Labels: LabelsForVault(v.Name),
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: *etcdPods,
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{
+				{
+					Ports: tcpPorts(etcdClientPort),
+					From: []networkingv1.NetworkPolicyPeer{
+						{PodSelector: &metav1.LabelSelector{MatchLabels: LabelsForVault(v.Name)}},
+						{PodSelector: etcdPods},
+						{PodSelector: np.GetEtcdOperatorSelector()},
+						{PodSelector: np.GetEtcdBackupOperatorSelector()},
+						{PodSelector: &metav1.LabelSelector{MatchLabels: labelsForBackupJob(v.Name)}},
+					},
+				},
+				{
+					Ports: tcpPorts(etcdPeerPort),
+					From:  []networkingv1.NetworkPolicyPeer{{PodSelector: etcdPods}},
+				},
+			},
+		},
+	}
+}
+
+// peersOrAll returns the given peers, or nil to allow all sources if there are none.
+func peersOrAll(peers []networkingv1.NetworkPolicyPeer) []networkingv1.NetworkPolicyPeer {
+	if len(peers) == 0 {
+		return nil
+	}
+	return peers
+}
+
+func tcpPorts(ports ...int) []networkingv1.NetworkPolicyPort {
+	var nps []networkingv1.NetworkPolicyPort
+	for _, p := range ports {
+		proto := v1.ProtocolTCP
+		port := intstr.FromInt(p)
+		nps = append(nps, networkingv1.NetworkPolicyPort{Protocol: &proto, Port: &port})
+	}
+	return nps
+}
+
+func syncNetworkPolicy(kubecli kubernetes.Interface, v *api.VaultService, np *networkingv1.NetworkPolicy) error {
+	npCli := kubecli.NetworkingV1().NetworkPolicies(v.Namespace)
+	AddOwnerRefToObject(np, AsOwner(v))
+	_, err := npCli.Create(np)
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create network policy (%s): %v", np.Name, err)
+	}
+
+	cur, err := npCli.Get(np.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get network policy (%s): %v", np.Name, err)
+	}
+	if reflect.DeepEqual(cur.Spec, np.Spec) {
+		return nil
+	}
+	cur.Spec = np.Spec
+	_, err = npCli.Update(cur)
+	if err != nil {
+		return fmt.Errorf("failed to update network policy (%s): %v", np.Name, err)
+	}
+	return nil
+}
+
+func deleteNetworkPolicies(kubecli kubernetes.Interface, v *api.VaultService) error {
+	for _, name := range []string{v.Name, EtcdNetworkPolicyName(v.Name)} {
+		err := kubecli.NetworkingV1().NetworkPolicies(v.Namespace).Delete(name, nil)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete network policy (%s): %v", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sutil
+
+import (
+	"fmt"
+	"reflect"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+)
+
+// EtcdPDBName returns the name of the PodDisruptionBudget of the etcd pods for the given vault name
+func EtcdPDBName(vaultName string) string {
+	return EtcdNameForVault(vaultName)
+}
+
+// labelsForEtcd returns the labels etcd operator sets on the pods of the etcd cluster for the given vault name.
+func labelsForEtcd(vaultName string) map[string]string {
+	return map[string]string{"app": "etcd", "etcd_cluster": EtcdNameForVault(vaultName)}
+}
+
+// SyncVaultPDB makes sure the PodDisruptionBudget of the vault pods exists.
+// It keeps an unsealed vault node available during voluntary disruptions. Unsealed nodes, active or standby,
+// are the ready vault pods, so the budget selects them by their active label and leaves the sealed pods evictable.
+// The active node can be evicted once a standby node is unsealed to take over.
+func SyncVaultPDB(kubecli kubernetes.Interface, v *api.VaultService) error {
+	sel := &metav1.LabelSelector{
+		MatchLabels: LabelsForVault(v.Name),
+		MatchExpressions: []metav1.LabelSelectorRequirement{{
+			Key:      VaultActiveLabel,
+			Operator: metav1.LabelSelectorOpIn,
+			Values:   []string{"true", "false"},
+		}},
+	}
+	err := syncPDB(kubecli, v, v.Name, sel, 1)
+	if err != nil {
+		return fmt.Errorf("sync vault PDB failed: %v", err)
+	}
+	return nil
+}
+
+// SyncEtcdPDB makes sure the PodDisruptionBudget of the etcd pods exists.
+// It keeps a quorum of etcd members available during voluntary disruptions.
+func SyncEtcdPDB(kubecli kubernetes.Interface, v *api.VaultService) error {
+	sel := &metav1.LabelSelector{MatchLabels: labelsForEtcd(v.Name)}
+	err := syncPDB(kubecli, v, EtcdPDBName(v.Name), sel, etcdClusterSize/2+1)
+	if err != nil {
+		return fmt.Errorf("sync etcd PDB failed: %v", err)
+	}
+	return nil
+}
+
+func syncPDB(kubecli kubernetes.Interface, v *api.VaultService, name string, sel *metav1.LabelSelector, minAvailable int) error {
+	pdbCli := kubecli.PolicyV1beta1().PodDisruptionBudgets(v.Namespace)
+	pdb, err := pdbCli.Get(name, metav1.GetOptions{})
+	if err == nil {
+		if pdb.Spec.MinAvailable != nil && pdb.Spec.MinAvailable.IntValue() == minAvailable &&
+			reflect.DeepEqual(pdb.Spec.Selector, sel) {
+			return nil
+		}
+		// The spec of a PodDisruptionBudget cannot be updated. Replace it instead.
+		err = pdbCli.Delete(name, &metav1.DeleteOptions{Preconditions: &metav1.Preconditions{UID: &pdb.UID}})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete PDB (%s): %v", name, err)
+		}
+	} else if !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to get PDB (%s): %v", name, err)
+	}
+
+	ma := intstr.FromInt(minAvailable)
+	pdb = &policyv1beta1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: LabelsForVault(v.Name),
+		},
+		Spec: policyv1beta1.PodDisruptionBudgetSpec{
+			MinAvailable: &ma,
+			Selector:     sel,
+		},
+	}
+	AddOwnerRefToObject(pdb, AsOwner(v))
+	_, err = pdbCli.Create(pdb)
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create PDB (%s): %v", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sutil
+
+import (
+	"encoding/hex"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+	"github.com/coreos/vault-operator/pkg/util/vaultutil"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	vaultPluginVolName    = "vault-plugins"
+	pluginContainerPrefix = "install-plugin-"
+)
+
+// ValidatePlugins checks that the plugins can be installed into vault pods.
+func ValidatePlugins(plugins []api.Plugin) error {
+	names := map[string]bool{}
+	for _, p := range plugins {
+		if errs := validation.IsDNS1123Label(p.Name); len(errs) != 0 {
+			return fmt.Errorf("plugin name (%s) is invalid: %s", p.Name, strings.Join(errs, ", "))
+		}
+		if names[p.Name] {
+			return fmt.Errorf("plugin name (%s) is used more than once", p.Name)
+		}
+		names[p.Name] = true
+		if len(p.Image) == 0 || len(p.Path) == 0 {
+			return fmt.Errorf("plugin (%s) must specify both image and path", p.Name)
+		}
+		sum, err := hex.DecodeString(p.SHA256)
+		if err != nil || len(sum) != 32 {
+			return fmt.Errorf("plugin (%s) has an invalid sha256 (%s)", p.Name, p.SHA256)
+		}
+	}
+	return nil
+}
+
+// configVaultPlugins installs the plugin binaries into a volume shared with the vault container,
+// by copying each of them out of its image with an init container.
+func configVaultPlugins(pt *v1.PodTemplateSpec, v *api.VaultService) {
+	if len(v.Spec.Plugins) == 0 {
+		return
+	}
+	pt.Spec.Volumes = append(pt.Spec.Volumes, v1.Volume{
+		Name:         vaultPluginVolName,
+		VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}},
+	})
+	mount := v1.VolumeMount{
+		Name:      vaultPluginVolName,
+		MountPath: vaultutil.VaultPluginDir,
+	}
+	for _, p := range v.Spec.Plugins {
+		pt.Spec.InitContainers = append(pt.Spec.InitContainers, v1.Container{
+			Name:         pluginContainerPrefix + p.Name,
+			Image:        p.Image,
+			Command:      []string{"cp", p.Path, filepath.Join(vaultutil.VaultPluginDir, p.Name)},
+			VolumeMounts: []v1.VolumeMount{mount},
+		})
+	}
+	mount.ReadOnly = true
+	pt.Spec.Containers[0].VolumeMounts = append(pt.Spec.Containers[0].VolumeMounts, mount)
+}
@@ -0,0 +1,106 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sutil
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+	"github.com/coreos/vault-operator/pkg/util/vaultutil"
+
+	"k8s.io/api/core/v1"
+)
+
+// ValidatePodPolicy checks that the extra containers, volumes and env vars of the pod policy
+// do not collide with the ones the vault operator sets up in vault pods.
+func ValidatePodPolicy(p *api.PodPolicy) error {
+	if p == nil {
+		return nil
+	}
+
+	for _, l := range []string{VaultActiveLabel, VaultCanaryLabel} {
+		if _, ok := p.Labels[l]; ok {
+			return fmt.Errorf("label (%s) is reserved", l)
+		}
+	}
+
+	reservedVolumes := []string{vaultConfigVolName, vaultTLSAssetVolume, vaultPluginVolName, vaultAuditVolName, vaultAuditLinkVolName}
+	for _, vol := range p.ExtraVolumes {
+		if presentIn(vol.Name, reservedVolumes...) {
+			return fmt.Errorf("volume name (%s) is reserved", vol.Name)
+		}
+	}
+	reservedDirs := []string{filepath.Dir(VaultConfigPath), vaultutil.VaultTLSAssetDir, vaultutil.VaultPluginDir, vaultutil.VaultAuditDir, vaultutil.VaultAuditLinkDir}
+	for _, m := range p.VolumeMounts {
+		if presentIn(m.Name, reservedVolumes...) {
+			return fmt.Errorf("volume mount (%s) uses a reserved volume name", m.MountPath)
+		}
+		for _, dir := range reservedDirs {
+			if isSubPath(m.MountPath, dir) || isSubPath(dir, m.MountPath) {
+				return fmt.Errorf("volume mount (%s) overlaps with the reserved directory (%s)", m.MountPath, dir)
+			}
+		}
+	}
+
+	reservedContainers := []string{vaultContainerName, exporterContainerName, auditLinkContainerName}
+	names := map[string]bool{}
+	for _, c := range append(append([]v1.Container{}, p.ExtraContainers...), p.InitContainers...) {
+		if presentIn(c.Name, reservedContainers...) || strings.HasPrefix(c.Name, pluginContainerPrefix) {
+			return fmt.Errorf("container name (%s) is reserved", c.Name)
+		}
+		if names[c.Name] {
+			return fmt.Errorf("container name (%s) is used more than once", c.Name)
+		}
+		names[c.Name] = true
+	}
+
+	for _, e := range p.Env {
+		if presentIn(e.Name, evnVaultRedirectAddr, evnVaultClusterAddr, envPodIP) {
+			return fmt.Errorf("env var (%s) is reserved", e.Name)
+		}
+	}
+	return nil
+}
+
+// applyExtraPodSpec adds the extra containers, volumes and env vars of the pod policy to the vault pod template.
+// It must be called after all the volumes of the vault operator are set up.
+func applyExtraPodSpec(pt *v1.PodTemplateSpec, p *api.PodPolicy) {
+	if p == nil {
+		return
+	}
+	s := &pt.Spec
+	s.Volumes = append(s.Volumes, p.ExtraVolumes...)
+	s.Containers[0].VolumeMounts = append(s.Containers[0].VolumeMounts, p.VolumeMounts...)
+	s.Containers[0].Env = append(s.Containers[0].Env, p.Env...)
+	s.Containers = append(s.Containers, p.ExtraContainers...)
+	s.InitContainers = append(s.InitContainers, p.InitContainers...)
+}
+
+func presentIn(a string, list ...string) bool {
+	for _, l := range list {
+		if a == l {
+			return true
+		}
+	}
+	return false
+}
+
+// isSubPath returns true if path is dir or a path under dir.
+func isSubPath(path, dir string) bool {
+	path, dir = filepath.Clean(path), filepath.Clean(dir)
+	return path == dir || dir == "/" || strings.HasPrefix(path, dir+"/")
+}
@@ -0,0 +1,134 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sutil
+
+import (
+	"path/filepath"
+	"testing"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+	"github.com/coreos/vault-operator/pkg/util/vaultutil"
+
+	"k8s.io/api/core/v1"
+)
+
+func TestValidatePodPolicy(t *testing.T) {
+	tests := []struct {
+		name    string
+		pod     *api.PodPolicy
+		wantErr bool
+	}{{
+		name: "no pod policy",
+	}, {
+		name: "valid extras",
+		pod: &api.PodPolicy{
+			ExtraContainers: []v1.Container{{Name: "log-shipper"}},
+			InitContainers:  []v1.Container{{Name: "fetch-certs"}},
+			ExtraVolumes:    []v1.Volume{{Name: "certs"}},
+			VolumeMounts:    []v1.VolumeMount{{Name: "certs", MountPath: "/etc/certs"}},
+			Env:             []v1.EnvVar{{Name: "VAULT_LOG_LEVEL", Value: "debug"}},
+			Labels:          map[string]string{"team": "security"},
+		},
+	}, {
+		name:    "reserved label",
+		pod:     &api.PodPolicy{Labels: map[string]string{VaultActiveLabel: "true"}},
+		wantErr: true,
+	}, {
+		name:    "reserved canary label",
+		pod:     &api.PodPolicy{Labels: map[string]string{VaultCanaryLabel: "true"}},
+		wantErr: true,
+	}, {
+		name:    "reserved volume",
+		pod:     &api.PodPolicy{ExtraVolumes: []v1.Volume{{Name: vaultConfigVolName}}},
+		wantErr: true,
+	}, {
+		name:    "mount of a reserved volume",
+		pod:     &api.PodPolicy{VolumeMounts: []v1.VolumeMount{{Name: vaultTLSAssetVolume, MountPath: "/tmp/tls"}}},
+		wantErr: true,
+	}, {
+		name:    "mount over a reserved directory",
+		pod:     &api.PodPolicy{VolumeMounts: []v1.VolumeMount{{Name: "certs", MountPath: vaultutil.VaultTLSAssetDir}}},
+		wantErr: true,
+	}, {
+		name:    "mount under a reserved directory",
+		pod:     &api.PodPolicy{VolumeMounts: []v1.VolumeMount{{Name: "certs", MountPath: vaultutil.VaultPluginDir + "/extra"}}},
+		wantErr: true,
+	}, {
+		name:    "mount above a reserved directory",
+		pod:     &api.PodPolicy{VolumeMounts: []v1.VolumeMount{{Name: "root", MountPath: "/"}}},
+		wantErr: true,
+	}, {
+		name:    "mount next to a reserved directory",
+		pod:     &api.PodPolicy{VolumeMounts: []v1.VolumeMount{{Name: "certs", MountPath: filepath.Clean(vaultutil.VaultTLSAssetDir) + "-extra"}}},
+		wantErr: false,
+	}, {
+		name:    "reserved container",
+		pod:     &api.PodPolicy{ExtraContainers: []v1.Container{{Name: vaultContainerName}}},
+		wantErr: true,
+	}, {
+		name:    "reserved plugin init container",
+		pod:     &api.PodPolicy{InitContainers: []v1.Container{{Name: pluginContainerPrefix + "foo"}}},
+		wantErr: true,
+	}, {
+		name: "duplicate container",
+		pod: &api.PodPolicy{
+			ExtraContainers: []v1.Container{{Name: "helper"}},
+			InitContainers:  []v1.Container{{Name: "helper"}},
+		},
+		wantErr: true,
+	}, {
+		name:    "reserved env var",
+		pod:     &api.PodPolicy{Env: []v1.EnvVar{{Name: envPodIP}}},
+		wantErr: true,
+	}}
+	for _, tt := range tests {
+		err := ValidatePodPolicy(tt.pod)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: ValidatePodPolicy() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestApplyExtraPodSpec(t *testing.T) {
+	vr := newTestVaultService()
+	vr.Spec.Pod = &api.PodPolicy{
+		ExtraContainers: []v1.Container{{Name: "log-shipper"}},
+		InitContainers:  []v1.Container{{Name: "fetch-certs"}},
+		ExtraVolumes:    []v1.Volume{{Name: "certs"}},
+		VolumeMounts:    []v1.VolumeMount{{Name: "certs", MountPath: "/etc/certs"}},
+		Env:             []v1.EnvVar{{Name: "VAULT_LOG_LEVEL", Value: "debug"}},
+	}
+	s := newVaultPodTemplate(vr).Spec
+
+	if s.Containers[0].Name != vaultContainerName {
+		t.Fatalf("expect the vault container first, got %s", s.Containers[0].Name)
+	}
+	if c := s.Containers[len(s.Containers)-1]; c.Name != "log-shipper" {
+		t.Errorf("expect the extra container last, got %s", c.Name)
+	}
+	if c := s.InitContainers[len(s.InitContainers)-1]; c.Name != "fetch-certs" {
+		t.Errorf("expect the extra init container last, got %s", c.Name)
+	}
+	if v := s.Volumes[len(s.Volumes)-1]; v.Name != "certs" {
+		t.Errorf("expect the extra volume last, got %s", v.Name)
+	}
+	vc := s.Containers[0]
+	if m := vc.VolumeMounts[len(vc.VolumeMounts)-1]; m.MountPath != "/etc/certs" {
+		t.Errorf("expect the extra volume mount in the vault container, got %s", m.MountPath)
+	}
+	if e := vc.Env[len(vc.Env)-1]; e.Name != "VAULT_LOG_LEVEL" {
+		t.Errorf("expect the extra env var in the vault container, got %s", e.Name)
+	}
+}
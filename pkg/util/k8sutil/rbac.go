@@ -0,0 +1,204 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sutil
+
+import (
+	"fmt"
+	"reflect"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+
+	"k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// authDelegatorClusterRole allows to review tokens and check access on behalf of others.
+const authDelegatorClusterRole = "system:auth-delegator"
+
+// VaultServiceAccountName returns the name of the ServiceAccount the operator creates for the given vault name
+func VaultServiceAccountName(vaultName string) string {
+	return vaultName
+}
+
+// VaultPodServiceAccountName returns the name of the ServiceAccount that the vault pods of the given vault service run as.
+func VaultPodServiceAccountName(v *api.VaultService) string {
+	if p := v.Spec.Pod; p != nil && len(p.ServiceAccountName) != 0 {
+		return p.ServiceAccountName
+	}
+	return VaultServiceAccountName(v.Name)
+}
+
+// VaultTokenReviewBindingName returns the name of the ClusterRoleBinding that lets the vault pods
+// of the given vault service review service account tokens.
+// ClusterRoleBindings are not namespaced, so the name includes the namespace of the vault service.
+func VaultTokenReviewBindingName(v *api.VaultService) string {
+	return fmt.Sprintf("vault-tokenreview:%s:%s", v.Namespace, v.Name)
+}
+
+// syncVaultServiceAccount creates the ServiceAccount that vault pods run as by default.
+func syncVaultServiceAccount(kubecli kubernetes.Interface, v *api.VaultService) error {
+	sa := &v1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   VaultServiceAccountName(v.Name),
+			Labels: LabelsForVault(v.Name),
+		},
+	}
+	AddOwnerRefToObject(sa, AsOwner(v))
+	_, err := kubecli.CoreV1().ServiceAccounts(v.Namespace).Create(sa)
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create service account (%s): %v", sa.Name, err)
+	}
+	return nil
+}
+
+// syncVaultRole creates the Role of the vault pods and binds it to their ServiceAccount.
+// The Role only lets the vault pods read the pods and endpoints of their namespace, to look up their peers.
+func syncVaultRole(kubecli kubernetes.Interface, v *api.VaultService) error {
+	role := newVaultRole(v)
+	_, err := kubecli.RbacV1().Roles(v.Namespace).Create(role)
+	if err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create role (%s): %v", role.Name, err)
+	}
+
+	rb := newVaultRoleBinding(v)
+	rbCli := kubecli.RbacV1().RoleBindings(v.Namespace)
+	_, err = rbCli.Create(rb)
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create role binding (%s): %v", rb.Name, err)
+	}
+	cur, err := rbCli.Get(rb.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get role binding (%s): %v", rb.Name, err)
+	}
+	if reflect.DeepEqual(cur.Subjects, rb.Subjects) {
+		return nil
+	}
+	cur.Subjects = rb.Subjects
+	_, err = rbCli.Update(cur)
+	if err != nil {
+		return fmt.Errorf("failed to update role binding (%s): %v", rb.Name, err)
+	}
+	return nil
+}
+
+func newVaultRole(v *api.VaultService) *rbacv1.Role {
+	role := &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   VaultServiceAccountName(v.Name),
+			Labels: LabelsForVault(v.Name),
+		},
+		Rules: []rbacv1.PolicyRule{{
+			APIGroups: []string{""},
+			Resources: []string{"pods", "endpoints"},
+			Verbs:     []string{"get", "list", "watch"},
+		}},
+	}
+	AddOwnerRefToObject(role, AsOwner(v))
+	return role
+}
+
+func newVaultRoleBinding(v *api.VaultService) *rbacv1.RoleBinding {
+	rb := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   VaultServiceAccountName(v.Name),
+			Labels: LabelsForVault(v.Name),
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "Role",
+			Name:     VaultServiceAccountName(v.Name),
+		},
+		Subjects: vaultPodSubjects(v),
+	}
+	AddOwnerRefToObject(rb, AsOwner(v))
+	return rb
+}
+
+// vaultPodSubjects returns the RBAC subjects of the ServiceAccount that the vault pods run as.
+func vaultPodSubjects(v *api.VaultService) []rbacv1.Subject {
+	return []rbacv1.Subject{{
+		Kind:      rbacv1.ServiceAccountKind,
+		Name:      VaultPodServiceAccountName(v),
+		Namespace: v.Namespace,
+	}}
+}
+
+// SyncVaultTokenReviewBinding binds the service account of the vault pods to the system:auth-delegator
+// cluster role if the kubernetes auth method is enabled, so that vault can review the service account tokens
+// clients log in with. Otherwise the binding is deleted.
+//
+// A cluster scoped object cannot be owned by the namespaced vault CR. The binding is deleted by
+// DeleteVaultTokenReviewBinding when the vault CR is deleted instead, which the VaultFinalizer
+// on the vault CR guarantees even if the operator is down at that time.
+func SyncVaultTokenReviewBinding(kubecli kubernetes.Interface, v *api.VaultService) error {
+	if !v.Spec.KubernetesAuth {
+		return DeleteVaultTokenReviewBinding(kubecli, v)
+	}
+
+	crb := newVaultTokenReviewBinding(v)
+	crbCli := kubecli.RbacV1().ClusterRoleBindings()
+	_, err := crbCli.Create(crb)
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create cluster role binding (%s): %v", crb.Name, err)
+	}
+	// The service account of the vault pods may have changed.
+	cur, err := crbCli.Get(crb.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get cluster role binding (%s): %v", crb.Name, err)
+	}
+	if reflect.DeepEqual(cur.Subjects, crb.Subjects) {
+		return nil
+	}
+	cur.Subjects = crb.Subjects
+	_, err = crbCli.Update(cur)
+	if err != nil {
+		return fmt.Errorf("failed to update cluster role binding (%s): %v", crb.Name, err)
+	}
+	return nil
+}
+
+func newVaultTokenReviewBinding(v *api.VaultService) *rbacv1.ClusterRoleBinding {
+	return &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   VaultTokenReviewBindingName(v),
+			Labels: LabelsForVault(v.Name),
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     authDelegatorClusterRole,
+		},
+		Subjects: vaultPodSubjects(v),
+	}
+}
+
+// DeleteVaultTokenReviewBinding deletes the ClusterRoleBinding created by SyncVaultTokenReviewBinding.
+func DeleteVaultTokenReviewBinding(kubecli kubernetes.Interface, v *api.VaultService) error {
+	name := VaultTokenReviewBindingName(v)
+	err := kubecli.RbacV1().ClusterRoleBindings().Delete(name, nil)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete cluster role binding (%s): %v", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sutil
+
+import (
+	"reflect"
+	"testing"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+func TestVaultPodSubjects(t *testing.T) {
+	tests := []struct {
+		name string
+		pod  *api.PodPolicy
+		want string
+	}{{
+		name: "default service account",
+		want: "example",
+	}, {
+		name: "pod policy service account",
+		pod:  &api.PodPolicy{ServiceAccountName: "vault"},
+		want: "vault",
+	}}
+
+	for _, tt := range tests {
+		vr := newTestVaultService()
+		vr.Spec.Pod = tt.pod
+		want := []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: tt.want, Namespace: "default"}}
+
+		crb := newVaultTokenReviewBinding(vr)
+		if !reflect.DeepEqual(crb.Subjects, want) {
+			t.Errorf("%s: token review binding subjects = %v, want %v", tt.name, crb.Subjects, want)
+		}
+		rb := newVaultRoleBinding(vr)
+		if !reflect.DeepEqual(rb.Subjects, want) {
+			t.Errorf("%s: role binding subjects = %v, want %v", tt.name, rb.Subjects, want)
+		}
+	}
+}
+
+func TestNewVaultTokenReviewBinding(t *testing.T) {
+	vr := newTestVaultService()
+	crb := newVaultTokenReviewBinding(vr)
+	if crb.Name != "vault-tokenreview:default:example" {
+		t.Errorf("name = %s, want vault-tokenreview:default:example", crb.Name)
+	}
+	if crb.RoleRef.Kind != "ClusterRole" || crb.RoleRef.Name != authDelegatorClusterRole {
+		t.Errorf("role ref = %v, want the %s cluster role", crb.RoleRef, authDelegatorClusterRole)
+	}
+	// Cluster scoped objects cannot be owned by the namespaced vault CR.
+	if len(crb.OwnerReferences) != 0 {
+		t.Errorf("owner references = %v, want none", crb.OwnerReferences)
+	}
+}
+
+func TestNewVaultRole(t *testing.T) {
+	vr := newTestVaultService()
+	role := newVaultRole(vr)
+	rb := newVaultRoleBinding(vr)
+
+	if rb.RoleRef.Kind != "Role" || rb.RoleRef.Name != role.Name {
+		t.Errorf("role ref = %v, want the %s role", rb.RoleRef, role.Name)
+	}
+	for _, r := range role.Rules {
+		for _, verb := range r.Verbs {
+			switch verb {
+			case "get", "list", "watch":
+			default:
+				t.Errorf("role allows %s on %v, want read only", verb, r.Resources)
+			}
+		}
+	}
+	if len(role.OwnerReferences) != 1 || len(rb.OwnerReferences) != 1 {
+		t.Errorf("role and role binding should be owned by the vault CR, got %v and %v", role.OwnerReferences, rb.OwnerReferences)
+	}
+}
@@ -0,0 +1,152 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+)
+
+// routesPath is the API path of the OpenShift routes in the given namespace.
+// The client-go version in use has no OpenShift clients, so routes are handled as raw JSON.
+const routesPath = "/apis/route.openshift.io/v1/namespaces/%s/routes"
+
+// route is the subset of the OpenShift route (route.openshift.io/v1) the operator manages.
+type route struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Spec              routeSpec `json:"spec"`
+}
+
+type routeSpec struct {
+	Host string               `json:"host"`
+	To   routeTargetReference `json:"to"`
+	Port *routePort           `json:"port,omitempty"`
+	TLS  *routeTLSConfig      `json:"tls,omitempty"`
+}
+
+type routeTargetReference struct {
+	Kind string `json:"kind"`
+	Name string `json:"name"`
+}
+
+type routePort struct {
+	TargetPort intstr.IntOrString `json:"targetPort"`
+}
+
+type routeTLSConfig struct {
+	Termination string `json:"termination"`
+}
+
+// syncVaultRoute creates or updates the OpenShift route exposing the vault service,
+// or deletes it if the service policy does not specify one.
+func syncVaultRoute(kubecli kubernetes.Interface, v *api.VaultService) error {
+	rc := kubecli.Discovery().RESTClient()
+	path := fmt.Sprintf(routesPath, v.Namespace)
+	if v.Spec.Service == nil || v.Spec.Service.Route == nil {
+		return deleteVaultRoute(kubecli, v.Namespace, v.Name)
+	}
+
+	rp := v.Spec.Service.Route
+	spec := routeSpec{
+		Host: rp.Host,
+		To:   routeTargetReference{Kind: "Service", Name: v.Name},
+		Port: &routePort{TargetPort: intstr.FromString(vaultClientPortName)},
+		// No certs: TLS is passed through to vault.
+		TLS: &routeTLSConfig{Termination: "passthrough"},
+	}
+
+	// TODO: use routes informer
+	raw, err := rc.Get().AbsPath(path, v.Name).Do().Raw()
+	if apierrors.IsNotFound(err) {
+		r := &route{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: "route.openshift.io/v1",
+				Kind:       "Route",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        v.Name,
+				Labels:      LabelsForVault(v.Name),
+				Annotations: applySpecAnnotations(nil, rp.Annotations),
+			},
+			Spec: spec,
+		}
+		AddOwnerRefToObject(r, AsOwner(v))
+		body, err := json.Marshal(r)
+		if err != nil {
+			return fmt.Errorf("failed to create vault route: %v", err)
+		}
+		err = rc.Post().AbsPath(path).SetHeader("Content-Type", "application/json").Body(body).Do().Error()
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create vault route: %v", err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get vault route: %v", err)
+	}
+
+	// The route is updated as a whole, so keep the fields the operator does not manage.
+	r := &route{}
+	obj := map[string]interface{}{}
+	if err = json.Unmarshal(raw, r); err == nil {
+		err = json.Unmarshal(raw, &obj)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to decode vault route: %v", err)
+	}
+	annotations := applySpecAnnotations(r.Annotations, rp.Annotations)
+	if reflect.DeepEqual(r.Annotations, annotations) && reflect.DeepEqual(r.Spec, spec) {
+		return nil
+	}
+	meta, ok := obj["metadata"].(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("failed to decode vault route: no metadata")
+	}
+	meta["annotations"] = annotations
+	rs, _ := obj["spec"].(map[string]interface{})
+	if rs == nil {
+		rs = map[string]interface{}{}
+		obj["spec"] = rs
+	}
+	rs["host"], rs["to"], rs["port"], rs["tls"] = spec.Host, spec.To, spec.Port, spec.TLS
+	body, err := json.Marshal(obj)
+	if err != nil {
+		return fmt.Errorf("failed to update vault route: %v", err)
+	}
+	err = rc.Put().AbsPath(path, v.Name).SetHeader("Content-Type", "application/json").Body(body).Do().Error()
+	if err != nil {
+		return fmt.Errorf("failed to update vault route: %v", err)
+	}
+	return nil
+}
+
+// deleteVaultRoute deletes the OpenShift route of the given vault service, if there is one.
+func deleteVaultRoute(kubecli kubernetes.Interface, namespace, name string) error {
+	// Routes do not exist outside of OpenShift, which the API server reports as not found as well.
+	err := kubecli.Discovery().RESTClient().Delete().AbsPath(fmt.Sprintf(routesPath, namespace), name).Do().Error()
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete vault route: %v", err)
+	}
+	return nil
+}
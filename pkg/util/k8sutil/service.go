@@ -0,0 +1,359 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sutil
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+
+	"k8s.io/api/core/v1"
+	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+)
+
+// specAnnotationsAnnotation records the keys of the annotations the operator set from the vault spec.
+const specAnnotationsAnnotation = "vault.security.coreos.com/spec-annotations"
+
+// sslPassthroughAnnotation makes the nginx ingress controller pass TLS through to vault.
+const sslPassthroughAnnotation = "nginx.ingress.kubernetes.io/ssl-passthrough"
+
+// tolerateUnreadyEndpointsAnnotation makes a service publish the addresses of unready pods.
+const tolerateUnreadyEndpointsAnnotation = "service.alpha.kubernetes.io/tolerate-unready-endpoints"
+
+// ValidateServicePolicy checks that the vault service can be exposed as specified.
+func ValidateServicePolicy(sp *api.ServicePolicy) error {
+	if sp == nil {
+		return nil
+	}
+	switch sp.Type {
+	case "", v1.ServiceTypeClusterIP, v1.ServiceTypeNodePort, v1.ServiceTypeLoadBalancer:
+	default:
+		return fmt.Errorf("unsupported service type (%s)", sp.Type)
+	}
+	if len(sp.ExternalAddress) != 0 {
+		u, err := url.Parse(sp.ExternalAddress)
+		if err != nil || u.Scheme != "https" || len(u.Hostname()) == 0 {
+			return fmt.Errorf("external address (%s) must be an https URL", sp.ExternalAddress)
+		}
+	}
+	if sp.Ingress != nil {
+		if errs := validation.IsDNS1123Subdomain(sp.Ingress.Host); len(errs) != 0 {
+			return fmt.Errorf("ingress host (%s) is invalid: %v", sp.Ingress.Host, errs)
+		}
+	}
+	if sp.Route != nil {
+		if errs := validation.IsDNS1123Subdomain(sp.Route.Host); len(errs) != 0 {
+			return fmt.Errorf("route host (%s) is invalid: %v", sp.Route.Host, errs)
+		}
+	}
+	return nil
+}
+
+// VaultAPIAddress returns the address vault advertises to clients.
+func VaultAPIAddress(v *api.VaultService) string {
+	if addr := v.Spec.Service.GetExternalAddress(); len(addr) != 0 {
+		return addr
+	}
+	return VaultServiceURL(v.GetName(), v.GetNamespace(), VaultClientPort)
+}
+
+// VaultStandbyServiceName returns the name of the service of the standby nodes of the given vault name
+func VaultStandbyServiceName(vaultName string) string {
+	return vaultName + "-standby"
+}
+
+// VaultHeadlessServiceName returns the name of the headless service of the given vault name
+func VaultHeadlessServiceName(vaultName string) string {
+	return vaultName + "-internal"
+}
+
+// VaultServiceHost returns the DNS name of the vault service in the given namespace.
+func VaultServiceHost(vaultName, namespace string) string {
+	return fmt.Sprintf("%s.%s.svc", vaultName, namespace)
+}
+
+// syncVaultService creates the vault service, or updates it to match the service policy.
+// The vault service only routes to the active vault node.
+func syncVaultService(kubecli kubernetes.Interface, v *api.VaultService) error {
+	selector := LabelsForVault(v.GetName())
+	selector[VaultActiveLabel] = "true"
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   v.Name,
+			Labels: LabelsForVault(v.GetName()),
+		},
+		Spec: v1.ServiceSpec{
+			Selector: selector,
+			Ports: []v1.ServicePort{
+				{
+					Name:     vaultClientPortName,
+					Protocol: v1.ProtocolTCP,
+					Port:     VaultClientPort,
+				},
+				{
+					Name:     vaultClusterPortName,
+					Protocol: v1.ProtocolTCP,
+					Port:     vaultClusterPort,
+				},
+				{
+					Name:     "prometheus",
+					Protocol: v1.ProtocolTCP,
+					Port:     exporterPromPort,
+				},
+			},
+		},
+	}
+	applyServicePolicy(svc, v.Spec.Service)
+	return syncService(kubecli, v, svc, func(cur *v1.Service) {
+		cur.Spec.Selector = selector
+		applyServicePolicy(cur, v.Spec.Service)
+	})
+}
+
+// syncVaultStandbyService creates the service routing to the unsealed standby vault nodes,
+// which clients can read from without going through the active node.
+func syncVaultStandbyService(kubecli kubernetes.Interface, v *api.VaultService) error {
+	selector := LabelsForVault(v.GetName())
+	selector[VaultActiveLabel] = "false"
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   VaultStandbyServiceName(v.Name),
+			Labels: LabelsForVault(v.GetName()),
+			Annotations: map[string]string{
+				// Deployments created by older operators only mark the active node ready.
+				// The operator labels only the unsealed standby nodes with vault-active=false.
+				tolerateUnreadyEndpointsAnnotation: "true",
+			},
+		},
+		Spec: v1.ServiceSpec{
+			Selector: selector,
+			Ports: []v1.ServicePort{{
+				Name:     vaultClientPortName,
+				Protocol: v1.ProtocolTCP,
+				Port:     VaultClientPort,
+			}},
+		},
+	}
+	return syncService(kubecli, v, svc, func(cur *v1.Service) {
+		if cur.Annotations == nil {
+			cur.Annotations = map[string]string{}
+		}
+		cur.Annotations[tolerateUnreadyEndpointsAnnotation] = "true"
+		cur.Spec.Selector = selector
+	})
+}
+
+// syncVaultHeadlessService creates the headless service that resolves to the IPs of all vault pods,
+// sealed or not, so that peers and clients can address every vault node without pod DNS.
+func syncVaultHeadlessService(kubecli kubernetes.Interface, v *api.VaultService) error {
+	selector := LabelsForVault(v.GetName())
+	svc := &v1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   VaultHeadlessServiceName(v.Name),
+			Labels: LabelsForVault(v.GetName()),
+			Annotations: map[string]string{
+				// Sealed vault nodes are not ready but still need to be reachable.
+				tolerateUnreadyEndpointsAnnotation: "true",
+			},
+		},
+		Spec: v1.ServiceSpec{
+			ClusterIP: v1.ClusterIPNone,
+			Selector:  selector,
+			Ports: []v1.ServicePort{
+				{
+					Name:     vaultClientPortName,
+					Protocol: v1.ProtocolTCP,
+					Port:     VaultClientPort,
+				},
+				{
+					Name:     vaultClusterPortName,
+					Protocol: v1.ProtocolTCP,
+					Port:     vaultClusterPort,
+				},
+			},
+		},
+	}
+	return syncService(kubecli, v, svc, func(cur *v1.Service) {
+		cur.Spec.Selector = selector
+	})
+}
+
+// syncService creates the given service, or if it exists, updates it with the given function.
+func syncService(kubecli kubernetes.Interface, v *api.VaultService, svc *v1.Service, update func(*v1.Service)) error {
+	AddOwnerRefToObject(svc, AsOwner(v))
+	_, err := kubecli.CoreV1().Services(v.Namespace).Create(svc)
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create service (%s): %v", svc.Name, err)
+	}
+
+	// TODO: use services informer
+	cur, err := kubecli.CoreV1().Services(v.Namespace).Get(svc.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get service (%s): %v", svc.Name, err)
+	}
+	want := cur.DeepCopy()
+	update(want)
+	if reflect.DeepEqual(cur, want) {
+		return nil
+	}
+	_, err = kubecli.CoreV1().Services(v.Namespace).Update(want)
+	if err != nil {
+		return fmt.Errorf("failed to update service (%s): %v", svc.Name, err)
+	}
+	return nil
+}
+
+// applyServicePolicy sets the exposure of the vault service as specified by the service policy.
+func applyServicePolicy(svc *v1.Service, sp *api.ServicePolicy) {
+	if sp == nil {
+		sp = &api.ServicePolicy{}
+	}
+	svc.Spec.Type = sp.Type
+	if len(svc.Spec.Type) == 0 {
+		svc.Spec.Type = v1.ServiceTypeClusterIP
+	}
+	svc.Annotations = applySpecAnnotations(svc.Annotations, sp.Annotations)
+	svc.Spec.LoadBalancerSourceRanges = nil
+	svc.Spec.ExternalTrafficPolicy = ""
+	switch svc.Spec.Type {
+	case v1.ServiceTypeClusterIP:
+		// Node ports are not allowed on ClusterIP services.
+		for i := range svc.Spec.Ports {
+			svc.Spec.Ports[i].NodePort = 0
+		}
+	case v1.ServiceTypeLoadBalancer:
+		svc.Spec.LoadBalancerSourceRanges = sp.LoadBalancerSourceRanges
+		fallthrough
+	case v1.ServiceTypeNodePort:
+		svc.Spec.ExternalTrafficPolicy = sp.ExternalTrafficPolicy
+		if len(svc.Spec.ExternalTrafficPolicy) == 0 {
+			svc.Spec.ExternalTrafficPolicy = v1.ServiceExternalTrafficPolicyTypeCluster
+		}
+	}
+	// The health check node port is only allowed on LoadBalancer services with the Local policy.
+	if svc.Spec.Type != v1.ServiceTypeLoadBalancer || svc.Spec.ExternalTrafficPolicy != v1.ServiceExternalTrafficPolicyTypeLocal {
+		svc.Spec.HealthCheckNodePort = 0
+	}
+}
+
+// applySpecAnnotations returns the current annotations of an object with the annotations of the vault spec applied.
+// Annotations set by others, like cloud controllers, are kept. The keys of the spec annotations are recorded,
+// so that they are removed once they are dropped from the spec.
+func applySpecAnnotations(cur, spec map[string]string) map[string]string {
+	out := map[string]string{}
+	for k, v := range cur {
+		out[k] = v
+	}
+	for _, k := range strings.Split(out[specAnnotationsAnnotation], ",") {
+		delete(out, k)
+	}
+	delete(out, specAnnotationsAnnotation)
+	if len(spec) != 0 {
+		keys := make([]string, 0, len(spec))
+		for k, v := range spec {
+			out[k] = v
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		out[specAnnotationsAnnotation] = strings.Join(keys, ",")
+	}
+	if len(out) == 0 {
+		return nil
+	}
+	return out
+}
+
+// syncVaultIngress creates or updates the ingress exposing the vault service,
+// or deletes it if the service policy does not specify one.
+func syncVaultIngress(kubecli kubernetes.Interface, v *api.VaultService) error {
+	ingCli := kubecli.ExtensionsV1beta1().Ingresses(v.Namespace)
+	if v.Spec.Service == nil || v.Spec.Service.Ingress == nil {
+		err := ingCli.Delete(v.Name, nil)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete vault ingress: %v", err)
+		}
+		return nil
+	}
+
+	ip := v.Spec.Service.Ingress
+	annotations := map[string]string{}
+	for k, val := range ip.Annotations {
+		annotations[k] = val
+	}
+	if _, ok := annotations[sslPassthroughAnnotation]; !ok {
+		annotations[sslPassthroughAnnotation] = "true"
+	}
+	spec := extensionsv1beta1.IngressSpec{
+		// No secret: TLS is passed through to vault.
+		TLS: []extensionsv1beta1.IngressTLS{{Hosts: []string{ip.Host}}},
+		Rules: []extensionsv1beta1.IngressRule{{
+			Host: ip.Host,
+			IngressRuleValue: extensionsv1beta1.IngressRuleValue{
+				HTTP: &extensionsv1beta1.HTTPIngressRuleValue{
+					Paths: []extensionsv1beta1.HTTPIngressPath{{
+						Backend: extensionsv1beta1.IngressBackend{
+							ServiceName: v.Name,
+							ServicePort: intstr.FromInt(VaultClientPort),
+						},
+					}},
+				},
+			},
+		}},
+	}
+
+	// TODO: use ingresses informer
+	ing, err := ingCli.Get(v.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		ing = &extensionsv1beta1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        v.Name,
+				Labels:      LabelsForVault(v.Name),
+				Annotations: annotations,
+			},
+			Spec: spec,
+		}
+		AddOwnerRefToObject(ing, AsOwner(v))
+		_, err = ingCli.Create(ing)
+		if err != nil && !apierrors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create vault ingress: %v", err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get vault ingress: %v", err)
+	}
+	if reflect.DeepEqual(ing.Annotations, annotations) && reflect.DeepEqual(ing.Spec, spec) {
+		return nil
+	}
+	ing.Annotations = annotations
+	ing.Spec = spec
+	_, err = ingCli.Update(ing)
+	if err != nil {
+		return fmt.Errorf("failed to update vault ingress: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sutil
+
+import (
+	"reflect"
+	"testing"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+
+	"k8s.io/api/core/v1"
+)
+
+func TestApplySpecAnnotations(t *testing.T) {
+	tests := []struct {
+		name string
+		cur  map[string]string
+		spec map[string]string
+		want map[string]string
+	}{{
+		name: "none",
+	}, {
+		name: "added",
+		spec: map[string]string{"b": "1", "a": "2"},
+		want: map[string]string{"a": "2", "b": "1", specAnnotationsAnnotation: "a,b"},
+	}, {
+		name: "others are kept",
+		cur:  map[string]string{"cloud": "x"},
+		spec: map[string]string{"a": "1"},
+		want: map[string]string{"cloud": "x", "a": "1", specAnnotationsAnnotation: "a"},
+	}, {
+		name: "dropped from the spec",
+		cur:  map[string]string{"cloud": "x", "a": "1", "b": "2", specAnnotationsAnnotation: "a,b"},
+		spec: map[string]string{"b": "3"},
+		want: map[string]string{"cloud": "x", "b": "3", specAnnotationsAnnotation: "b"},
+	}, {
+		name: "all dropped",
+		cur:  map[string]string{"a": "1", specAnnotationsAnnotation: "a"},
+	}}
+	for _, tt := range tests {
+		got := applySpecAnnotations(tt.cur, tt.spec)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: annotations = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestApplyServicePolicy(t *testing.T) {
+	tests := []struct {
+		name           string
+		cur            v1.ServiceSpec
+		sp             *api.ServicePolicy
+		wantType       v1.ServiceType
+		wantPolicy     v1.ServiceExternalTrafficPolicyType
+		wantNodePort   int32
+		wantHCNodePort int32
+	}{{
+		name:     "default",
+		wantType: v1.ServiceTypeClusterIP,
+	}, {
+		name:         "node port",
+		cur:          v1.ServiceSpec{Ports: []v1.ServicePort{{NodePort: 30001}}},
+		sp:           &api.ServicePolicy{Type: v1.ServiceTypeNodePort},
+		wantType:     v1.ServiceTypeNodePort,
+		wantPolicy:   v1.ServiceExternalTrafficPolicyTypeCluster,
+		wantNodePort: 30001,
+	}, {
+		name:         "back to ClusterIP",
+		cur:          v1.ServiceSpec{Type: v1.ServiceTypeNodePort, Ports: []v1.ServicePort{{NodePort: 30001}}},
+		wantType:     v1.ServiceTypeClusterIP,
+		wantNodePort: 0,
+	}, {
+		name: "load balancer with the Local policy",
+		cur: v1.ServiceSpec{
+			Type:                v1.ServiceTypeLoadBalancer,
+			Ports:               []v1.ServicePort{{NodePort: 30001}},
+			HealthCheckNodePort: 30002,
+		},
+		sp:             &api.ServicePolicy{Type: v1.ServiceTypeLoadBalancer, ExternalTrafficPolicy: v1.ServiceExternalTrafficPolicyTypeLocal},
+		wantType:       v1.ServiceTypeLoadBalancer,
+		wantPolicy:     v1.ServiceExternalTrafficPolicyTypeLocal,
+		wantNodePort:   30001,
+		wantHCNodePort: 30002,
+	}, {
+		name: "load balancer switched to the Cluster policy",
+		cur: v1.ServiceSpec{
+			Type:                  v1.ServiceTypeLoadBalancer,
+			ExternalTrafficPolicy: v1.ServiceExternalTrafficPolicyTypeLocal,
+			Ports:                 []v1.ServicePort{{NodePort: 30001}},
+			HealthCheckNodePort:   30002,
+		},
+		sp:           &api.ServicePolicy{Type: v1.ServiceTypeLoadBalancer, ExternalTrafficPolicy: v1.ServiceExternalTrafficPolicyTypeCluster},
+		wantType:     v1.ServiceTypeLoadBalancer,
+		wantPolicy:   v1.ServiceExternalTrafficPolicyTypeCluster,
+		wantNodePort: 30001,
+	}, {
+		name: "load balancer switched to NodePort",
+		cur: v1.ServiceSpec{
+			Type:                  v1.ServiceTypeLoadBalancer,
+			ExternalTrafficPolicy: v1.ServiceExternalTrafficPolicyTypeLocal,
+			Ports:                 []v1.ServicePort{{NodePort: 30001}},
+			HealthCheckNodePort:   30002,
+		},
+		sp:           &api.ServicePolicy{Type: v1.ServiceTypeNodePort, ExternalTrafficPolicy: v1.ServiceExternalTrafficPolicyTypeLocal},
+		wantType:     v1.ServiceTypeNodePort,
+		wantPolicy:   v1.ServiceExternalTrafficPolicyTypeLocal,
+		wantNodePort: 30001,
+	}}
+	for _, tt := range tests {
+		svc := &v1.Service{Spec: tt.cur}
+		if len(svc.Spec.Ports) == 0 {
+			svc.Spec.Ports = []v1.ServicePort{{}}
+		}
+		applyServicePolicy(svc, tt.sp)
+		s := svc.Spec
+		if s.Type != tt.wantType || s.ExternalTrafficPolicy != tt.wantPolicy {
+			t.Errorf("%s: type = %s, external traffic policy = %s, want %s, %s", tt.name, s.Type, s.ExternalTrafficPolicy, tt.wantType, tt.wantPolicy)
+		}
+		if s.Ports[0].NodePort != tt.wantNodePort {
+			t.Errorf("%s: node port = %d, want %d", tt.name, s.Ports[0].NodePort, tt.wantNodePort)
+		}
+		if s.HealthCheckNodePort != tt.wantHCNodePort {
+			t.Errorf("%s: health check node port = %d, want %d", tt.name, s.HealthCheckNodePort, tt.wantHCNodePort)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sutil
+
+import (
+	"fmt"
+	"time"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+
+	appsv1beta1 "k8s.io/api/apps/v1beta1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// VaultCanaryLabel is the label of the canary pod of an upgrade of vault.
+const VaultCanaryLabel = "vault-canary"
+
+// ValidateUpgradePolicy checks the strategy and durations of the upgrade policy of a vault service.
+func ValidateUpgradePolicy(p *api.UpgradePolicy) error {
+	if p == nil {
+		return nil
+	}
+	switch p.Strategy {
+	case "", api.UpgradeStrategyRolling, api.UpgradeStrategyCanary:
+	default:
+		return fmt.Errorf("unknown upgrade strategy (%s)", p.Strategy)
+	}
+	for _, d := range []string{p.SoakTime, p.Timeout} {
+		if len(d) == 0 {
+			continue
+		}
+		if _, err := time.ParseDuration(d); err != nil {
+			return fmt.Errorf("invalid duration (%s): %v", d, err)
+		}
+	}
+	return nil
+}
+
+// NewVaultCanaryPod returns the canary pod of an upgrade of the given vault, which runs the vault image
+// of its spec with the pod template of the vault deployment d.
+// It has the labels of the vault pods, so that it joins the vault service and its status, but not the
+// pod-template-hash label, so that it is not adopted by the replica sets of the deployment.
+// Like any vault node, the canary gets the active label if it wins the leader election, and then
+// serves the vault service and is protected by the disruption budget until the upgrade moves on.
+func NewVaultCanaryPod(v *api.VaultService, d *appsv1beta1.Deployment) *v1.Pod {
+	t := d.Spec.Template.DeepCopy()
+	labels := LabelsForVault(v.Name)
+	for k, val := range t.Labels {
+		labels[k] = val
+	}
+	labels[VaultCanaryLabel] = "true"
+	p := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: v.Name + "-canary-",
+			Namespace:    v.Namespace,
+			Labels:       labels,
+			Annotations:  t.Annotations,
+		},
+		Spec: t.Spec,
+	}
+	p.Spec.Containers[0].Image = VaultImage(v.Spec)
+	AddOwnerRefToObject(p, AsOwner(v))
+	return p
+}
+
+// IsPodCrashLooping checks if any container of the pod is waiting to be restarted after crashing repeatedly.
+func IsPodCrashLooping(p *v1.Pod) bool {
+	for _, cs := range p.Status.ContainerStatuses {
+		if cs.State.Waiting != nil && cs.State.Waiting.Reason == "CrashLoopBackOff" {
+			return true
+		}
+	}
+	return false
+}
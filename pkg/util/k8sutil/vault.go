@@ -15,9 +15,14 @@
 package k8sutil
 
 import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
 	"fmt"
-	"io/ioutil"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
@@ -43,17 +48,23 @@ var (
 	vaultConfigVolName   = "vault-config"
 	evnVaultRedirectAddr = "VAULT_API_ADDR"
 	evnVaultClusterAddr  = "VAULT_CLUSTER_ADDR"
+	envPodIP             = "POD_IP"
 )
 
 const (
 	VaultClientPort      = 8200
 	vaultClusterPort     = 8201
 	vaultClientPortName  = "vault-client"
+	vaultContainerName   = "vault"
+	ipcLockCapability    = "IPC_LOCK"
 	vaultClusterPortName = "vault-cluster"
 
-	exporterStatsdPort = 9125
-	exporterPromPort   = 9102
-	exporterImage      = "prom/statsd-exporter:v0.5.0"
+	etcdClusterSize = 3
+
+	exporterContainerName = "statsd-exporter"
+	exporterStatsdPort    = 9125
+	exporterPromPort      = 9102
+	exporterImage         = "prom/statsd-exporter:v0.5.0"
 )
 
 // EtcdClientTLSSecretName returns the name of etcd client TLS secret for the given vault name
@@ -74,7 +85,6 @@ func EtcdPeerTLSSecretName(vaultName string) string {
 // DeployEtcdCluster creates an etcd cluster for the given vault's name via etcd operator and
 // waits for all of its members to be ready.
 func DeployEtcdCluster(etcdCRCli etcdCRClient.Interface, v *api.VaultService) error {
-	size := 3
 	etcdCluster := &etcdCRAPI.EtcdCluster{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       etcdCRAPI.EtcdClusterResourceKind,
@@ -86,7 +96,7 @@ func DeployEtcdCluster(etcdCRCli etcdCRClient.Interface, v *api.VaultService) er
 			Labels:    LabelsForVault(v.Name),
 		},
 		Spec: etcdCRAPI.ClusterSpec{
-			Size: size,
+			Size: etcdClusterSize,
 			TLS: &etcdCRAPI.TLSPolicy{
 				Static: &etcdCRAPI.StaticTLS{
 					Member: &etcdCRAPI.MemberSecret{
@@ -116,20 +126,25 @@ func DeployEtcdCluster(etcdCRCli etcdCRClient.Interface, v *api.VaultService) er
 		return fmt.Errorf("deploy etcd cluster failed: %v", err)
 	}
 
-	err = retryutil.Retry(10*time.Second, 10, func() (bool, error) {
+	err = WaitEtcdClusterReady(etcdCRCli, v)
+	if err != nil {
+		return fmt.Errorf("deploy etcd cluster failed: %v", err)
+	}
+	return nil
+}
+
+// WaitEtcdClusterReady waits until all members of the etcd cluster of the given vault are ready.
+func WaitEtcdClusterReady(etcdCRCli etcdCRClient.Interface, v *api.VaultService) error {
+	return retryutil.Retry(10*time.Second, 10, func() (bool, error) {
 		er, err := etcdCRCli.EtcdV1beta2().EtcdClusters(v.Namespace).Get(EtcdNameForVault(v.Name), metav1.GetOptions{})
 		if err != nil {
 			return false, err
 		}
-		if len(er.Status.Members.Ready) < size {
+		if len(er.Status.Members.Ready) < etcdClusterSize {
 			return false, nil
 		}
 		return true, nil
 	})
-	if err != nil {
-		return fmt.Errorf("deploy etcd cluster failed: %v", err)
-	}
-	return nil
 }
 
 // DeleteEtcdCluster deletes the etcd cluster for the given vault
@@ -143,7 +158,7 @@ func DeleteEtcdCluster(etcdCRCli etcdCRClient.Interface, v *api.VaultService) er
 
 func vaultContainer(v *api.VaultService) v1.Container {
 	return v1.Container{
-		Name:  "vault",
+		Name:  vaultContainerName,
 		Image: fmt.Sprintf("%s:%s", v.Spec.BaseImage, v.Spec.Version),
 		Command: []string{
 			"/bin/vault",
@@ -151,13 +166,21 @@ func vaultContainer(v *api.VaultService) v1.Container {
 			"-config=" + VaultConfigPath,
 		},
 		Env: []v1.EnvVar{
+			{
+				Name: envPodIP,
+				ValueFrom: &v1.EnvVarSource{
+					FieldRef: &v1.ObjectFieldSelector{FieldPath: "status.podIP"},
+				},
+			},
 			{
 				Name:  evnVaultRedirectAddr,
-				Value: VaultServiceURL(v.GetName(), v.GetNamespace(), VaultClientPort),
+				Value: VaultAPIAddress(v),
 			},
 			{
+				// Each vault node advertises its own address, so that standby nodes forward requests to the active node
+				// instead of the shared vault service.
 				Name:  evnVaultClusterAddr,
-				Value: VaultServiceURL(v.GetName(), v.GetNamespace(), vaultClusterPort),
+				Value: fmt.Sprintf("https://$(%s):%d", envPodIP, vaultClusterPort),
 			},
 		},
 		VolumeMounts: []v1.VolumeMount{{
@@ -168,7 +191,7 @@ func vaultContainer(v *api.VaultService) v1.Container {
 			Capabilities: &v1.Capabilities{
 				// Vault requires mlock syscall to work.
 				// Without this it would fail "Error initializing core: Failed to lock memory: cannot allocate memory"
-				Add: []v1.Capability{"IPC_LOCK"},
+				Add: []v1.Capability{ipcLockCapability},
 			},
 		},
 		Ports: []v1.ContainerPort{{
@@ -181,13 +204,7 @@ func vaultContainer(v *api.VaultService) v1.Container {
 		LivenessProbe: &v1.Probe{
 			Handler: v1.Handler{
 				Exec: &v1.ExecAction{
-					Command: []string{
-						"curl",
-						"--connect-timeout", "5",
-						"--max-time", "10",
-						"-k", "-s",
-						fmt.Sprintf("https://localhost:%d/v1/sys/health", VaultClientPort),
-					},
+					Command: curlVaultHealthCmd(),
 				},
 			},
 			InitialDelaySeconds: 10,
@@ -198,7 +215,7 @@ func vaultContainer(v *api.VaultService) v1.Container {
 		ReadinessProbe: &v1.Probe{
 			Handler: v1.Handler{
 				HTTPGet: &v1.HTTPGetAction{
-					Path:   "/v1/sys/health",
+					Path:   vaultHealthPath,
 					Port:   intstr.FromInt(VaultClientPort),
 					Scheme: v1.URISchemeHTTPS,
 				},
@@ -211,9 +228,26 @@ func vaultContainer(v *api.VaultService) v1.Container {
 	}
 }
 
+// vaultHealthPath is the health endpoint the vault pods are probed with.
+// Unsealed nodes, active or standby, are ready; sealed and uninitialized nodes are not.
+const vaultHealthPath = "/v1/sys/health?standbyok=true"
+
+// curlVaultHealthCmd returns the command that queries the health endpoint of the local vault server.
+// The given args are passed to curl before the URL.
+func curlVaultHealthCmd(args ...string) []string {
+	cmd := []string{
+		"curl",
+		"--connect-timeout", "5",
+		"--max-time", "10",
+		"-k", "-s",
+	}
+	cmd = append(cmd, args...)
+	return append(cmd, fmt.Sprintf("https://localhost:%d%s", VaultClientPort, vaultHealthPath))
+}
+
 func statsdExporterContainer() v1.Container {
 	return v1.Container{
-		Name:  "statsd-exporter",
+		Name:  exporterContainerName,
 		Image: exporterImage,
 		Ports: []v1.ContainerPort{{
 			Name:          "statsd",
@@ -235,33 +269,17 @@ func statsdExporterContainer() v1.Container {
 // it and return no error. It is safe to retry on this function.
 func DeployVault(kubecli kubernetes.Interface, v *api.VaultService) error {
 	selector := LabelsForVault(v.GetName())
+	podTempl := newVaultPodTemplate(v)
 
-	podTempl := v1.PodTemplateSpec{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:   v.GetName(),
-			Labels: selector,
-		},
-		Spec: v1.PodSpec{
-			Containers: []v1.Container{vaultContainer(v), statsdExporterContainer()},
-			Volumes: []v1.Volume{{
-				Name: vaultConfigVolName,
-				VolumeSource: v1.VolumeSource{
-					ConfigMap: &v1.ConfigMapVolumeSource{
-						LocalObjectReference: v1.LocalObjectReference{
-							Name: ConfigMapNameForVault(v),
-						},
-					},
-				},
-			}},
-		},
+	err := syncVaultServiceAccount(kubecli, v)
+	if err != nil {
+		return err
 	}
-	if v.Spec.Pod != nil {
-		applyPodPolicy(&podTempl.Spec, v.Spec.Pod)
+	err = syncVaultRole(kubecli, v)
+	if err != nil {
+		return err
 	}
 
-	configEtcdBackendTLS(&podTempl, v)
-	configVaultServerTLS(&podTempl, v)
-
 	d := &appsv1beta1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:   v.GetName(),
@@ -280,103 +298,393 @@ func DeployVault(kubecli kubernetes.Interface, v *api.VaultService) error {
 			},
 		},
 	}
+	RecordImmutableVaultSpec(d, v)
 	AddOwnerRefToObject(d, AsOwner(v))
-	_, err := kubecli.AppsV1beta1().Deployments(v.Namespace).Create(d)
+	_, err = kubecli.AppsV1beta1().Deployments(v.Namespace).Create(d)
 	if err != nil && !apierrors.IsAlreadyExists(err) {
 		return err
 	}
 
-	svc := &v1.Service{
+	err = syncVaultService(kubecli, v)
+	if err != nil {
+		return err
+	}
+	err = syncVaultStandbyService(kubecli, v)
+	if err != nil {
+		return err
+	}
+	err = syncVaultHeadlessService(kubecli, v)
+	if err != nil {
+		return err
+	}
+	err = syncVaultIngress(kubecli, v)
+	if err != nil {
+		return err
+	}
+	return syncVaultRoute(kubecli, v)
+}
+
+// immutableSpecFields are the fields of the vault spec that cannot be updated once the vault deployment is created,
+// with the annotations recording them on the deployment.
+var immutableSpecFields = []struct {
+	name       string
+	annotation string
+	value      func(v *api.VaultService) interface{}
+}{{
+	name:       "pod",
+	annotation: "vault.security.coreos.com/pod-policy-hash",
+	value:      func(v *api.VaultService) interface{} { return v.Spec.Pod },
+}, {
+	name:       "plugins",
+	annotation: "vault.security.coreos.com/plugins-hash",
+	value:      func(v *api.VaultService) interface{} { return v.Spec.Plugins },
+}}
+
+// specHash returns the hash recorded for the given field of the vault spec.
+func specHash(obj interface{}) string {
+	b, err := json.Marshal(obj)
+	if err != nil {
+		// The fields of the vault spec always marshal, as they are read from JSON.
+		panic(err)
+	}
+	return fmt.Sprintf("%x", sha256.Sum256(b))
+}
+
+// RecordImmutableVaultSpec records the immutable fields of the vault spec on the given vault deployment,
+// unless they are recorded already. It returns whether the deployment is changed.
+func RecordImmutableVaultSpec(d *appsv1beta1.Deployment, v *api.VaultService) bool {
+	changed := false
+	for _, f := range immutableSpecFields {
+		if _, ok := d.Annotations[f.annotation]; ok {
+			continue
+		}
+		if d.Annotations == nil {
+			d.Annotations = map[string]string{}
+		}
+		d.Annotations[f.annotation] = specHash(f.value(v))
+		changed = true
+	}
+	return changed
+}
+
+// ValidateImmutableVaultSpec rejects changes of the immutable fields of the vault spec
+// since they were recorded on the given vault deployment.
+func ValidateImmutableVaultSpec(d *appsv1beta1.Deployment, v *api.VaultService) error {
+	for _, f := range immutableSpecFields {
+		h, ok := d.Annotations[f.annotation]
+		if ok && h != specHash(f.value(v)) {
+			return fmt.Errorf("%s cannot be updated once the vault service is created", f.name)
+		}
+	}
+	return nil
+}
+
+// newVaultPodTemplate returns the pod template of the vault deployment of the given vault service.
+func newVaultPodTemplate(v *api.VaultService) v1.PodTemplateSpec {
+	podTempl := v1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:   v.Name,
-			Labels: selector,
+			Name:   v.GetName(),
+			Labels: LabelsForVault(v.GetName()),
 		},
-		Spec: v1.ServiceSpec{
-			Selector: selector,
-			Ports: []v1.ServicePort{
-				{
-					Name:     vaultClientPortName,
-					Protocol: v1.ProtocolTCP,
-					Port:     VaultClientPort,
-				},
-				{
-					Name:     vaultClusterPortName,
-					Protocol: v1.ProtocolTCP,
-					Port:     vaultClusterPort,
-				},
-				{
-					Name:     "prometheus",
-					Protocol: v1.ProtocolTCP,
-					Port:     exporterPromPort,
+		Spec: v1.PodSpec{
+			ServiceAccountName: VaultServiceAccountName(v.Name),
+			Containers:         []v1.Container{vaultContainer(v), statsdExporterContainer()},
+			Volumes: []v1.Volume{{
+				Name: vaultConfigVolName,
+				VolumeSource: v1.VolumeSource{
+					ConfigMap: &v1.ConfigMapVolumeSource{
+						LocalObjectReference: v1.LocalObjectReference{
+							Name: ConfigMapNameForVault(v),
+						},
+					},
 				},
-			},
+			}},
 		},
 	}
-	AddOwnerRefToObject(svc, AsOwner(v))
-	_, err = kubecli.CoreV1().Services(v.Namespace).Create(svc)
-	if err != nil && !apierrors.IsAlreadyExists(err) {
-		return fmt.Errorf("failed to create vault service: %v", err)
-	}
-	return nil
+	configEtcdBackendTLS(&podTempl, v)
+	configVaultServerTLS(&podTempl, v)
+	configVaultPlugins(&podTempl, v)
+	configVaultAudit(&podTempl, v)
+	applyPodPolicy(&podTempl, v)
+	applyExtraPodSpec(&podTempl, v.Spec.Pod)
+	return podTempl
 }
 
 // UpgradeDeployment sets deployment spec to:
 // - roll forward version
-// - keep active Vault node available by setting `maxUnavailable=N-1` and `maxSurge=1`
+// - keep an unsealed Vault node available by setting `maxUnavailable=N-1` and `maxSurge=1`
+// - pick up the probes of the current operator, so that deployments created by older operators
+//   count unsealed standby nodes as ready
 func UpgradeDeployment(kubecli kubernetes.Interface, vr *api.VaultService, d *appsv1beta1.Deployment) error {
 	mu := intstr.FromInt(int(vr.Spec.Nodes - 1))
 	d.Spec.Strategy.RollingUpdate.MaxUnavailable = &mu
-	d.Spec.Template.Spec.Containers[0].Image = vaultImage(vr.Spec)
+	c := &d.Spec.Template.Spec.Containers[0]
+	want := newVaultPodTemplate(vr).Spec.Containers[0]
+	c.Image = want.Image
+	c.LivenessProbe = want.LivenessProbe
+	c.ReadinessProbe = want.ReadinessProbe
+	_, err := kubecli.AppsV1beta1().Deployments(d.Namespace).Update(d)
+	if err != nil {
+		return fmt.Errorf("failed to upgrade deployment to (%s): %v", VaultImage(vr.Spec), err)
+	}
+	return nil
+}
+
+// serverCertHashAnnotation records on the vault pod template the hash of the server cert
+// the vault pods were last restarted for by the operator.
+const serverCertHashAnnotation = "vault.security.coreos.com/server-cert-hash"
+
+// RestartVaultPodsForServerCert rolls the vault pods so that they load the given server cert, by recording its hash
+// on the pod template of the vault deployment. If force is false, the pods are only rolled if a different cert
+// was recorded before, i.e. for a restart that was not applied yet.
+// Like an upgrade, it sets `maxUnavailable=N-1`, so that the restarted pods, which come up sealed,
+// don't hold up the rollout, while an unsealed vault node with the old cert keeps serving.
+func RestartVaultPodsForServerCert(kubecli kubernetes.Interface, vr *api.VaultService, cert []byte, force bool) error {
+	// TODO: make use of deployment informer
+	d, err := kubecli.AppsV1beta1().Deployments(vr.Namespace).Get(vr.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		// The vault pods are created with the cert.
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get vault deployment: %v", err)
+	}
+	h := fmt.Sprintf("%x", sha256.Sum256(cert))
+	cur, ok := d.Spec.Template.Annotations[serverCertHashAnnotation]
+	if cur == h || (!ok && !force) {
+		return nil
+	}
+	if d.Spec.Template.Annotations == nil {
+		d.Spec.Template.Annotations = map[string]string{}
+	}
+	d.Spec.Template.Annotations[serverCertHashAnnotation] = h
+	mu := intstr.FromInt(int(vr.Spec.Nodes - 1))
+	d.Spec.Strategy.RollingUpdate.MaxUnavailable = &mu
+	_, err = kubecli.AppsV1beta1().Deployments(d.Namespace).Update(d)
+	if err != nil {
+		return fmt.Errorf("failed to restart vault pods for the server cert: %v", err)
+	}
+	return nil
+}
+
+// RollbackDeployment rolls the vault deployment back to the given image after a failed upgrade,
+// and restores the maxUnavailable=1 that UpgradeDeployment raised.
+// The pods of the image that are still running, like the active one, are kept by its replica set.
+func RollbackDeployment(kubecli kubernetes.Interface, d *appsv1beta1.Deployment, image string) error {
+	mu := intstr.FromInt(1)
+	d.Spec.Strategy.RollingUpdate.MaxUnavailable = &mu
+	d.Spec.Template.Spec.Containers[0].Image = image
 	_, err := kubecli.AppsV1beta1().Deployments(d.Namespace).Update(d)
 	if err != nil {
-		return fmt.Errorf("failed to upgrade deployment to (%s): %v", vaultImage(vr.Spec), err)
+		return fmt.Errorf("failed to roll back deployment to (%s): %v", image, err)
 	}
 	return nil
 }
 
-func applyPodPolicy(s *v1.PodSpec, p *api.PodPolicy) {
+// applyPodPolicy applies the pod policy of the vault service to the vault pod template.
+// Without a pod policy, vault pods are still spread across nodes and zones by default.
+// It must be called after all the containers of the vault operator are set up;
+// the containers given by the user, like the audit sidecar, are left as they are.
+func applyPodPolicy(pt *v1.PodTemplateSpec, v *api.VaultService) {
+	s := &pt.Spec
+	p := v.Spec.Pod
+	if p == nil {
+		s.Affinity = vaultPodAntiAffinity(v.Name, nil)
+		return
+	}
+
 	for i := range s.Containers {
-		s.Containers[i].Resources = p.Resources
+		c := &s.Containers[i]
+		switch {
+		case c.Name == vaultContainerName && p.VaultResources != nil:
+			c.Resources = *p.VaultResources
+		case c.Name == exporterContainerName && p.ExporterResources != nil:
+			c.Resources = *p.ExporterResources
+		case presentIn(c.Name, vaultContainerName, exporterContainerName):
+			c.Resources = p.Resources
+		}
 	}
 
 	for i := range s.InitContainers {
-		s.InitContainers[i].Resources = p.Resources
+		c := &s.InitContainers[i]
+		if strings.HasPrefix(c.Name, pluginContainerPrefix) || c.Name == auditLinkContainerName {
+			c.Resources = p.Resources
+		}
+	}
+
+	s.NodeSelector = p.NodeSelector
+	s.Tolerations = p.Tolerations
+	s.PriorityClassName = p.PriorityClassName
+	s.Affinity = p.Affinity
+	if s.Affinity == nil {
+		s.Affinity = vaultPodAntiAffinity(v.Name, p.TopologySpread)
+	}
+
+	if len(p.Labels) != 0 {
+		// The labels of the pod template are shared with the selectors of the vault deployment and service.
+		l := make(map[string]string, len(pt.Labels)+len(p.Labels))
+		mergeLabels(l, pt.Labels)
+		mergeLabels(l, p.Labels)
+		pt.Labels = l
+	}
+	if len(p.Annotations) != 0 {
+		pt.Annotations = make(map[string]string, len(p.Annotations))
+		mergeLabels(pt.Annotations, p.Annotations)
+	}
+	if len(p.ServiceAccountName) != 0 {
+		s.ServiceAccountName = p.ServiceAccountName
+	}
+	s.ImagePullSecrets = p.ImagePullSecrets
+	s.SecurityContext = p.SecurityContext
+	if p.ContainerSecurityContext != nil {
+		for i := range s.Containers {
+			c := &s.Containers[i]
+			if !presentIn(c.Name, vaultContainerName, exporterContainerName) {
+				continue
+			}
+			sc := p.ContainerSecurityContext.DeepCopy()
+			if c.Name == vaultContainerName {
+				withIPCLock(sc)
+			}
+			c.SecurityContext = sc
+		}
+		// The audit log directory of the pod must be writable by the user vault runs as.
+		for i := range s.InitContainers {
+			if c := &s.InitContainers[i]; c.Name == auditLinkContainerName {
+				c.SecurityContext = p.ContainerSecurityContext.DeepCopy()
+			}
+		}
 	}
 }
 
-func vaultImage(vs api.VaultServiceSpec) string {
+// mergeLabels adds the labels in l2 to l1 without overwriting the existing ones in l1.
+func mergeLabels(l1, l2 map[string]string) {
+	for k, v := range l2 {
+		if _, ok := l1[k]; ok {
+			continue
+		}
+		l1[k] = v
+	}
+}
+
+// withIPCLock makes sure the security context allows the IPC_LOCK capability,
+// which vault requires to lock its memory.
+func withIPCLock(sc *v1.SecurityContext) {
+	if sc.Capabilities == nil {
+		sc.Capabilities = &v1.Capabilities{}
+	}
+	var drop []v1.Capability
+	for _, c := range sc.Capabilities.Drop {
+		if c != ipcLockCapability {
+			drop = append(drop, c)
+		}
+	}
+	sc.Capabilities.Drop = drop
+	for _, c := range sc.Capabilities.Add {
+		if c == ipcLockCapability {
+			return
+		}
+	}
+	sc.Capabilities.Add = append(sc.Capabilities.Add, ipcLockCapability)
+}
+
+// vaultPodAntiAffinity returns the anti-affinity that spreads the pods of the given vault
+// across the domains of every topology key of the spread policy.
+func vaultPodAntiAffinity(vaultName string, tp *api.TopologySpreadPolicy) *v1.Affinity {
+	sel := &metav1.LabelSelector{MatchLabels: LabelsForVault(vaultName)}
+	aa := &v1.PodAntiAffinity{}
+	for _, key := range tp.GetTopologyKeys() {
+		term := v1.PodAffinityTerm{
+			LabelSelector: sel,
+			TopologyKey:   key,
+		}
+		if tp.IsRequired() {
+			aa.RequiredDuringSchedulingIgnoredDuringExecution = append(aa.RequiredDuringSchedulingIgnoredDuringExecution, term)
+			continue
+		}
+		aa.PreferredDuringSchedulingIgnoredDuringExecution = append(aa.PreferredDuringSchedulingIgnoredDuringExecution, v1.WeightedPodAffinityTerm{
+			Weight:          100,
+			PodAffinityTerm: term,
+		})
+	}
+	return &v1.Affinity{PodAntiAffinity: aa}
+}
+
+// VaultImage returns the vault image of the version of the given vault service spec.
+func VaultImage(vs api.VaultServiceSpec) string {
 	return fmt.Sprintf("%s:%s", vs.BaseImage, vs.Version)
 }
 
 func IsVaultVersionMatch(ps v1.PodSpec, vs api.VaultServiceSpec) bool {
-	return ps.Containers[0].Image == vaultImage(vs)
+	return ps.Containers[0].Image == VaultImage(vs)
+}
+
+// IsVaultClientAuthConfigured checks if the given vault pod spec mounts the client cert
+// that the probes present to a vault listener requiring client certs.
+func IsVaultClientAuthConfigured(ps v1.PodSpec) bool {
+	for _, vol := range ps.Volumes {
+		if vol.Name != vaultTLSAssetVolume || vol.Projected == nil {
+			continue
+		}
+		for _, src := range vol.Projected.Sources {
+			if src.Secret == nil {
+				continue
+			}
+			for _, item := range src.Secret.Items {
+				if item.Key == api.ClientTLSCertName {
+					return true
+				}
+			}
+		}
+	}
+	return false
 }
 
-// VaultTLSFromSecret reads Vault CR's TLS secret and converts it into a vault client's TLS config struct.
-func VaultTLSFromSecret(kubecli kubernetes.Interface, vr *api.VaultService) (*vaultapi.TLSConfig, error) {
+// NewVaultPodClient returns a vault client that talks to the given vault pod at its IP.
+// The pod's server cert is verified against the name of the vault service, which every vault server cert is valid for,
+// so that no pod DNS record is needed.
+func NewVaultPodClient(p *v1.Pod, tlsConfig *tls.Config) (*vaultapi.Client, error) {
+	cfg := tlsConfig.Clone()
+	cfg.ServerName = VaultServiceHost(p.Labels[vaultClusterLabel], p.Namespace)
+	return vaultutil.NewClient(p.Status.PodIP, strconv.Itoa(VaultClientPort), cfg)
+}
+
+// VaultTLSFromSecret reads Vault CR's TLS secret and converts it into a vault client's TLS config.
+func VaultTLSFromSecret(kubecli kubernetes.Interface, vr *api.VaultService) (*tls.Config, error) {
 	secretName := vr.Spec.TLS.Static.ClientSecret
 
 	secret, err := kubecli.CoreV1().Secrets(vr.GetNamespace()).Get(secretName, metav1.GetOptions{})
 	if err != nil {
 		return nil, fmt.Errorf("read client tls failed: failed to get secret (%s): %v", secretName, err)
 	}
+	return NewVaultTLSConfig(secret, api.IsClientAuthEnabled(vr.Spec.TLS))
+}
 
-	// Read the secret and write ca.crt to a temporary file
-	caCertData := secret.Data[api.CATLSCertName]
-	f, err := ioutil.TempFile("", api.CATLSCertName)
-	if err != nil {
-		return nil, fmt.Errorf("read client tls failed: create temp file failed: %v", err)
+// NewVaultTLSConfig builds a vault client's TLS config in memory from the given client secret.
+// The CA cert in the secret verifies the vault servers.
+// If clientAuth is true, the client cert and key in the secret are presented to the vault servers.
+func NewVaultTLSConfig(secret *v1.Secret, clientAuth bool) (*tls.Config, error) {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(secret.Data[api.CATLSCertName]) {
+		return nil, fmt.Errorf("read client tls failed: no valid %s in secret (%s)", api.CATLSCertName, secret.Name)
+	}
+	tlsConfig := &tls.Config{
+		RootCAs:    pool,
+		MinVersion: tls.VersionTLS12,
+	}
+	if !clientAuth {
+		return tlsConfig, nil
 	}
-	defer f.Close()
 
-	_, err = f.Write(caCertData)
+	cert, err := tls.X509KeyPair(secret.Data[api.ClientTLSCertName], secret.Data[api.ClientTLSKeyName])
 	if err != nil {
-		return nil, fmt.Errorf("read client tls failed: write ca cert file failed: %v", err)
+		return nil, fmt.Errorf("read client tls failed: invalid client cert in secret (%s): %v", secret.Name, err)
 	}
-	if err = f.Sync(); err != nil {
-		return nil, fmt.Errorf("read client tls failed: sync ca cert file failed: %v", err)
+	// Like the vault api client, always present the client cert regardless of the CAs requested by the server.
+	tlsConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+		return &cert, nil
 	}
-	return &vaultapi.TLSConfig{CACert: f.Name()}, nil
+	return tlsConfig, nil
 }
 
 // IsPodReady checks the status of the pod for the Ready condition
@@ -419,11 +727,55 @@ func DestroyVault(kubecli kubernetes.Interface, v *api.VaultService) error {
 		return err
 	}
 
-	err = kubecli.CoreV1().Services(ns).Delete(n, do)
+	for _, svc := range []string{n, VaultStandbyServiceName(n), VaultHeadlessServiceName(n)} {
+		err = kubecli.CoreV1().Services(ns).Delete(svc, do)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	err = kubecli.ExtensionsV1beta1().Ingresses(ns).Delete(n, do)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	err = deleteVaultRoute(kubecli, ns, n)
+	if err != nil {
+		return err
+	}
+
+	for _, pdb := range []string{n, EtcdPDBName(n)} {
+		err = kubecli.PolicyV1beta1().PodDisruptionBudgets(ns).Delete(pdb, do)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	err = deleteNetworkPolicies(kubecli, v)
+	if err != nil {
+		return err
+	}
+
+	err = kubecli.CoreV1().ServiceAccounts(ns).Delete(VaultServiceAccountName(n), do)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	err = kubecli.RbacV1().RoleBindings(ns).Delete(VaultServiceAccountName(n), do)
 	if err != nil && !apierrors.IsNotFound(err) {
 		return err
 	}
 
+	err = kubecli.RbacV1().Roles(ns).Delete(VaultServiceAccountName(n), do)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+
+	err = DeleteVaultTokenReviewBinding(kubecli, v)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -437,10 +789,18 @@ func EtcdURLForVault(name string) string {
 	return fmt.Sprintf("https://%s-client:2379", EtcdNameForVault(name))
 }
 
+// VaultActiveLabel is the label of vault pods telling whether the pod is the active vault node.
+// It is maintained by the operator from the health of the vault nodes:
+// "true" on the active node, "false" on the unsealed standby nodes, and unset on the other nodes.
+const VaultActiveLabel = "vault-active"
+
+// vaultClusterLabel is the label of the resources of a vault service, whose value is the name of the vault service.
+const vaultClusterLabel = "vault_cluster"
+
 // LabelsForVault returns the labels for selecting the resources
 // belonging to the given vault name.
 func LabelsForVault(name string) map[string]string {
-	return map[string]string{"app": "vault", "vault_cluster": name}
+	return map[string]string{"app": "vault", vaultClusterLabel: name}
 }
 
 // configEtcdBackendTLS configures the volume and mounts in vault pod to
@@ -480,4 +840,40 @@ func configVaultServerTLS(pt *v1.PodTemplateSpec, v *api.VaultService) {
 		},
 	}
 	pt.Spec.Volumes[1].VolumeSource.Projected.Sources = append(pt.Spec.Volumes[1].VolumeSource.Projected.Sources, serverTLSVolume)
+
+	if api.IsClientAuthEnabled(v.Spec.TLS) {
+		configVaultClientAuth(pt, v)
+	}
+}
+
+// configVaultClientAuth mounts the CA that the vault listener verifies client certs against,
+// and the client cert that the probes present to the vault listener.
+func configVaultClientAuth(pt *v1.PodTemplateSpec, v *api.VaultService) {
+	clientTLSVolume := v1.VolumeProjection{
+		Secret: &v1.SecretProjection{
+			LocalObjectReference: v1.LocalObjectReference{
+				Name: v.Spec.TLS.Static.ClientSecret,
+			},
+			Items: []v1.KeyToPath{
+				{Key: api.CATLSCertName, Path: api.CATLSCertName},
+				{Key: api.ClientTLSCertName, Path: api.ClientTLSCertName},
+				{Key: api.ClientTLSKeyName, Path: api.ClientTLSKeyName},
+			},
+		},
+	}
+	pt.Spec.Volumes[1].VolumeSource.Projected.Sources = append(pt.Spec.Volumes[1].VolumeSource.Projected.Sources, clientTLSVolume)
+
+	certArgs := []string{
+		"--cert", filepath.Join(vaultutil.VaultTLSAssetDir, api.ClientTLSCertName),
+		"--key", filepath.Join(vaultutil.VaultTLSAssetDir, api.ClientTLSKeyName),
+	}
+	c := &pt.Spec.Containers[0]
+	c.LivenessProbe.Handler = v1.Handler{
+		Exec: &v1.ExecAction{Command: curlVaultHealthCmd(certArgs...)},
+	}
+	// HTTPGet probes cannot present a client cert.
+	// "-f" keeps the HTTPGet semantics of failing on HTTP status codes >= 400.
+	c.ReadinessProbe.Handler = v1.Handler{
+		Exec: &v1.ExecAction{Command: curlVaultHealthCmd(append(certArgs, "-f")...)},
+	}
 }
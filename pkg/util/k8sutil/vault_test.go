@@ -0,0 +1,551 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sutil
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+	"github.com/coreos/vault-operator/pkg/util/tlsutil"
+	"github.com/coreos/vault-operator/pkg/util/vaultutil"
+
+	appsv1beta1 "k8s.io/api/apps/v1beta1"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// newTestVaultService returns a vault service with the defaults set, as the operator sees it.
+func newTestVaultService() *api.VaultService {
+	vr := &api.VaultService{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "example",
+			Namespace: "default",
+		},
+	}
+	vr.SetDefaults()
+	return vr
+}
+
+func TestIsVaultClientAuthConfigured(t *testing.T) {
+	tests := []struct {
+		name       string
+		clientAuth *api.ClientAuthPolicy
+		want       bool
+	}{
+		{"disabled", nil, false},
+		{"enabled", &api.ClientAuthPolicy{}, true},
+		{"enabled with consumer secrets", &api.ClientAuthPolicy{ClientSecrets: []string{"app-tls"}}, true},
+	}
+	for _, tt := range tests {
+		vr := newTestVaultService()
+		vr.Spec.TLS.ClientAuth = tt.clientAuth
+		pt := newVaultPodTemplate(vr)
+		if got := IsVaultClientAuthConfigured(pt.Spec); got != tt.want {
+			t.Errorf("%s: IsVaultClientAuthConfigured() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestVaultProbesPresentClientCert(t *testing.T) {
+	vr := newTestVaultService()
+	c := newVaultPodTemplate(vr).Spec.Containers[0]
+	if c.ReadinessProbe.HTTPGet == nil {
+		t.Errorf("client auth disabled: want an HTTP readiness probe, got %+v", c.ReadinessProbe.Handler)
+	}
+
+	vr.Spec.TLS.ClientAuth = &api.ClientAuthPolicy{}
+	c = newVaultPodTemplate(vr).Spec.Containers[0]
+	certArgs := fmt.Sprintf("--cert %s --key %s",
+		filepath.Join(vaultutil.VaultTLSAssetDir, api.ClientTLSCertName),
+		filepath.Join(vaultutil.VaultTLSAssetDir, api.ClientTLSKeyName))
+	for _, p := range []*v1.Probe{c.LivenessProbe, c.ReadinessProbe} {
+		if p.Exec == nil {
+			t.Fatalf("client auth enabled: want exec probes, got %+v", p.Handler)
+		}
+		if cmd := strings.Join(p.Exec.Command, " "); !strings.Contains(cmd, certArgs) {
+			t.Errorf("client auth enabled: probe (%s) does not present the client cert", cmd)
+		}
+	}
+}
+
+// newTestClientSecret returns a vault client secret with a CA bundle and a client cert signed by that CA.
+func newTestClientSecret(t *testing.T) *v1.Secret {
+	caKey, err := tlsutil.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	caCrt, err := tlsutil.NewSelfSignedCACertificate(tlsutil.CertConfig{CommonName: "test ca"}, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := tlsutil.NewPrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	crt, err := tlsutil.NewSignedCertificate(tlsutil.CertConfig{
+		CommonName:   "test",
+		ExtKeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, key, caCrt, caKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-client-tls"},
+		Data: map[string][]byte{
+			api.CATLSCertName:     tlsutil.EncodeCertificatePEM(caCrt),
+			api.ClientTLSCertName: tlsutil.EncodeCertificatePEM(crt),
+			api.ClientTLSKeyName:  tlsutil.EncodePrivateKeyPEM(key),
+		},
+	}
+}
+
+func TestNewVaultTLSConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		clientAuth bool
+		// modify breaks the valid client secret of the test case.
+		modify func(se *v1.Secret)
+		// wantErr is a substring of the expected error, or empty if the secret is valid.
+		wantErr string
+	}{{
+		name:   "valid",
+		modify: func(*v1.Secret) {},
+	}, {
+		name:       "valid with client auth",
+		clientAuth: true,
+		modify:     func(*v1.Secret) {},
+	}, {
+		name:   "client cert not needed without client auth",
+		modify: func(se *v1.Secret) { delete(se.Data, api.ClientTLSKeyName) },
+	}, {
+		name:    "no CA",
+		modify:  func(se *v1.Secret) { delete(se.Data, api.CATLSCertName) },
+		wantErr: "no valid " + api.CATLSCertName,
+	}, {
+		name:       "no client key",
+		clientAuth: true,
+		modify:     func(se *v1.Secret) { delete(se.Data, api.ClientTLSKeyName) },
+		wantErr:    "invalid client cert",
+	}}
+	for _, tt := range tests {
+		se := newTestClientSecret(t)
+		tt.modify(se)
+		c, err := NewVaultTLSConfig(se, tt.clientAuth)
+		if len(tt.wantErr) != 0 {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: error = %v, want error containing %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		if c.MinVersion != tls.VersionTLS12 {
+			t.Errorf("%s: min TLS version = %x, want %x", tt.name, c.MinVersion, tls.VersionTLS12)
+		}
+		if len(c.RootCAs.Subjects()) != 1 {
+			t.Errorf("%s: root CAs = %d, want 1", tt.name, len(c.RootCAs.Subjects()))
+		}
+		if hasCert := c.GetClientCertificate != nil; hasCert != tt.clientAuth {
+			t.Errorf("%s: presents client cert = %v, want %v", tt.name, hasCert, tt.clientAuth)
+		}
+	}
+}
+
+func TestVaultPodScheduling(t *testing.T) {
+	preferred := func(keys ...string) *v1.Affinity {
+		aa := &v1.PodAntiAffinity{}
+		for _, k := range keys {
+			aa.PreferredDuringSchedulingIgnoredDuringExecution = append(aa.PreferredDuringSchedulingIgnoredDuringExecution, v1.WeightedPodAffinityTerm{
+				Weight: 100,
+				PodAffinityTerm: v1.PodAffinityTerm{
+					LabelSelector: &metav1.LabelSelector{MatchLabels: LabelsForVault("example")},
+					TopologyKey:   k,
+				},
+			})
+		}
+		return &v1.Affinity{PodAntiAffinity: aa}
+	}
+	required := func(keys ...string) *v1.Affinity {
+		aa := &v1.PodAntiAffinity{}
+		for _, k := range keys {
+			aa.RequiredDuringSchedulingIgnoredDuringExecution = append(aa.RequiredDuringSchedulingIgnoredDuringExecution, v1.PodAffinityTerm{
+				LabelSelector: &metav1.LabelSelector{MatchLabels: LabelsForVault("example")},
+				TopologyKey:   k,
+			})
+		}
+		return &v1.Affinity{PodAntiAffinity: aa}
+	}
+	nodeAffinity := &v1.Affinity{NodeAffinity: &v1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &v1.NodeSelector{
+			NodeSelectorTerms: []v1.NodeSelectorTerm{{
+				MatchExpressions: []v1.NodeSelectorRequirement{{Key: "dedicated", Operator: v1.NodeSelectorOpExists}},
+			}},
+		},
+	}}
+
+	tests := []struct {
+		name string
+		pod  *api.PodPolicy
+		want *v1.Affinity
+	}{{
+		name: "no pod policy",
+		want: preferred(api.TopologyKeyHostname, api.TopologyKeyZone),
+	}, {
+		name: "empty pod policy",
+		pod:  &api.PodPolicy{},
+		want: preferred(api.TopologyKeyHostname, api.TopologyKeyZone),
+	}, {
+		name: "custom topology keys",
+		pod:  &api.PodPolicy{TopologySpread: &api.TopologySpreadPolicy{TopologyKeys: []string{"rack"}}},
+		want: preferred("rack"),
+	}, {
+		name: "required spread with default keys",
+		pod:  &api.PodPolicy{TopologySpread: &api.TopologySpreadPolicy{Required: true}},
+		want: required(api.TopologyKeyHostname, api.TopologyKeyZone),
+	}, {
+		name: "affinity replaces the spread",
+		pod: &api.PodPolicy{
+			Affinity:       nodeAffinity,
+			TopologySpread: &api.TopologySpreadPolicy{Required: true},
+		},
+		want: nodeAffinity,
+	}, {
+		name: "empty affinity disables the spread",
+		pod:  &api.PodPolicy{Affinity: &v1.Affinity{}},
+		want: &v1.Affinity{},
+	}}
+	for _, tt := range tests {
+		vr := newTestVaultService()
+		vr.Spec.Pod = tt.pod
+		got := newVaultPodTemplate(vr).Spec.Affinity
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: affinity = %+v, want %+v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestVaultPodResources(t *testing.T) {
+	res := func(cpu string) v1.ResourceRequirements {
+		return v1.ResourceRequirements{Requests: v1.ResourceList{v1.ResourceCPU: resource.MustParse(cpu)}}
+	}
+	vaultRes, exporterRes := res("500m"), res("10m")
+
+	tests := []struct {
+		name         string
+		pod          *api.PodPolicy
+		wantVault    v1.ResourceRequirements
+		wantExporter v1.ResourceRequirements
+	}{{
+		name: "no pod policy",
+	}, {
+		name:         "shared resources",
+		pod:          &api.PodPolicy{Resources: res("100m")},
+		wantVault:    res("100m"),
+		wantExporter: res("100m"),
+	}, {
+		name:         "vault resources override",
+		pod:          &api.PodPolicy{Resources: res("100m"), VaultResources: &vaultRes},
+		wantVault:    vaultRes,
+		wantExporter: res("100m"),
+	}, {
+		name:         "per container resources",
+		pod:          &api.PodPolicy{VaultResources: &vaultRes, ExporterResources: &exporterRes},
+		wantVault:    vaultRes,
+		wantExporter: exporterRes,
+	}}
+	sidecarRes := res("1m")
+	for _, tt := range tests {
+		vr := newTestVaultService()
+		vr.Spec.Pod = tt.pod
+		vr.Spec.Plugins = []api.Plugin{{Name: "example-plugin", Image: "example/plugin", Path: "/bin/example-plugin"}}
+		vr.Spec.Audit = &api.AuditPolicy{File: &api.FileAuditDevice{Sidecar: &v1.Container{Name: "audit-shipper", Resources: sidecarRes}}}
+		s := newVaultPodTemplate(vr).Spec
+		for _, c := range s.Containers {
+			var want v1.ResourceRequirements
+			switch c.Name {
+			case vaultContainerName:
+				want = tt.wantVault
+			case exporterContainerName:
+				want = tt.wantExporter
+			default:
+				// The audit sidecar keeps its own resources.
+				want = sidecarRes
+			}
+			if !reflect.DeepEqual(c.Resources, want) {
+				t.Errorf("%s: resources of container %s = %+v, want %+v", tt.name, c.Name, c.Resources, want)
+			}
+		}
+		var want v1.ResourceRequirements
+		if tt.pod != nil {
+			want = tt.pod.Resources
+		}
+		for _, c := range s.InitContainers {
+			if !reflect.DeepEqual(c.Resources, want) {
+				t.Errorf("%s: resources of init container %s = %+v, want %+v", tt.name, c.Name, c.Resources, want)
+			}
+		}
+		if len(s.InitContainers) != 2 {
+			t.Errorf("%s: expect the plugin and audit log init containers, got %d", tt.name, len(s.InitContainers))
+		}
+	}
+}
+
+func TestVaultPodAuditLog(t *testing.T) {
+	vr := newTestVaultService()
+	vr.Spec.Audit = &api.AuditPolicy{File: &api.FileAuditDevice{PersistentVolumeClaimName: "audit"}}
+	s := newVaultPodTemplate(vr).Spec
+
+	var link *v1.Container
+	for i := range s.InitContainers {
+		if s.InitContainers[i].Name == auditLinkContainerName {
+			link = &s.InitContainers[i]
+		}
+	}
+	if link == nil {
+		t.Fatalf("no %s init container", auditLinkContainerName)
+	}
+	// Vault pods sharing the audit log volume must write to separate files.
+	cmd := strings.Join(link.Command, " ")
+	want := "ln -sf /var/log/vault/$POD_NAME/audit.log " + vaultutil.VaultAuditLogPath
+	if !strings.Contains(cmd, want) {
+		t.Errorf("command = %s, want it to contain %s", cmd, want)
+	}
+	if len(link.Env) != 1 || link.Env[0].Name != envPodName || link.Env[0].ValueFrom.FieldRef.FieldPath != "metadata.name" {
+		t.Errorf("env = %+v, want %s from metadata.name", link.Env, envPodName)
+	}
+
+	mounts := map[string]string{}
+	for _, m := range s.Containers[0].VolumeMounts {
+		mounts[m.Name] = m.MountPath
+	}
+	if mounts[vaultAuditVolName] != vaultutil.VaultAuditDir || mounts[vaultAuditLinkVolName] != vaultutil.VaultAuditLinkDir {
+		t.Errorf("vault container mounts = %v, want the audit log and link volumes", mounts)
+	}
+}
+
+func TestVaultPodMetadata(t *testing.T) {
+	tests := []struct {
+		name            string
+		pod             *api.PodPolicy
+		wantLabels      map[string]string
+		wantAnnotations map[string]string
+		wantSA          string
+	}{{
+		name:       "no pod policy",
+		wantLabels: LabelsForVault("example"),
+		wantSA:     VaultServiceAccountName("example"),
+	}, {
+		name: "extra labels and annotations",
+		pod: &api.PodPolicy{
+			Labels:      map[string]string{"team": "security"},
+			Annotations: map[string]string{"sidecar.istio.io/inject": "false"},
+		},
+		wantLabels:      map[string]string{"app": "vault", "vault_cluster": "example", "team": "security"},
+		wantAnnotations: map[string]string{"sidecar.istio.io/inject": "false"},
+		wantSA:          VaultServiceAccountName("example"),
+	}, {
+		name:       "operator labels are not overwritten",
+		pod:        &api.PodPolicy{Labels: map[string]string{"app": "other", "vault_cluster": "other"}},
+		wantLabels: LabelsForVault("example"),
+		wantSA:     VaultServiceAccountName("example"),
+	}, {
+		name:       "service account",
+		pod:        &api.PodPolicy{ServiceAccountName: "vault-sa"},
+		wantLabels: LabelsForVault("example"),
+		wantSA:     "vault-sa",
+	}}
+	for _, tt := range tests {
+		vr := newTestVaultService()
+		vr.Spec.Pod = tt.pod
+		pt := newVaultPodTemplate(vr)
+		if !reflect.DeepEqual(pt.Labels, tt.wantLabels) {
+			t.Errorf("%s: labels = %v, want %v", tt.name, pt.Labels, tt.wantLabels)
+		}
+		if !reflect.DeepEqual(pt.Annotations, tt.wantAnnotations) {
+			t.Errorf("%s: annotations = %v, want %v", tt.name, pt.Annotations, tt.wantAnnotations)
+		}
+		if pt.Spec.ServiceAccountName != tt.wantSA {
+			t.Errorf("%s: service account = %q, want %q", tt.name, pt.Spec.ServiceAccountName, tt.wantSA)
+		}
+	}
+}
+
+func TestVaultPodSecurityContext(t *testing.T) {
+	nonRoot := true
+	tests := []struct {
+		name string
+		sc   *v1.SecurityContext
+		want *v1.Capabilities
+	}{{
+		name: "no capabilities",
+		sc:   &v1.SecurityContext{RunAsNonRoot: &nonRoot},
+		want: &v1.Capabilities{Add: []v1.Capability{ipcLockCapability}},
+	}, {
+		name: "IPC_LOCK dropped",
+		sc:   &v1.SecurityContext{Capabilities: &v1.Capabilities{Drop: []v1.Capability{"ALL", ipcLockCapability}}},
+		want: &v1.Capabilities{Add: []v1.Capability{ipcLockCapability}, Drop: []v1.Capability{"ALL"}},
+	}, {
+		name: "IPC_LOCK already added",
+		sc:   &v1.SecurityContext{Capabilities: &v1.Capabilities{Add: []v1.Capability{ipcLockCapability}}},
+		want: &v1.Capabilities{Add: []v1.Capability{ipcLockCapability}},
+	}}
+	for _, tt := range tests {
+		vr := newTestVaultService()
+		vr.Spec.Pod = &api.PodPolicy{ContainerSecurityContext: tt.sc}
+		for _, c := range newVaultPodTemplate(vr).Spec.Containers {
+			want := tt.sc.Capabilities
+			if c.Name == vaultContainerName {
+				want = tt.want
+			}
+			if c.SecurityContext == nil || !reflect.DeepEqual(c.SecurityContext.Capabilities, want) {
+				t.Errorf("%s: security context of container %s = %+v, want capabilities %+v", tt.name, c.Name, c.SecurityContext, want)
+			}
+		}
+	}
+}
+
+func TestValidateImmutableVaultSpec(t *testing.T) {
+	tests := []struct {
+		name    string
+		update  func(vr *api.VaultService)
+		wantErr string
+	}{{
+		name:   "unchanged",
+		update: func(*api.VaultService) {},
+	}, {
+		name:   "nodes updated",
+		update: func(vr *api.VaultService) { vr.Spec.Nodes = 3 },
+	}, {
+		name:    "pod policy added",
+		update:  func(vr *api.VaultService) { vr.Spec.Pod = &api.PodPolicy{NodeSelector: map[string]string{"disk": "ssd"}} },
+		wantErr: "pod cannot be updated",
+	}, {
+		name:    "plugin added",
+		update:  func(vr *api.VaultService) { vr.Spec.Plugins = []api.Plugin{{Name: "example", Image: "example/plugin:v1"}} },
+		wantErr: "plugins cannot be updated",
+	}}
+	for _, tt := range tests {
+		vr := newTestVaultService()
+		d := &appsv1beta1.Deployment{}
+		if !RecordImmutableVaultSpec(d, vr) {
+			t.Errorf("%s: spec not recorded on a new deployment", tt.name)
+		}
+		tt.update(vr)
+		if RecordImmutableVaultSpec(d, vr) {
+			t.Errorf("%s: recorded spec overwritten", tt.name)
+		}
+		err := ValidateImmutableVaultSpec(d, vr)
+		if len(tt.wantErr) == 0 {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", tt.name, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: error = %v, want error containing %q", tt.name, err, tt.wantErr)
+		}
+	}
+
+	// Deployments created before the spec was recorded are not rejected.
+	vr := newTestVaultService()
+	vr.Spec.Pod = &api.PodPolicy{}
+	if err := ValidateImmutableVaultSpec(&appsv1beta1.Deployment{}, vr); err != nil {
+		t.Errorf("unrecorded deployment: unexpected error: %v", err)
+	}
+}
+
+func TestRestartVaultPodsForServerCert(t *testing.T) {
+	tests := []struct {
+		name string
+		// recorded is the cert recorded on the pod template, if any.
+		recorded    []byte
+		cert        []byte
+		force       bool
+		wantRestart bool
+	}{{
+		name: "not recorded",
+		cert: []byte("new"),
+	}, {
+		name:        "reissued",
+		cert:        []byte("new"),
+		force:       true,
+		wantRestart: true,
+	}, {
+		name:        "restart not applied yet",
+		recorded:    []byte("old"),
+		cert:        []byte("new"),
+		wantRestart: true,
+	}, {
+		name:     "restarted",
+		recorded: []byte("new"),
+		cert:     []byte("new"),
+		force:    true,
+	}}
+	for _, tt := range tests {
+		vr := newTestVaultService()
+		vr.Spec.Nodes = 3
+		mu := intstr.FromInt(1)
+		kubecli := fake.NewSimpleClientset(&appsv1beta1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: vr.Name, Namespace: vr.Namespace},
+			Spec: appsv1beta1.DeploymentSpec{
+				Template: newVaultPodTemplate(vr),
+				Strategy: appsv1beta1.DeploymentStrategy{
+					RollingUpdate: &appsv1beta1.RollingUpdateDeployment{MaxUnavailable: &mu},
+				},
+			},
+		})
+		if tt.recorded != nil {
+			if err := RestartVaultPodsForServerCert(kubecli, vr, tt.recorded, true); err != nil {
+				t.Fatal(err)
+			}
+		}
+		before, err := kubecli.AppsV1beta1().Deployments(vr.Namespace).Get(vr.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err = RestartVaultPodsForServerCert(kubecli, vr, tt.cert, tt.force); err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+			continue
+		}
+		d, err := kubecli.AppsV1beta1().Deployments(vr.Namespace).Get(vr.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		restarted := !reflect.DeepEqual(before.Spec.Template, d.Spec.Template)
+		if restarted != tt.wantRestart {
+			t.Errorf("%s: restarted = %v, want %v", tt.name, restarted, tt.wantRestart)
+		}
+		if tt.wantRestart && d.Spec.Strategy.RollingUpdate.MaxUnavailable.IntValue() != 2 {
+			t.Errorf("%s: maxUnavailable = %v, want 2", tt.name, d.Spec.Strategy.RollingUpdate.MaxUnavailable)
+		}
+	}
+
+	// The vault pods are created with the cert.
+	if err := RestartVaultPodsForServerCert(fake.NewSimpleClientset(), newTestVaultService(), []byte("new"), true); err != nil {
+		t.Errorf("no deployment: unexpected error: %v", err)
+	}
+}
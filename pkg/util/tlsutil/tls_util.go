@@ -17,10 +17,12 @@ package tlsutil
 import (
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
 	"errors"
+	"fmt"
 	"math"
 	"math/big"
 	"net"
@@ -38,6 +40,9 @@ type CertConfig struct {
 	CommonName   string
 	Organization []string
 	AltNames     AltNames
+	// ExtKeyUsages is the set of purposes the signed certificate is valid for,
+	// e.g. x509.ExtKeyUsageServerAuth or x509.ExtKeyUsageClientAuth.
+	ExtKeyUsages []x509.ExtKeyUsage
 }
 
 // AltNames contains the domain names and IP addresses that will be added
@@ -109,9 +114,9 @@ func NewSelfSignedCACertificate(cfg CertConfig, key *rsa.PrivateKey) (*x509.Cert
 		},
 		NotBefore:             now.UTC(),
 		NotAfter:              now.Add(duration365d).UTC(),
-		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
 		BasicConstraintsValid: true,
-		IsCA: true,
+		IsCA:                  true,
 	}
 
 	certDERBytes, err := x509.CreateCertificate(rand.Reader, &tmpl, &tmpl, key.Public(), key)
@@ -130,6 +135,36 @@ func ParsePEMEncodedCACert(pemdata []byte) (*x509.Certificate, error) {
 	return x509.ParseCertificate(decoded.Bytes)
 }
 
+// ParsePEMEncodedCerts parses all certificates from the given pemdata
+func ParsePEMEncodedCerts(pemdata []byte) ([]*x509.Certificate, error) {
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, pemdata = pem.Decode(pemdata)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			return nil, fmt.Errorf("unexpected PEM block type (%s)", block.Type)
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, errors.New("no PEM data found")
+	}
+	return certs, nil
+}
+
+// VerifyKeyPair checks that the given PEM encoded certificate and private key belong together.
+func VerifyKeyPair(certPEM, keyPEM []byte) error {
+	_, err := tls.X509KeyPair(certPEM, keyPEM)
+	return err
+}
+
 // ParsePEMEncodedPrivateKey parses a private key from given pemdata
 func ParsePEMEncodedPrivateKey(pemdata []byte) (*rsa.PrivateKey, error) {
 	decoded, _ := pem.Decode(pemdata)
@@ -140,14 +175,23 @@ func ParsePEMEncodedPrivateKey(pemdata []byte) (*rsa.PrivateKey, error) {
 }
 
 // NewSignedCertificate signs a certificate using the given private key, CA and returns a signed certificate.
-// The certificate could be used for both client and server auth.
+// The certificate is only valid for the extended key usages given in cfg.
 // The certificate has one-year lease.
 func NewSignedCertificate(cfg CertConfig, key *rsa.PrivateKey, caCert *x509.Certificate, caKey *rsa.PrivateKey) (*x509.Certificate, error) {
+	if len(cfg.ExtKeyUsages) == 0 {
+		return nil, errors.New("no extended key usage specified")
+	}
 	serial, err := rand.Int(rand.Reader, new(big.Int).SetInt64(math.MaxInt64))
 	if err != nil {
 		return nil, err
 	}
 
+	keyUsage := x509.KeyUsageDigitalSignature
+	if hasExtKeyUsage(cfg.ExtKeyUsages, x509.ExtKeyUsageServerAuth) {
+		// RSA key exchange on the server side encrypts the session key with the server's public key.
+		keyUsage |= x509.KeyUsageKeyEncipherment
+	}
+
 	certTmpl := x509.Certificate{
 		Subject: pkix.Name{
 			CommonName:   cfg.CommonName,
@@ -158,8 +202,8 @@ func NewSignedCertificate(cfg CertConfig, key *rsa.PrivateKey, caCert *x509.Cert
 		SerialNumber: serial,
 		NotBefore:    caCert.NotBefore,
 		NotAfter:     time.Now().Add(duration365d).UTC(),
-		KeyUsage:     x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
-		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		KeyUsage:     keyUsage,
+		ExtKeyUsage:  cfg.ExtKeyUsages,
 	}
 	certDERBytes, err := x509.CreateCertificate(rand.Reader, &certTmpl, caCert, key.Public(), caKey)
 	if err != nil {
@@ -167,3 +211,12 @@ func NewSignedCertificate(cfg CertConfig, key *rsa.PrivateKey, caCert *x509.Cert
 	}
 	return x509.ParseCertificate(certDERBytes)
 }
+
+func hasExtKeyUsage(usages []x509.ExtKeyUsage, u x509.ExtKeyUsage) bool {
+	for _, usage := range usages {
+		if usage == u {
+			return true
+		}
+	}
+	return false
+}
@@ -16,7 +16,9 @@ package vaultutil
 
 import (
 	"bytes"
+	"crypto/tls"
 	"fmt"
+	"net/http"
 	"path/filepath"
 
 	vaultapi "github.com/hashicorp/vault/api"
@@ -29,6 +31,14 @@ const (
 	ServerTLSCertName = "server.crt"
 	// ServerTLSKeyName is the filename of the vault server key
 	ServerTLSKeyName = "server.key"
+	// VaultPluginDir is the dir where vault's plugin binaries sit
+	VaultPluginDir = "/run/vault/plugins"
+	// VaultAuditDir is the dir where vault's file audit device writes the audit log
+	VaultAuditDir = "/var/log/vault"
+	// VaultAuditLinkDir is the dir of the link to the audit log of a vault pod, in a dir named after the pod under VaultAuditDir
+	VaultAuditLinkDir = "/run/vault/audit"
+	// VaultAuditLogPath is the path vault's file audit device writes the audit log to
+	VaultAuditLogPath = VaultAuditLinkDir + "/audit.log"
 )
 
 var listenerFmt = `
@@ -37,7 +47,11 @@ listener "tcp" {
   cluster_address = "0.0.0.0:8201"
   tls_cert_file = "%s"
   tls_key_file  = "%s"
-}
+%s}
+`
+
+var clientAuthFmt = `  tls_require_and_verify_client_cert = "true"
+  tls_client_ca_file = "%s"
 `
 
 var etcdStorageFmt = `
@@ -55,7 +69,9 @@ storage "etcd" {
 // NewConfigWithDefaultParams appends to given config data some default params:
 // - telemetry setting
 // - tcp listener
-func NewConfigWithDefaultParams(data string) string {
+// If clientCAFile is not empty, the listener requires client certificates
+// and verifies them against the given CA.
+func NewConfigWithDefaultParams(data, clientCAFile string) string {
 	buf := bytes.NewBufferString(data)
 	buf.WriteString(`
 telemetry {
@@ -63,9 +79,14 @@ telemetry {
 }
 `)
 
+	var clientAuthSection string
+	if len(clientCAFile) != 0 {
+		clientAuthSection = fmt.Sprintf(clientAuthFmt, clientCAFile)
+	}
 	listenerSection := fmt.Sprintf(listenerFmt,
 		filepath.Join(VaultTLSAssetDir, ServerTLSCertName),
-		filepath.Join(VaultTLSAssetDir, ServerTLSKeyName))
+		filepath.Join(VaultTLSAssetDir, ServerTLSKeyName),
+		clientAuthSection)
 	buf.WriteString(listenerSection)
 
 	return buf.String()
@@ -80,10 +101,21 @@ func NewConfigWithEtcd(data, etcdURL string) string {
 	return data
 }
 
-func NewClient(hostname string, port string, tlsConfig *vaultapi.TLSConfig) (*vaultapi.Client, error) {
+// NewConfigWithPluginDir returns the new config data combining
+// original config and the plugin directory setting.
+func NewConfigWithPluginDir(data, pluginDir string) string {
+	return fmt.Sprintf("%s\nplugin_directory = \"%s\"\n", data, pluginDir)
+}
+
+// NewClient returns a vault client for the vault server at the given hostname and port.
+// The given TLS config is copied into the client's transport.
+func NewClient(hostname string, port string, tlsConfig *tls.Config) (*vaultapi.Client, error) {
 	cfg := vaultapi.DefaultConfig()
+	if cfg.Error != nil {
+		return nil, cfg.Error
+	}
 	podURL := fmt.Sprintf("https://%s:%s", hostname, port)
 	cfg.Address = podURL
-	cfg.ConfigureTLS(tlsConfig)
+	cfg.HttpClient.Transport.(*http.Transport).TLSClientConfig = tlsConfig.Clone()
 	return vaultapi.NewClient(cfg)
 }
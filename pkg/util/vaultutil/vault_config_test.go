@@ -0,0 +1,54 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vaultutil
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewConfigWithDefaultParams(t *testing.T) {
+	tests := []struct {
+		name         string
+		clientCAFile string
+		want         []string
+		notWant      []string
+	}{{
+		name:    "no client auth",
+		want:    []string{`tls_cert_file = "/run/vault/tls/server.crt"`, `tls_key_file  = "/run/vault/tls/server.key"`},
+		notWant: []string{"tls_require_and_verify_client_cert", "tls_client_ca_file"},
+	}, {
+		name:         "client auth",
+		clientCAFile: "/run/vault/tls/vault-client-ca.crt",
+		want: []string{
+			`tls_require_and_verify_client_cert = "true"`,
+			`tls_client_ca_file = "/run/vault/tls/vault-client-ca.crt"`,
+		},
+	}}
+	for _, tt := range tests {
+		cfg := NewConfigWithDefaultParams("", tt.clientCAFile)
+		listener := cfg[strings.Index(cfg, `listener "tcp"`):]
+		for _, s := range tt.want {
+			if !strings.Contains(listener, s) {
+				t.Errorf("%s: listener section has no %s:\n%s", tt.name, s, listener)
+			}
+		}
+		for _, s := range tt.notWant {
+			if strings.Contains(listener, s) {
+				t.Errorf("%s: listener section should not have %s:\n%s", tt.name, s, listener)
+			}
+		}
+	}
+}
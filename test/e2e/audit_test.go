@@ -0,0 +1,73 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"testing"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+	"github.com/coreos/vault-operator/test/e2e/e2eutil"
+	"github.com/coreos/vault-operator/test/e2e/framework"
+
+	"k8s.io/api/core/v1"
+)
+
+func TestAuditDevices(t *testing.T) {
+	f := framework.Global
+	vaultCR := e2eutil.NewCluster("test-vault-", f.Namespace, 1)
+	vaultCR.Spec.Audit = &api.AuditPolicy{File: &api.FileAuditDevice{}}
+	vaultCR, err := e2eutil.CreateCluster(t, f.VaultsCRClient, vaultCR)
+	if err != nil {
+		t.Fatalf("failed to create vault cluster: %v", err)
+	}
+	defer func(vaultCR *api.VaultService) {
+		if err := e2eutil.DeleteCluster(t, f.VaultsCRClient, vaultCR); err != nil {
+			t.Fatalf("failed to delete vault cluster: %v", err)
+		}
+	}(vaultCR)
+	vaultCR, tlsConfig := e2eutil.WaitForCluster(t, f.KubeClient, f.VaultsCRClient, vaultCR)
+
+	podName := vaultCR.Status.VaultStatus.Sealed[0]
+	vClient := e2eutil.SetupVaultClient(t, f.KubeClient, f.Namespace, tlsConfig, podName)
+	vaultCR, initResp := e2eutil.InitializeVault(t, f.VaultsCRClient, vaultCR, vClient)
+	if err = e2eutil.UnsealVaultNode(initResp.Keys[0], vClient); err != nil {
+		t.Fatalf("failed to unseal vault node (%v): %v", podName, err)
+	}
+	vaultCR, err = e2eutil.WaitActiveVaultsUp(t, f.VaultsCRClient, 6, vaultCR)
+	if err != nil {
+		t.Fatalf("failed to wait for any node to become active: %v", err)
+	}
+	vaultCR, err = e2eutil.SetOperatorToken(t, f.KubeClient, f.VaultsCRClient, vaultCR, initResp.RootToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vaultCR, err = e2eutil.WaitUntilVaultConditionTrue(t, f.VaultsCRClient, 6, vaultCR, func(v *api.VaultService) bool {
+		c := v.Status.GetCondition(api.VaultServiceAuditEnabled)
+		return c != nil && c.Status == v1.ConditionTrue
+	})
+	if err != nil {
+		t.Fatalf("failed to wait for the audit devices to be enabled: %v", err)
+	}
+
+	vClient.SetToken(initResp.RootToken)
+	devices, err := vClient.Sys().ListAudit()
+	if err != nil {
+		t.Fatalf("failed to list audit devices: %v", err)
+	}
+	if d, ok := devices["file/"]; !ok || d.Type != "file" {
+		t.Fatalf("expect a file audit device to be enabled, got: %v", devices)
+	}
+}
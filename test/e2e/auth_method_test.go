@@ -0,0 +1,99 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"testing"
+	"time"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+	"github.com/coreos/vault-operator/test/e2e/e2eutil"
+	"github.com/coreos/vault-operator/test/e2e/framework"
+
+	"github.com/coreos/etcd-operator/pkg/util/retryutil"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestVaultAuthMethod(t *testing.T) {
+	f := framework.Global
+	vaultCR, tlsConfig, rootToken := e2eutil.SetupUnsealedVaultCluster(t, f.KubeClient, f.VaultsCRClient, f.Namespace)
+	defer func(vaultCR *api.VaultService) {
+		if err := e2eutil.DeleteCluster(t, f.VaultsCRClient, vaultCR); err != nil {
+			t.Fatalf("failed to delete vault cluster: %v", err)
+		}
+	}(vaultCR)
+	vaultCR, err := e2eutil.SetOperatorToken(t, f.KubeClient, f.VaultsCRClient, vaultCR, rootToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	authMethods := f.VaultsCRClient.VaultV1alpha1().VaultAuthMethods(f.Namespace)
+	am, err := authMethods.Create(&api.VaultAuthMethod{
+		ObjectMeta: metav1.ObjectMeta{GenerateName: "test-approle-"},
+		Spec: api.VaultAuthMethodSpec{
+			VaultService: vaultCR.Name,
+			Type:         api.AuthMethodAppRole,
+			Roles: []api.AuthRole{{
+				Name:     "test-role",
+				Policies: []string{"default"},
+				TTL:      "20m",
+			}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create VaultAuthMethod: %v", err)
+	}
+
+	err = retryutil.Retry(5*time.Second, 6, func() (bool, error) {
+		am, err = authMethods.Get(am.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return am.Status.Synced, nil
+	})
+	if err != nil {
+		t.Fatalf("failed to wait for VaultAuthMethod to be synced: %v, last status: %+v", err, am.Status)
+	}
+
+	vClient := e2eutil.SetupVaultClient(t, f.KubeClient, f.Namespace, tlsConfig, vaultCR.Status.VaultStatus.Active)
+	vClient.SetToken(rootToken)
+	role, err := vClient.Logical().Read("auth/approle/role/test-role")
+	if err != nil || role == nil {
+		t.Fatalf("failed to read role (test-role) from vault: %v", err)
+	}
+
+	// The auth method is disabled before the VaultAuthMethod goes away.
+	if err = authMethods.Delete(am.Name, nil); err != nil {
+		t.Fatalf("failed to delete VaultAuthMethod: %v", err)
+	}
+	err = retryutil.Retry(5*time.Second, 6, func() (bool, error) {
+		_, err := authMethods.Get(am.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+	if err != nil {
+		t.Fatalf("failed to wait for VaultAuthMethod to be deleted: %v", err)
+	}
+	mounts, err := vClient.Sys().ListAuth()
+	if err != nil {
+		t.Fatalf("failed to list auth methods: %v", err)
+	}
+	if _, ok := mounts["approle/"]; ok {
+		t.Fatalf("expect the approle auth method to be disabled")
+	}
+}
@@ -112,6 +112,55 @@ func createBackup(t *testing.T, vaultCR *api.VaultService, etcdClusterName, s3Pa
 	t.Logf("backup for cluster (%s) has been saved", etcdClusterName)
 }
 
+// createVaultBackup backs up the vault cluster to s3Path with a VaultBackup, and waits for it to succeed.
+func createVaultBackup(t *testing.T, vaultCR *api.VaultService, s3Path string) {
+	f := framework.Global
+	backups := f.VaultsCRClient.VaultV1alpha1().VaultBackups(f.Namespace)
+	vb, err := backups.Create(&api.VaultBackup{
+		ObjectMeta: metav1.ObjectMeta{GenerateName: vaultCR.Name + "-"},
+		Spec: api.VaultBackupSpec{
+			VaultService: vaultCR.Name,
+			S3: &api.S3BackupTarget{
+				Path:      s3Path,
+				AWSSecret: os.Getenv("TEST_AWS_SECRET"),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create vault backup cr: %v", err)
+	}
+	defer func() {
+		if err := backups.Delete(vb.Name, nil); err != nil {
+			t.Fatalf("failed to delete vault backup cr: %v", err)
+		}
+	}()
+
+	// The operator checks on the backup every 10 seconds.
+	err = retryutil.Retry(5*time.Second, 12, func() (bool, error) {
+		vb, err = backups.Get(vb.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, fmt.Errorf("failed to retrieve backup CR: %v", err)
+		}
+		switch vb.Status.Phase {
+		case api.BackupPhaseSucceeded:
+			if vb.Status.EtcdVersion == eopapi.DefaultEtcdVersion && vb.Status.Revision > 1 && vb.Status.Size > 0 {
+				return true, nil
+			}
+			return false, fmt.Errorf("expect EtcdVersion==%v, Revision > 1 and Size > 0, but got %+v", eopapi.DefaultEtcdVersion, vb.Status)
+		case api.BackupPhaseFailed:
+			return false, fmt.Errorf("backup failed with reason: %v ", vb.Status.Message)
+		}
+		return false, nil
+	})
+	if err != nil {
+		t.Fatalf("failed to verify backup: %v", err)
+	}
+	if vb.Status.Location != "s3://"+s3Path {
+		t.Fatalf("expect backup location s3://%s, got %s", s3Path, vb.Status.Location)
+	}
+	t.Logf("backup for vault cluster (%s) has been saved", vaultCR.Name)
+}
+
 func killEtcdCluster(t *testing.T, etcdClusterName string) {
 	f := framework.Global
 	lops := eopk8sutil.ClusterListOpt(etcdClusterName)
@@ -206,3 +255,88 @@ func TestBackupRestoreOnVault(t *testing.T) {
 	restoreEtcdCluster(t, s3Path, etcdClusterName)
 	verifyRestoredVault(t, vaultCR, secretData, keyPath, rootToken)
 }
+
+func TestVaultBackup(t *testing.T) {
+	f := framework.Global
+	s3Path := path.Join(os.Getenv("TEST_S3_BUCKET"), "jenkins", strconv.Itoa(int(rand.Uint64())), time.Now().Format(time.RFC3339), "etcd.backup")
+
+	vaultCR, tlsConfig, rootToken := e2eutil.SetupUnsealedVaultCluster(t, f.KubeClient, f.VaultsCRClient, f.Namespace)
+	defer func(vaultCR *api.VaultService) {
+		if err := e2eutil.DeleteCluster(t, f.VaultsCRClient, vaultCR); err != nil {
+			t.Fatalf("failed to delete vault cluster: %v", err)
+		}
+	}(vaultCR)
+	e2eutil.WriteSecretData(t, vaultCR, f.KubeClient, tlsConfig, rootToken, f.Namespace)
+
+	createVaultBackup(t, vaultCR, s3Path)
+}
+
+func TestRestoreVaultFromBackup(t *testing.T) {
+	f := framework.Global
+	s3Path := path.Join(os.Getenv("TEST_S3_BUCKET"), "jenkins", strconv.Itoa(int(rand.Uint64())), time.Now().Format(time.RFC3339), "etcd.backup")
+
+	vaultCR, err := e2eutil.CreateCluster(t, f.VaultsCRClient, e2eutil.NewCluster("test-vault-", f.Namespace, 1))
+	if err != nil {
+		t.Fatalf("failed to create vault cluster: %v", err)
+	}
+	defer func(vaultCR *api.VaultService) {
+		if err := e2eutil.DeleteCluster(t, f.VaultsCRClient, vaultCR); err != nil {
+			t.Fatalf("failed to delete vault cluster: %v", err)
+		}
+	}(vaultCR)
+	vaultCR, tlsConfig := e2eutil.WaitForCluster(t, f.KubeClient, f.VaultsCRClient, vaultCR)
+
+	// The unseal key is kept to unseal the restored vault.
+	podName := vaultCR.Status.VaultStatus.Sealed[0]
+	vClient := e2eutil.SetupVaultClient(t, f.KubeClient, f.Namespace, tlsConfig, podName)
+	vaultCR, initResp := e2eutil.InitializeVault(t, f.VaultsCRClient, vaultCR, vClient)
+	if err := e2eutil.UnsealVaultNode(initResp.Keys[0], vClient); err != nil {
+		t.Fatalf("failed to unseal vault node(%v): %v", podName, err)
+	}
+	vaultCR, err = e2eutil.WaitActiveVaultsUp(t, f.VaultsCRClient, 6, vaultCR)
+	if err != nil {
+		t.Fatalf("failed to wait for any node to become active: %v", err)
+	}
+	_, keyPath, secretData, _ := e2eutil.WriteSecretData(t, vaultCR, f.KubeClient, tlsConfig, initResp.RootToken, f.Namespace)
+	createVaultBackup(t, vaultCR, s3Path)
+
+	restoredCR := e2eutil.NewCluster("test-vault-restored-", f.Namespace, 1)
+	restoredCR.Spec.RestoreFrom = &api.RestoreSource{
+		S3: &api.S3BackupTarget{
+			Path:      s3Path,
+			AWSSecret: os.Getenv("TEST_AWS_SECRET"),
+		},
+	}
+	restoredCR, err = e2eutil.CreateCluster(t, f.VaultsCRClient, restoredCR)
+	if err != nil {
+		t.Fatalf("failed to create restored vault cluster: %v", err)
+	}
+	defer func(vaultCR *api.VaultService) {
+		if err := e2eutil.DeleteCluster(t, f.VaultsCRClient, vaultCR); err != nil {
+			t.Fatalf("failed to delete restored vault cluster: %v", err)
+		}
+	}(restoredCR)
+
+	// etcd is deployed, restored and becomes ready again before vault is deployed.
+	restoredCR, err = e2eutil.WaitUntilVaultConditionTrue(t, f.VaultsCRClient, 36, restoredCR, func(v *api.VaultService) bool {
+		return v.Status.Phase == api.ClusterPhaseRunning
+	})
+	if err != nil {
+		t.Fatalf("failed to wait for the restored vault cluster to run: %v", err)
+	}
+	if c := restoredCR.Status.GetCondition(api.VaultServiceRestored); c == nil || c.Status != v1.ConditionTrue {
+		t.Fatalf("expect the Restored condition to be True, got %+v", c)
+	}
+	if !restoredCR.Status.Initialized {
+		t.Fatalf("expect the restored vault to be initialized")
+	}
+
+	// The restored vault is sealed with the keys of the backed up vault.
+	restoredCR, restoredTLS := e2eutil.WaitForCluster(t, f.KubeClient, f.VaultsCRClient, restoredCR)
+	podName = restoredCR.Status.VaultStatus.Sealed[0]
+	vClient = e2eutil.SetupVaultClient(t, f.KubeClient, f.Namespace, restoredTLS, podName)
+	if err := e2eutil.UnsealVaultNode(initResp.Keys[0], vClient); err != nil {
+		t.Fatalf("failed to unseal restored vault node(%v): %v", podName, err)
+	}
+	verifyRestoredVault(t, restoredCR, secretData, keyPath, initResp.RootToken)
+}
@@ -0,0 +1,123 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+	"github.com/coreos/vault-operator/pkg/util/k8sutil"
+	"github.com/coreos/vault-operator/test/e2e/e2eutil"
+	"github.com/coreos/vault-operator/test/e2e/framework"
+
+	"github.com/coreos/etcd-operator/pkg/util/retryutil"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestScheduledBackups(t *testing.T) {
+	f := framework.Global
+	minio, err := e2eutil.CreateMinIO(f.KubeClient, f.Namespace, "vault-backups")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := e2eutil.DeleteMinIO(f.KubeClient, f.Namespace, minio); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	vaultCR := e2eutil.NewCluster("test-vault-", f.Namespace, 1)
+	vaultCR.Spec.Backup = &api.BackupPolicy{
+		Schedule: "* * * * *",
+		S3: &api.S3BackupTarget{
+			Path:      minio.Bucket + "/e2e",
+			AWSSecret: minio.AWSSecret,
+			Endpoint:  minio.Endpoint,
+		},
+		Retention: &api.BackupRetention{MaxBackups: 2},
+	}
+	vaultCR, err = e2eutil.CreateCluster(t, f.VaultsCRClient, vaultCR)
+	if err != nil {
+		t.Fatalf("failed to create vault cluster: %v", err)
+	}
+	backups := f.VaultsCRClient.VaultV1alpha1().VaultBackups(f.Namespace)
+	sel := k8sutil.LabelsForVault(vaultCR.Name)
+	sel[k8sutil.ScheduledBackupLabel] = "true"
+	lo := metav1.ListOptions{LabelSelector: labels.SelectorFromSet(sel).String()}
+	defer func(vaultCR *api.VaultService) {
+		// The vault cluster is deleted first, so that no more backups are taken.
+		if err := e2eutil.DeleteCluster(t, f.VaultsCRClient, vaultCR); err != nil {
+			t.Fatalf("failed to delete vault cluster: %v", err)
+		}
+		if err := backups.DeleteCollection(nil, lo); err != nil {
+			t.Fatalf("failed to delete scheduled backups: %v", err)
+		}
+		// The snapshots are deleted from MinIO before the backups go away.
+		err := retryutil.Retry(10*time.Second, 6, func() (bool, error) {
+			l, err := backups.List(lo)
+			if err != nil {
+				return false, err
+			}
+			return len(l.Items) == 0, nil
+		})
+		if err != nil {
+			t.Fatalf("failed to wait for scheduled backups to be deleted: %v", err)
+		}
+	}(vaultCR)
+	// Backups are taken from etcd, so vault doesn't need to be initialized.
+	vaultCR, _ = e2eutil.WaitForCluster(t, f.KubeClient, f.VaultsCRClient, vaultCR)
+
+	// A backup is taken every minute, and each is checked on by the operator every 10 seconds.
+	// Wait until the first backup is pruned, once two later backups have succeeded.
+	var first string
+	err = retryutil.Retry(10*time.Second, 60, func() (bool, error) {
+		l, err := backups.List(lo)
+		if err != nil {
+			return false, fmt.Errorf("failed to list scheduled backups: %v", err)
+		}
+		found := false
+		for _, b := range l.Items {
+			if b.Status.Phase == api.BackupPhaseFailed {
+				return false, fmt.Errorf("backup (%s) failed: %s", b.Name, b.Status.Message)
+			}
+			found = found || b.Name == first
+		}
+		if len(first) == 0 {
+			// Backups are named after the time they are taken.
+			for _, b := range l.Items {
+				if b.Status.Phase == api.BackupPhaseSucceeded && (len(first) == 0 || b.Name < first) {
+					first = b.Name
+				}
+			}
+			return false, nil
+		}
+		return !found, nil
+	})
+	if err != nil {
+		t.Fatalf("failed to wait for the first backup to be pruned: %v", err)
+	}
+
+	vaultCR, err = e2eutil.WaitUntilVaultConditionTrue(t, f.VaultsCRClient, 6, vaultCR, func(v *api.VaultService) bool {
+		c := v.Status.GetCondition(api.VaultServiceBackupSucceeded)
+		return c != nil && c.Status == v1.ConditionTrue
+	})
+	if err != nil {
+		t.Fatalf("failed to wait for the BackupSucceeded condition: %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"testing"
+	"time"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+	"github.com/coreos/vault-operator/pkg/util/k8sutil"
+	"github.com/coreos/vault-operator/test/e2e/e2eutil"
+	"github.com/coreos/vault-operator/test/e2e/framework"
+
+	"github.com/coreos/etcd-operator/pkg/util/retryutil"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestLastUnsealedNodeNotEvicted(t *testing.T) {
+	f := framework.Global
+	vaultCR, err := e2eutil.CreateCluster(t, f.VaultsCRClient, e2eutil.NewCluster("test-vault-", f.Namespace, 2))
+	if err != nil {
+		t.Fatalf("failed to create vault cluster: %v", err)
+	}
+	defer func(vaultCR *api.VaultService) {
+		if err := e2eutil.DeleteCluster(t, f.VaultsCRClient, vaultCR); err != nil {
+			t.Fatalf("failed to delete vault cluster: %v", err)
+		}
+	}(vaultCR)
+	vaultCR, tlsConfig := e2eutil.WaitForCluster(t, f.KubeClient, f.VaultsCRClient, vaultCR)
+	vClient := e2eutil.SetupVaultClient(t, f.KubeClient, f.Namespace, tlsConfig, vaultCR.Status.VaultStatus.Sealed[0])
+	vaultCR, initResp := e2eutil.InitializeVault(t, f.VaultsCRClient, vaultCR, vClient)
+
+	// Unseal the 1st vault node only, so the active node is the only unsealed one.
+	unseal := func(podName string) {
+		vClient := e2eutil.SetupVaultClient(t, f.KubeClient, f.Namespace, tlsConfig, podName)
+		if err := e2eutil.UnsealVaultNode(initResp.Keys[0], vClient); err != nil {
+			t.Fatalf("failed to unseal vault node (%s): %v", podName, err)
+		}
+	}
+	unseal(vaultCR.Status.VaultStatus.Sealed[0])
+	vaultCR, err = e2eutil.WaitActiveVaultsUp(t, f.VaultsCRClient, 6, vaultCR)
+	if err != nil {
+		t.Fatalf("failed to wait for any node to become active: %v", err)
+	}
+
+	for _, name := range []string{vaultCR.Name, k8sutil.EtcdPDBName(vaultCR.Name)} {
+		if _, err := f.KubeClient.PolicyV1beta1().PodDisruptionBudgets(f.Namespace).Get(name, metav1.GetOptions{}); err != nil {
+			t.Fatalf("failed to get PDB (%s): %v", name, err)
+		}
+	}
+
+	// waitPDB waits for the disruption controller to observe the given number of unsealed vault pods.
+	waitPDB := func(unsealed int32) {
+		var pdb *policyv1beta1.PodDisruptionBudget
+		err := retryutil.Retry(5*time.Second, 6, func() (bool, error) {
+			var err error
+			pdb, err = f.KubeClient.PolicyV1beta1().PodDisruptionBudgets(f.Namespace).Get(vaultCR.Name, metav1.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+			// Only the unsealed vault pods are selected by the vault PDB.
+			return pdb.Status.ExpectedPods == unsealed && pdb.Status.CurrentHealthy == unsealed, nil
+		})
+		if err != nil {
+			t.Fatalf("failed to wait for vault PDB status: %v, last status: %+v", err, pdb.Status)
+		}
+	}
+	waitPDB(1)
+
+	// The sealed vault pod is not protected.
+	if len(vaultCR.Status.VaultStatus.Sealed) != 1 {
+		t.Fatalf("expect one sealed vault node, got: %v", vaultCR.Status.VaultStatus.Sealed)
+	}
+	sealed := vaultCR.Status.VaultStatus.Sealed[0]
+	err = f.KubeClient.CoreV1().Pods(f.Namespace).Evict(&policyv1beta1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{Name: sealed, Namespace: f.Namespace},
+	})
+	if err != nil {
+		t.Fatalf("failed to evict the sealed vault pod (%s): %v", sealed, err)
+	}
+
+	active := vaultCR.Status.VaultStatus.Active
+	err = f.KubeClient.CoreV1().Pods(f.Namespace).Evict(&policyv1beta1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{Name: active, Namespace: f.Namespace},
+	})
+	if !apierrors.IsTooManyRequests(err) {
+		t.Fatalf("expect eviction of the only unsealed vault pod (%s) to be refused, got: %v", active, err)
+	}
+
+	// Once the replacement of the evicted pod is unsealed as a standby node, the active node can be evicted.
+	vaultCR, err = e2eutil.WaitSealedVaultsUp(t, f.VaultsCRClient, 1, 10, vaultCR)
+	if err != nil {
+		t.Fatalf("failed to wait for the replacement vault node to become sealed: %v", err)
+	}
+	standby := vaultCR.Status.VaultStatus.Sealed[0]
+	unseal(standby)
+	vaultCR, err = e2eutil.WaitStandbyVaultsUp(t, f.VaultsCRClient, 1, 6, vaultCR)
+	if err != nil {
+		t.Fatalf("failed to wait for the standby vault node: %v", err)
+	}
+	waitPDB(2)
+
+	err = f.KubeClient.CoreV1().Pods(f.Namespace).Evict(&policyv1beta1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{Name: active, Namespace: f.Namespace},
+	})
+	if err != nil {
+		t.Fatalf("failed to evict the active vault pod (%s) with an unsealed standby: %v", active, err)
+	}
+	if _, err = e2eutil.WaitUntilActiveIsFrom(t, f.VaultsCRClient, 6, vaultCR, standby); err != nil {
+		t.Fatalf("failed to wait for the standby vault node (%s) to take over: %v", standby, err)
+	}
+}
@@ -21,7 +21,9 @@ import (
 	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
 	"github.com/coreos/vault-operator/pkg/generated/clientset/versioned"
 
+	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
 )
 
 // CreateCluster creates a vault CR with the desired spec
@@ -64,6 +66,29 @@ func UpdateVersion(t *testing.T, crClient versioned.Interface, vs *api.VaultServ
 	return vault, nil
 }
 
+// SetOperatorToken stores the given vault token in a secret, and sets it as the operator token secret of the vault CR
+func SetOperatorToken(t *testing.T, kubeClient kubernetes.Interface, crClient versioned.Interface, vs *api.VaultService, token string) (*api.VaultService, error) {
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: vs.Name + "-operator-token"},
+		StringData: map[string]string{api.OperatorTokenKey: token},
+	}
+	_, err := kubeClient.CoreV1().Secrets(vs.Namespace).Create(secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create operator token secret: %v", err)
+	}
+	vault, err := crClient.VaultV1alpha1().VaultServices(vs.Namespace).Get(vs.Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get CR: %v", err)
+	}
+	vault.Spec.OperatorTokenSecret = secret.Name
+	vault, err = crClient.VaultV1alpha1().VaultServices(vs.Namespace).Update(vault)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update CR: %v", err)
+	}
+	LogfWithTimestamp(t, "set operator token secret of vault cluster(%v) to (%v)", vault.Name, secret.Name)
+	return vault, nil
+}
+
 // DeleteCluster deletes the vault CR specified by cluster spec
 func DeleteCluster(t *testing.T, crClient versioned.Interface, vs *api.VaultService) error {
 	t.Logf("deleting vault cluster: %v", vs.Name)
@@ -0,0 +1,129 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2eutil
+
+import (
+	"fmt"
+
+	"github.com/coreos/vault-operator/pkg/util/k8sutil"
+
+	eopapi "github.com/coreos/etcd-operator/pkg/apis/etcd/v1beta2"
+	"github.com/coreos/etcd-operator/pkg/util/retryutil"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	minioImage     = "minio/minio:RELEASE.2018-06-29T02-11-29Z"
+	minioPort      = 9000
+	minioAccessKey = "vault-e2e-access"
+	minioSecretKey = "vault-e2e-secret"
+)
+
+// MinIO is an S3 compatible object store running in the test namespace.
+type MinIO struct {
+	// Bucket is the only bucket of the object store.
+	Bucket string
+	// Endpoint is the S3 endpoint of the object store.
+	Endpoint string
+	// AWSSecret is the name of the secret with the AWS credentials and config files for the object store.
+	AWSSecret string
+}
+
+// CreateMinIO runs a MinIO pod with the given bucket as a stand-in for S3, and waits for it to run.
+//
+// The etcd backup operator addresses buckets in the host name, "<bucket>.<endpoint host>".
+// The MinIO service is named after the bucket, and the endpoint host is the service domain
+// of the namespace, so that "<bucket>.<namespace>.svc.cluster.local" resolves to the MinIO service.
+func CreateMinIO(kubecli kubernetes.Interface, namespace, bucket string) (*MinIO, error) {
+	domain := namespace + ".svc.cluster.local"
+	labels := map[string]string{"app": "minio", "minio_bucket": bucket}
+	m := &MinIO{
+		Bucket:    bucket,
+		Endpoint:  fmt.Sprintf("http://%s:%d", domain, minioPort),
+		AWSSecret: bucket + "-aws",
+	}
+
+	_, err := kubecli.CoreV1().Secrets(namespace).Create(&v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: m.AWSSecret},
+		StringData: map[string]string{
+			eopapi.AWSSecretCredentialsFileName: fmt.Sprintf("[default]\naws_access_key_id = %s\naws_secret_access_key = %s\n", minioAccessKey, minioSecretKey),
+			eopapi.AWSSecretConfigFileName:      "[default]\nregion = us-east-1\n",
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create MinIO AWS secret: %v", err)
+	}
+
+	_, err = kubecli.CoreV1().Pods(namespace).Create(&v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: bucket, Labels: labels},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{{
+				Name:  "minio",
+				Image: minioImage,
+				// A directory in the data dir is a bucket.
+				Command: []string{"/bin/sh", "-c", fmt.Sprintf("mkdir -p /data/%s && exec minio server /data", bucket)},
+				Env: []v1.EnvVar{
+					{Name: "MINIO_ACCESS_KEY", Value: minioAccessKey},
+					{Name: "MINIO_SECRET_KEY", Value: minioSecretKey},
+					{Name: "MINIO_DOMAIN", Value: domain},
+				},
+				Ports: []v1.ContainerPort{{ContainerPort: minioPort}},
+			}},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create MinIO pod: %v", err)
+	}
+
+	_, err = kubecli.CoreV1().Services(namespace).Create(&v1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: bucket},
+		Spec: v1.ServiceSpec{
+			Selector: labels,
+			Ports:    []v1.ServicePort{{Port: minioPort, TargetPort: intstr.FromInt(minioPort)}},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create MinIO service: %v", err)
+	}
+
+	err = retryutil.Retry(retryInterval, 6, func() (bool, error) {
+		p, err := kubecli.CoreV1().Pods(namespace).Get(bucket, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return k8sutil.IsPodReady(*p), nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to wait for MinIO pod to be ready: %v", err)
+	}
+	return m, nil
+}
+
+// DeleteMinIO deletes the MinIO pod, service and secret created by CreateMinIO.
+func DeleteMinIO(kubecli kubernetes.Interface, namespace string, m *MinIO) error {
+	if err := kubecli.CoreV1().Services(namespace).Delete(m.Bucket, nil); err != nil {
+		return fmt.Errorf("failed to delete MinIO service: %v", err)
+	}
+	if err := kubecli.CoreV1().Pods(namespace).Delete(m.Bucket, metav1.NewDeleteOptions(0)); err != nil {
+		return fmt.Errorf("failed to delete MinIO pod: %v", err)
+	}
+	if err := kubecli.CoreV1().Secrets(namespace).Delete(m.AWSSecret, nil); err != nil {
+		return fmt.Errorf("failed to delete MinIO AWS secret: %v", err)
+	}
+	return nil
+}
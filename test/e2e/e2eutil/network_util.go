@@ -0,0 +1,62 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2eutil
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/coreos/etcd-operator/pkg/util/retryutil"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// CanConnect runs a pod with the given labels that opens a TCP connection to the given host and port,
+// and returns whether the connection succeeded.
+func CanConnect(kubecli kubernetes.Interface, namespace string, labels map[string]string, host string, port int) (bool, error) {
+	pod, err := kubecli.CoreV1().Pods(namespace).Create(&v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "connect-",
+			Labels:       labels,
+		},
+		Spec: v1.PodSpec{
+			RestartPolicy: v1.RestartPolicyNever,
+			Containers: []v1.Container{{
+				Name:    "connect",
+				Image:   "busybox",
+				Command: []string{"sh", "-c", fmt.Sprintf("nc -w 5 %s %s </dev/null", host, strconv.Itoa(port))},
+			}},
+		},
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to create connect pod: %v", err)
+	}
+	defer kubecli.CoreV1().Pods(namespace).Delete(pod.Name, metav1.NewDeleteOptions(0))
+
+	var phase v1.PodPhase
+	err = retryutil.Retry(retryInterval, 12, func() (bool, error) {
+		p, err := kubecli.CoreV1().Pods(namespace).Get(pod.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		phase = p.Status.Phase
+		return phase == v1.PodSucceeded || phase == v1.PodFailed, nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to wait for connect pod (%s) to finish: %v", pod.Name, err)
+	}
+	return phase == v1.PodSucceeded, nil
+}
@@ -15,6 +15,7 @@
 package e2eutil
 
 import (
+	"crypto/tls"
 	"fmt"
 	"reflect"
 	"testing"
@@ -22,18 +23,15 @@ import (
 	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
 	"github.com/coreos/vault-operator/pkg/generated/clientset/versioned"
 	"github.com/coreos/vault-operator/pkg/util/k8sutil"
-	"github.com/coreos/vault-operator/pkg/util/vaultutil"
 
 	vaultapi "github.com/hashicorp/vault/api"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
 
-const targetVaultPort = "8200"
-
 // WaitForCluster waits for all available nodes of a cluster to appear in the vault CR status
 // Returns the updated vault cluster and the TLS configuration to use for vault clients interacting with the cluster
-func WaitForCluster(t *testing.T, kubeClient kubernetes.Interface, vaultsCRClient versioned.Interface, vaultCR *api.VaultService) (*api.VaultService, *vaultapi.TLSConfig) {
+func WaitForCluster(t *testing.T, kubeClient kubernetes.Interface, vaultsCRClient versioned.Interface, vaultCR *api.VaultService) (*api.VaultService, *tls.Config) {
 	// Based on local testing, it took about ~50s for a normal deployment to finish.
 	vaultCR, err := WaitAvailableVaultsUp(t, vaultsCRClient, int(vaultCR.Spec.Nodes), 10, vaultCR)
 	if err != nil {
@@ -77,20 +75,20 @@ func UnsealVaultNode(unsealKey string, vClient *vaultapi.Client) error {
 }
 
 // SetupVaultClient creates a vault client for the specified pod
-func SetupVaultClient(t *testing.T, kubeClient kubernetes.Interface, namespace string, tlsConfig *vaultapi.TLSConfig, podName string) *vaultapi.Client {
+func SetupVaultClient(t *testing.T, kubeClient kubernetes.Interface, namespace string, tlsConfig *tls.Config, podName string) *vaultapi.Client {
 	pod, err := kubeClient.CoreV1().Pods(namespace).Get(podName, metav1.GetOptions{})
 	if err != nil {
 		t.Fatalf("fail to get vault pod (%s): %v", podName, err)
 	}
-	vClient, err := vaultutil.NewClient(k8sutil.PodDNSName(*pod), targetVaultPort, tlsConfig)
+	vClient, err := k8sutil.NewVaultPodClient(pod, tlsConfig)
 	if err != nil {
-		t.Fatalf("failed creating vault client for (localhost:%v): %v", targetVaultPort, err)
+		t.Fatalf("failed creating vault client for (%s): %v", podName, err)
 	}
 	return vClient
 }
 
 // SetupUnsealedVaultCluster initializes a vault cluster and unseals the 1st vault node.
-func SetupUnsealedVaultCluster(t *testing.T, kubeClient kubernetes.Interface, vaultsCRClient versioned.Interface, namespace string) (*api.VaultService, *vaultapi.TLSConfig, string) {
+func SetupUnsealedVaultCluster(t *testing.T, kubeClient kubernetes.Interface, vaultsCRClient versioned.Interface, namespace string) (*api.VaultService, *tls.Config, string) {
 	vaultCR, err := CreateCluster(t, vaultsCRClient, NewCluster("test-vault-", namespace, 2))
 	if err != nil {
 		t.Fatalf("failed to create vault cluster: %v", err)
@@ -118,7 +116,7 @@ func SetupUnsealedVaultCluster(t *testing.T, kubeClient kubernetes.Interface, va
 }
 
 // WriteSecretData writes secret data into vault.
-func WriteSecretData(t *testing.T, vaultCR *api.VaultService, kubeClient kubernetes.Interface, tlsConfig *vaultapi.TLSConfig, rootToken, namespace string) (*vaultapi.Client, string, map[string]interface{}, string) {
+func WriteSecretData(t *testing.T, vaultCR *api.VaultService, kubeClient kubernetes.Interface, tlsConfig *tls.Config, rootToken, namespace string) (*vaultapi.Client, string, map[string]interface{}, string) {
 	// Write secret to active node
 	podName := vaultCR.Status.VaultStatus.Active
 	vClient := SetupVaultClient(t, kubeClient, namespace, tlsConfig, podName)
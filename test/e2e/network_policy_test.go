@@ -0,0 +1,99 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"math/rand"
+	"os"
+	"path"
+	"strconv"
+	"testing"
+	"time"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+	"github.com/coreos/vault-operator/pkg/util/k8sutil"
+	"github.com/coreos/vault-operator/test/e2e/e2eutil"
+	"github.com/coreos/vault-operator/test/e2e/framework"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNetworkPolicy(t *testing.T) {
+	f := framework.Global
+	vaultCR := e2eutil.NewCluster("test-vault-", f.Namespace, 1)
+	vaultCR.Spec.NetworkPolicy = &api.NetworkPolicy{}
+	vaultCR, err := e2eutil.CreateCluster(t, f.VaultsCRClient, vaultCR)
+	if err != nil {
+		t.Fatalf("failed to create vault cluster: %v", err)
+	}
+	defer func(vaultCR *api.VaultService) {
+		if err := e2eutil.DeleteCluster(t, f.VaultsCRClient, vaultCR); err != nil {
+			t.Fatalf("failed to delete vault cluster: %v", err)
+		}
+	}(vaultCR)
+
+	// The operator must still be able to poll the vault pods through the network policy.
+	vaultCR, _ = e2eutil.WaitForCluster(t, f.KubeClient, f.VaultsCRClient, vaultCR)
+
+	for _, name := range []string{vaultCR.Name, k8sutil.EtcdNetworkPolicyName(vaultCR.Name)} {
+		if _, err := f.KubeClient.NetworkingV1().NetworkPolicies(f.Namespace).Get(name, metav1.GetOptions{}); err != nil {
+			t.Fatalf("failed to get network policy (%s): %v", name, err)
+		}
+	}
+
+	// The network plugin of the test cluster must enforce network policies.
+	etcdHost := k8sutil.EtcdNameForVault(vaultCR.Name) + "-client"
+	tests := []struct {
+		name   string
+		labels map[string]string
+		host   string
+		port   int
+		want   bool
+	}{
+		{"any pod to the vault client port", nil, vaultCR.Name, k8sutil.VaultClientPort, true},
+		{"any pod to the vault cluster port", nil, vaultCR.Name, 8201, false},
+		{"any pod to etcd", nil, etcdHost, 2379, false},
+		{"etcd backup operator to etcd", map[string]string{"name": "etcd-backup-operator"}, etcdHost, 2379, true},
+	}
+	for _, tt := range tests {
+		got, err := e2eutil.CanConnect(f.KubeClient, f.Namespace, tt.labels, tt.host, tt.port)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: expect connected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
+
+func TestNetworkPolicyAllowsBackup(t *testing.T) {
+	f := framework.Global
+	vaultCR := e2eutil.NewCluster("test-vault-", f.Namespace, 1)
+	vaultCR.Spec.NetworkPolicy = &api.NetworkPolicy{}
+	vaultCR, err := e2eutil.CreateCluster(t, f.VaultsCRClient, vaultCR)
+	if err != nil {
+		t.Fatalf("failed to create vault cluster: %v", err)
+	}
+	defer func(vaultCR *api.VaultService) {
+		if err := e2eutil.DeleteCluster(t, f.VaultsCRClient, vaultCR); err != nil {
+			t.Fatalf("failed to delete vault cluster: %v", err)
+		}
+	}(vaultCR)
+	vaultCR, _ = e2eutil.WaitForCluster(t, f.KubeClient, f.VaultsCRClient, vaultCR)
+
+	// The etcd backup operator takes the snapshot through the network policy of the etcd pods.
+	s3Path := path.Join(os.Getenv("TEST_S3_BUCKET"), "jenkins", strconv.Itoa(int(rand.Uint64())), time.Now().Format(time.RFC3339), "etcd.backup")
+	createVaultBackup(t, vaultCR, s3Path)
+}
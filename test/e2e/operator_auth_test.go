@@ -0,0 +1,135 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"testing"
+	"time"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+	"github.com/coreos/vault-operator/test/e2e/e2eutil"
+	"github.com/coreos/vault-operator/test/e2e/framework"
+
+	"github.com/coreos/etcd-operator/pkg/util/retryutil"
+	vaultapi "github.com/hashicorp/vault/api"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestOperatorAuth(t *testing.T) {
+	f := framework.Global
+	vaultCR, tlsConfig, rootToken := e2eutil.SetupUnsealedVaultCluster(t, f.KubeClient, f.VaultsCRClient, f.Namespace)
+	defer func(vaultCR *api.VaultService) {
+		if err := e2eutil.DeleteCluster(t, f.VaultsCRClient, vaultCR); err != nil {
+			t.Fatalf("failed to delete vault cluster: %v", err)
+		}
+	}(vaultCR)
+
+	// Bootstrap with a copy of the root token, so that the test can still check vault after it is revoked.
+	vClient := e2eutil.SetupVaultClient(t, f.KubeClient, f.Namespace, tlsConfig, vaultCR.Status.VaultStatus.Active)
+	vClient.SetToken(rootToken)
+	bootstrap, err := vClient.Auth().Token().Create(&vaultapi.TokenCreateRequest{Policies: []string{"root"}})
+	if err != nil {
+		t.Fatalf("failed to create bootstrap token: %v", err)
+	}
+
+	vaultCR, err = f.VaultsCRClient.VaultV1alpha1().VaultServices(f.Namespace).Get(vaultCR.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get vault CR: %v", err)
+	}
+	vaultCR.Spec.OperatorAuth = &api.OperatorAuthPolicy{
+		Method:               api.OperatorAuthAppRole,
+		RevokeBootstrapToken: true,
+	}
+	vaultCR, err = f.VaultsCRClient.VaultV1alpha1().VaultServices(f.Namespace).Update(vaultCR)
+	if err != nil {
+		t.Fatalf("failed to update vault CR: %v", err)
+	}
+	vaultCR, err = e2eutil.SetOperatorToken(t, f.KubeClient, f.VaultsCRClient, vaultCR, bootstrap.Auth.ClientToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vaultCR, err = e2eutil.WaitUntilVaultConditionTrue(t, f.VaultsCRClient, 6, vaultCR, func(v *api.VaultService) bool {
+		c := v.Status.GetCondition(api.VaultServiceOperatorCredentialReady)
+		return c != nil && c.Status == v1.ConditionTrue
+	})
+	if err != nil {
+		t.Fatalf("failed to wait for the operator credential to be ready: %v", err)
+	}
+
+	if _, err = vClient.Auth().Token().Lookup(bootstrap.Auth.ClientToken); err == nil {
+		t.Fatalf("expect the bootstrap token to be revoked")
+	}
+	roles, err := vClient.Logical().List("auth/vault-operator/role")
+	if err != nil || roles == nil {
+		t.Fatalf("failed to list roles of the operator auth method: %v", err)
+	}
+
+	// The identity of the operator cannot write policies, which would let it grant itself any policy.
+	operatorToken, err := vClient.Auth().Token().Create(&vaultapi.TokenCreateRequest{Policies: []string{"vault-operator"}})
+	if err != nil {
+		t.Fatalf("failed to create token with the operator policy: %v", err)
+	}
+	operatorClient, err := vClient.Clone()
+	if err != nil {
+		t.Fatalf("failed to clone vault client: %v", err)
+	}
+	operatorClient.SetToken(operatorToken.Auth.ClientToken)
+	_, err = operatorClient.Logical().Write("sys/policies/acl/escalate", map[string]interface{}{
+		"policy": `path "*" { capabilities = ["create", "read", "update", "delete", "list", "sudo"] }`,
+	})
+	if err == nil {
+		t.Fatalf("expect the operator policy to refuse writing policies")
+	}
+
+	// VaultPolicy resources are written with the token of the operator token secret, which must be replaced
+	// once the bootstrap token is revoked.
+	token, err := vClient.Auth().Token().Create(&vaultapi.TokenCreateRequest{Policies: []string{"root"}})
+	if err != nil {
+		t.Fatalf("failed to create operator token: %v", err)
+	}
+	secret, err := f.KubeClient.CoreV1().Secrets(f.Namespace).Get(vaultCR.Spec.OperatorTokenSecret, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get operator token secret: %v", err)
+	}
+	secret.Data[api.OperatorTokenKey] = []byte(token.Auth.ClientToken)
+	if _, err = f.KubeClient.CoreV1().Secrets(f.Namespace).Update(secret); err != nil {
+		t.Fatalf("failed to update operator token secret: %v", err)
+	}
+
+	policies := f.VaultsCRClient.VaultV1alpha1().VaultPolicies(f.Namespace)
+	vp, err := policies.Create(&api.VaultPolicy{
+		ObjectMeta: metav1.ObjectMeta{GenerateName: "test-policy-"},
+		Spec: api.VaultPolicySpec{
+			VaultService: vaultCR.Name,
+			Policy:       `path "secret/demo/*" { capabilities = ["read"] }`,
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create VaultPolicy: %v", err)
+	}
+	defer policies.Delete(vp.Name, nil)
+	err = retryutil.Retry(5*time.Second, 6, func() (bool, error) {
+		vp, err = policies.Get(vp.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return vp.Status.Synced, nil
+	})
+	if err != nil {
+		t.Fatalf("failed to wait for VaultPolicy to be synced: %v, last status: %+v", err, vp.Status)
+	}
+}
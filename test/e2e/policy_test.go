@@ -0,0 +1,95 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"testing"
+	"time"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+	"github.com/coreos/vault-operator/test/e2e/e2eutil"
+	"github.com/coreos/vault-operator/test/e2e/framework"
+
+	"github.com/coreos/etcd-operator/pkg/util/retryutil"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestVaultPolicy(t *testing.T) {
+	f := framework.Global
+	vaultCR, tlsConfig, rootToken := e2eutil.SetupUnsealedVaultCluster(t, f.KubeClient, f.VaultsCRClient, f.Namespace)
+	defer func(vaultCR *api.VaultService) {
+		if err := e2eutil.DeleteCluster(t, f.VaultsCRClient, vaultCR); err != nil {
+			t.Fatalf("failed to delete vault cluster: %v", err)
+		}
+	}(vaultCR)
+	vaultCR, err := e2eutil.SetOperatorToken(t, f.KubeClient, f.VaultsCRClient, vaultCR, rootToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	policies := f.VaultsCRClient.VaultV1alpha1().VaultPolicies(f.Namespace)
+	vp, err := policies.Create(&api.VaultPolicy{
+		ObjectMeta: metav1.ObjectMeta{GenerateName: "test-policy-"},
+		Spec: api.VaultPolicySpec{
+			VaultService: vaultCR.Name,
+			Policy:       `path "secret/demo/*" { capabilities = ["read"] }`,
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create VaultPolicy: %v", err)
+	}
+
+	err = retryutil.Retry(5*time.Second, 6, func() (bool, error) {
+		vp, err = policies.Get(vp.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		return vp.Status.Synced, nil
+	})
+	if err != nil {
+		t.Fatalf("failed to wait for VaultPolicy to be synced: %v, last status: %+v", err, vp.Status)
+	}
+
+	vClient := e2eutil.SetupVaultClient(t, f.KubeClient, f.Namespace, tlsConfig, vaultCR.Status.VaultStatus.Active)
+	vClient.SetToken(rootToken)
+	policyPath := "sys/policies/acl/" + vp.GetPolicyName()
+	secret, err := vClient.Logical().Read(policyPath)
+	if err != nil || secret == nil {
+		t.Fatalf("failed to read policy (%s) from vault: %v", vp.GetPolicyName(), err)
+	}
+	if secret.Data["policy"] != vp.Spec.Policy {
+		t.Fatalf("expect policy %q, got %q", vp.Spec.Policy, secret.Data["policy"])
+	}
+
+	// The policy is deleted from vault before the VaultPolicy goes away.
+	if err = policies.Delete(vp.Name, nil); err != nil {
+		t.Fatalf("failed to delete VaultPolicy: %v", err)
+	}
+	err = retryutil.Retry(5*time.Second, 6, func() (bool, error) {
+		_, err := policies.Get(vp.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+	if err != nil {
+		t.Fatalf("failed to wait for VaultPolicy to be deleted: %v", err)
+	}
+	secret, err = vClient.Logical().Read(policyPath)
+	if err == nil && secret != nil {
+		t.Fatalf("expect policy (%s) to be deleted from vault", vp.GetPolicyName())
+	}
+}
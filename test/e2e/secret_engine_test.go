@@ -0,0 +1,123 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"testing"
+	"time"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+	"github.com/coreos/vault-operator/test/e2e/e2eutil"
+	"github.com/coreos/vault-operator/test/e2e/framework"
+
+	"github.com/coreos/etcd-operator/pkg/util/retryutil"
+	vaultapi "github.com/hashicorp/vault/api"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestVaultSecretEngine(t *testing.T) {
+	f := framework.Global
+	vaultCR, tlsConfig, rootToken := e2eutil.SetupUnsealedVaultCluster(t, f.KubeClient, f.VaultsCRClient, f.Namespace)
+	defer func(vaultCR *api.VaultService) {
+		if err := e2eutil.DeleteCluster(t, f.VaultsCRClient, vaultCR); err != nil {
+			t.Fatalf("failed to delete vault cluster: %v", err)
+		}
+	}(vaultCR)
+	vaultCR, err := e2eutil.SetOperatorToken(t, f.KubeClient, f.VaultsCRClient, vaultCR, rootToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	secretEngines := f.VaultsCRClient.VaultV1alpha1().VaultSecretEngines(f.Namespace)
+	se, err := secretEngines.Create(&api.VaultSecretEngine{
+		ObjectMeta: metav1.ObjectMeta{GenerateName: "test-transit-"},
+		Spec: api.VaultSecretEngineSpec{
+			VaultService:    vaultCR.Name,
+			Type:            api.SecretEngineTransit,
+			DefaultLeaseTTL: "1h",
+			Transit: &api.TransitConfig{
+				Keys: []api.TransitKey{{Name: "test-key"}},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create VaultSecretEngine: %v", err)
+	}
+	// Drift is only noticed by the resync of the secret engine, which happens every minute.
+	waitSecretEngineStatus := func(cond func(s *api.VaultSecretEngineStatus) bool) {
+		err = retryutil.Retry(5*time.Second, 15, func() (bool, error) {
+			se, err = secretEngines.Get(se.Name, metav1.GetOptions{})
+			if err != nil {
+				return false, err
+			}
+			return cond(&se.Status), nil
+		})
+		if err != nil {
+			t.Fatalf("failed to wait for VaultSecretEngine status: %v, last status: %+v", err, se.Status)
+		}
+	}
+	waitSecretEngineStatus(func(s *api.VaultSecretEngineStatus) bool { return s.Synced })
+
+	vClient := e2eutil.SetupVaultClient(t, f.KubeClient, f.Namespace, tlsConfig, vaultCR.Status.VaultStatus.Active)
+	vClient.SetToken(rootToken)
+	key, err := vClient.Logical().Read("transit/keys/test-key")
+	if err != nil || key == nil {
+		t.Fatalf("failed to read key (test-key) from vault: %v", err)
+	}
+
+	// Tuning the secret engine directly is reported as drift, and corrected once asked for.
+	err = vClient.Sys().TuneMount("transit", vaultapi.MountConfigInput{DefaultLeaseTTL: "2h"})
+	if err != nil {
+		t.Fatalf("failed to tune secret engine: %v", err)
+	}
+	waitSecretEngineStatus(func(s *api.VaultSecretEngineStatus) bool { return len(s.Drift) != 0 })
+
+	se.Spec.CorrectDrift = true
+	se, err = secretEngines.Update(se)
+	if err != nil {
+		t.Fatalf("failed to update VaultSecretEngine: %v", err)
+	}
+	waitSecretEngineStatus(func(s *api.VaultSecretEngineStatus) bool { return s.Synced })
+	c, err := vClient.Sys().MountConfig("transit")
+	if err != nil {
+		t.Fatalf("failed to read secret engine config: %v", err)
+	}
+	if c.DefaultLeaseTTL != 3600 {
+		t.Fatalf("expect default lease TTL to be tuned back to 3600s, got %ds", c.DefaultLeaseTTL)
+	}
+
+	// The secret engine is unmounted before the VaultSecretEngine goes away.
+	if err = secretEngines.Delete(se.Name, nil); err != nil {
+		t.Fatalf("failed to delete VaultSecretEngine: %v", err)
+	}
+	err = retryutil.Retry(5*time.Second, 6, func() (bool, error) {
+		_, err := secretEngines.Get(se.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	})
+	if err != nil {
+		t.Fatalf("failed to wait for VaultSecretEngine to be deleted: %v", err)
+	}
+	mounts, err := vClient.Sys().ListMounts()
+	if err != nil {
+		t.Fatalf("failed to list secret engines: %v", err)
+	}
+	if _, ok := mounts["transit/"]; ok {
+		t.Fatalf("expect the transit secret engine to be unmounted")
+	}
+}
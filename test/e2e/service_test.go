@@ -0,0 +1,154 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"testing"
+	"time"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+	"github.com/coreos/vault-operator/pkg/util/k8sutil"
+	"github.com/coreos/vault-operator/test/e2e/e2eutil"
+	"github.com/coreos/vault-operator/test/e2e/framework"
+
+	"github.com/coreos/etcd-operator/pkg/util/retryutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestServiceRoutesToActiveNode(t *testing.T) {
+	f := framework.Global
+	vaultCR, err := e2eutil.CreateCluster(t, f.VaultsCRClient, e2eutil.NewCluster("test-vault-", f.Namespace, 2))
+	if err != nil {
+		t.Fatalf("failed to create vault cluster: %v", err)
+	}
+	defer func(vaultCR *api.VaultService) {
+		if err := e2eutil.DeleteCluster(t, f.VaultsCRClient, vaultCR); err != nil {
+			t.Fatalf("failed to delete vault cluster: %v", err)
+		}
+	}(vaultCR)
+
+	vaultCR, tlsConfig := e2eutil.WaitForCluster(t, f.KubeClient, f.VaultsCRClient, vaultCR)
+
+	// Init vault via the first sealed node
+	podName := vaultCR.Status.VaultStatus.Sealed[0]
+	vClient := e2eutil.SetupVaultClient(t, f.KubeClient, f.Namespace, tlsConfig, podName)
+	vaultCR, initResp := e2eutil.InitializeVault(t, f.VaultsCRClient, vaultCR, vClient)
+
+	// Unseal both vault nodes, so that the standby node can take over
+	for _, podName := range vaultCR.Status.VaultStatus.Sealed {
+		vClient := e2eutil.SetupVaultClient(t, f.KubeClient, f.Namespace, tlsConfig, podName)
+		if err := e2eutil.UnsealVaultNode(initResp.Keys[0], vClient); err != nil {
+			t.Fatalf("failed to unseal vault node(%v): %v", podName, err)
+		}
+	}
+	vaultCR, err = e2eutil.WaitStandbyVaultsUp(t, f.VaultsCRClient, 1, 6, vaultCR)
+	if err != nil {
+		t.Fatalf("failed to wait for vault nodes to become standby: %v", err)
+	}
+	vaultCR, err = e2eutil.WaitActiveVaultsUp(t, f.VaultsCRClient, 6, vaultCR)
+	if err != nil {
+		t.Fatalf("failed to wait for any node to become active: %v", err)
+	}
+	oldActive, standby := vaultCR.Status.VaultStatus.Active, vaultCR.Status.VaultStatus.Standby[0]
+	waitServiceRoutesTo(t, vaultCR.Name, oldActive)
+	waitServiceRoutesTo(t, k8sutil.VaultStandbyServiceName(vaultCR.Name), standby)
+
+	// Step down the active node, and check that the vault service follows the new active node
+	vClient = e2eutil.SetupVaultClient(t, f.KubeClient, f.Namespace, tlsConfig, oldActive)
+	vClient.SetToken(initResp.RootToken)
+	if err := vClient.Sys().StepDown(); err != nil {
+		t.Fatalf("failed to step down the active vault node (%s): %v", oldActive, err)
+	}
+	vaultCR, err = e2eutil.WaitUntilActiveIsFrom(t, f.VaultsCRClient, 6, vaultCR, standby)
+	if err != nil {
+		t.Fatalf("failed to wait for the standby node (%s) to become active: %v", standby, err)
+	}
+	waitServiceRoutesTo(t, vaultCR.Name, standby)
+	waitServiceRoutesTo(t, k8sutil.VaultStandbyServiceName(vaultCR.Name), oldActive)
+}
+
+// waitServiceRoutesTo waits until the given service only routes to the given vault pod.
+func waitServiceRoutesTo(t *testing.T, svcName, podName string) {
+	f := framework.Global
+	pod, err := f.KubeClient.CoreV1().Pods(f.Namespace).Get(podName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get vault pod (%s): %v", podName, err)
+	}
+
+	var addrs []string
+	err = retryutil.Retry(5*time.Second, 6, func() (bool, error) {
+		ep, err := f.KubeClient.CoreV1().Endpoints(f.Namespace).Get(svcName, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		addrs = nil
+		for _, ss := range ep.Subsets {
+			for _, a := range ss.Addresses {
+				addrs = append(addrs, a.IP)
+			}
+			for _, a := range ss.NotReadyAddresses {
+				addrs = append(addrs, a.IP)
+			}
+		}
+		return len(addrs) == 1 && addrs[0] == pod.Status.PodIP, nil
+	})
+	if err != nil {
+		t.Fatalf("expect service (%s) to only route to the vault pod (%s), got endpoints %v: %v", svcName, pod.Status.PodIP, addrs, err)
+	}
+}
+
+func TestHeadlessServiceResolvesAllNodes(t *testing.T) {
+	f := framework.Global
+	vaultCR, _, _ := e2eutil.SetupUnsealedVaultCluster(t, f.KubeClient, f.VaultsCRClient, f.Namespace)
+	defer func(vaultCR *api.VaultService) {
+		if err := e2eutil.DeleteCluster(t, f.VaultsCRClient, vaultCR); err != nil {
+			t.Fatalf("failed to delete vault cluster: %v", err)
+		}
+	}(vaultCR)
+
+	// The 2nd vault node stays sealed, and must still be addressable through the headless service.
+	// DNS only resolves to the ready addresses of the endpoints, so the sealed pod must be among them.
+	sealed, err := f.KubeClient.CoreV1().Pods(f.Namespace).Get(vaultCR.Status.VaultStatus.Sealed[0], metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get sealed vault pod: %v", err)
+	}
+	name := k8sutil.VaultHeadlessServiceName(vaultCR.Name)
+	var addrs []string
+	err = retryutil.Retry(5*time.Second, 6, func() (bool, error) {
+		ep, err := f.KubeClient.CoreV1().Endpoints(f.Namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		addrs = nil
+		for _, ss := range ep.Subsets {
+			for _, a := range ss.Addresses {
+				addrs = append(addrs, a.IP)
+			}
+		}
+		if len(addrs) != int(vaultCR.Spec.Nodes) {
+			return false, nil
+		}
+		for _, ip := range addrs {
+			if ip == sealed.Status.PodIP {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		t.Fatalf("expect headless service (%s) to resolve to %d vault pods including the sealed pod (%s), got %v: %v",
+			name, vaultCR.Spec.Nodes, sealed.Status.PodIP, addrs, err)
+	}
+}
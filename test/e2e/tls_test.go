@@ -0,0 +1,133 @@
+// Copyright 2018 The vault-operator Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package e2e
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+	"reflect"
+	"testing"
+
+	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
+	"github.com/coreos/vault-operator/pkg/util/k8sutil"
+	"github.com/coreos/vault-operator/pkg/util/tlsutil"
+	"github.com/coreos/vault-operator/pkg/util/vaultutil"
+	"github.com/coreos/vault-operator/test/e2e/e2eutil"
+	"github.com/coreos/vault-operator/test/e2e/framework"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// getSecretCert parses the PEM encoded certificate stored under the given key of the secret.
+func getSecretCert(t *testing.T, secretName, certName string) *x509.Certificate {
+	f := framework.Global
+	secret, err := f.KubeClient.CoreV1().Secrets(f.Namespace).Get(secretName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get secret (%s): %v", secretName, err)
+	}
+	cert, err := tlsutil.ParsePEMEncodedCACert(secret.Data[certName])
+	if err != nil {
+		t.Fatalf("failed to parse %s in secret (%s): %v", certName, secretName, err)
+	}
+	return cert
+}
+
+// verifyCACert checks that the given cert is a CA only allowed to sign certificates.
+func verifyCACert(t *testing.T, ca *x509.Certificate) {
+	if !ca.IsCA || !ca.BasicConstraintsValid {
+		t.Fatalf("CA cert (%s) is not marked as CA", ca.Subject.CommonName)
+	}
+	if ca.KeyUsage&x509.KeyUsageCertSign == 0 {
+		t.Fatalf("CA cert (%s) is not allowed to sign certificates", ca.Subject.CommonName)
+	}
+	if ca.KeyUsage&x509.KeyUsageKeyEncipherment != 0 {
+		t.Fatalf("CA cert (%s) should not be allowed key encipherment", ca.Subject.CommonName)
+	}
+}
+
+// verifyTLSSecret checks that the key pair in the secret matches, chains to the CA and
+// is only valid for the expected extended key usages.
+func verifyTLSSecret(t *testing.T, secretName, certName, keyName string, ca *x509.Certificate, dnsName string, usages []x509.ExtKeyUsage) {
+	f := framework.Global
+	secret, err := f.KubeClient.CoreV1().Secrets(f.Namespace).Get(secretName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get secret (%s): %v", secretName, err)
+	}
+	cert, err := tlsutil.ParsePEMEncodedCACert(secret.Data[certName])
+	if err != nil {
+		t.Fatalf("failed to parse %s in secret (%s): %v", certName, secretName, err)
+	}
+	key, err := tlsutil.ParsePEMEncodedPrivateKey(secret.Data[keyName])
+	if err != nil {
+		t.Fatalf("failed to parse %s in secret (%s): %v", keyName, secretName, err)
+	}
+	pub, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok || pub.N.Cmp(key.N) != 0 || pub.E != key.E {
+		t.Fatalf("%s does not match %s in secret (%s)", certName, keyName, secretName)
+	}
+	if cert.IsCA {
+		t.Fatalf("%s in secret (%s) should not be a CA", certName, secretName)
+	}
+	if !reflect.DeepEqual(cert.ExtKeyUsage, usages) {
+		t.Fatalf("%s in secret (%s): expect extended key usages (%v), got (%v)", certName, secretName, usages, cert.ExtKeyUsage)
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	for _, u := range usages {
+		_, err = cert.Verify(x509.VerifyOptions{
+			DNSName:   dnsName,
+			Roots:     roots,
+			KeyUsages: []x509.ExtKeyUsage{u},
+		})
+		if err != nil {
+			t.Fatalf("failed to verify %s in secret (%s) for usage (%v): %v", certName, secretName, u, err)
+		}
+	}
+}
+
+func TestDefaultTLSAssets(t *testing.T) {
+	f := framework.Global
+	vaultCR, err := e2eutil.CreateCluster(t, f.VaultsCRClient, e2eutil.NewCluster("test-vault-", f.Namespace, 1))
+	if err != nil {
+		t.Fatalf("failed to create vault cluster: %v", err)
+	}
+	defer func(vaultCR *api.VaultService) {
+		if err := e2eutil.DeleteCluster(t, f.VaultsCRClient, vaultCR); err != nil {
+			t.Fatalf("failed to delete vault cluster: %v", err)
+		}
+	}(vaultCR)
+	vaultCR, _ = e2eutil.WaitForCluster(t, f.KubeClient, f.VaultsCRClient, vaultCR)
+
+	serverAuth := []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+	clientAuth := []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	memberAuth := []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth}
+
+	vaultCA := getSecretCert(t, api.DefaultVaultClientTLSSecretName(vaultCR.Name), api.CATLSCertName)
+	verifyCACert(t, vaultCA)
+	verifyTLSSecret(t, api.DefaultVaultServerTLSSecretName(vaultCR.Name), vaultutil.ServerTLSCertName, vaultutil.ServerTLSKeyName,
+		vaultCA, fmt.Sprintf("%s.%s.svc", vaultCR.Name, vaultCR.Namespace), serverAuth)
+
+	etcdName := k8sutil.EtcdNameForVault(vaultCR.Name)
+	etcdCA := getSecretCert(t, k8sutil.EtcdClientTLSSecretName(vaultCR.Name), "etcd-client-ca.crt")
+	verifyCACert(t, etcdCA)
+	verifyTLSSecret(t, k8sutil.EtcdClientTLSSecretName(vaultCR.Name), "etcd-client.crt", "etcd-client.key",
+		etcdCA, "", clientAuth)
+	verifyTLSSecret(t, k8sutil.EtcdServerTLSSecretName(vaultCR.Name), "server.crt", "server.key",
+		etcdCA, fmt.Sprintf("%s-client.%s.svc", etcdName, vaultCR.Namespace), memberAuth)
+	verifyTLSSecret(t, k8sutil.EtcdPeerTLSSecretName(vaultCR.Name), "peer.crt", "peer.key",
+		etcdCA, fmt.Sprintf("%s-0000.%s.%s.svc", etcdName, etcdName, vaultCR.Namespace), memberAuth)
+}
@@ -15,11 +15,15 @@
 package e2e
 
 import (
+	"strings"
 	"testing"
 
 	api "github.com/coreos/vault-operator/pkg/apis/vault/v1alpha1"
 	"github.com/coreos/vault-operator/test/e2e/e2eutil"
 	"github.com/coreos/vault-operator/test/e2e/framework"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func TestUpgradeVault(t *testing.T) {
@@ -118,3 +122,168 @@ func TestUpgradeVault(t *testing.T) {
 		t.Fatalf("failed to wait for standby nodes to become updated: %v", err)
 	}
 }
+
+func TestCanaryUpgradeVault(t *testing.T) {
+	f := framework.Global
+	vaultCR := e2eutil.NewCluster("test-vault-", f.Namespace, 2)
+	vaultCR.Spec.Version = "0.9.1-0"
+	vaultCR.Spec.Upgrade = &api.UpgradePolicy{Strategy: api.UpgradeStrategyCanary, SoakTime: "30s"}
+	vaultCR, err := e2eutil.CreateCluster(t, f.VaultsCRClient, vaultCR)
+	if err != nil {
+		t.Fatalf("failed to create vault cluster: %v", err)
+	}
+	defer func(vaultCR *api.VaultService) {
+		if err := e2eutil.DeleteCluster(t, f.VaultsCRClient, vaultCR); err != nil {
+			t.Fatalf("failed to delete vault cluster: %v", err)
+		}
+	}(vaultCR)
+	vaultCR, tlsConfig := e2eutil.WaitForCluster(t, f.KubeClient, f.VaultsCRClient, vaultCR)
+
+	podName := vaultCR.Status.VaultStatus.Sealed[0]
+	vClient := e2eutil.SetupVaultClient(t, f.KubeClient, f.Namespace, tlsConfig, podName)
+	vaultCR, initResp := e2eutil.InitializeVault(t, f.VaultsCRClient, vaultCR, vClient)
+	for _, podName := range vaultCR.Status.VaultStatus.Sealed {
+		vClient = e2eutil.SetupVaultClient(t, f.KubeClient, f.Namespace, tlsConfig, podName)
+		if err := e2eutil.UnsealVaultNode(initResp.Keys[0], vClient); err != nil {
+			t.Fatalf("failed to unseal vault node(%v): %v", podName, err)
+		}
+	}
+	vaultCR, err = e2eutil.WaitStandbyVaultsUp(t, f.VaultsCRClient, 1, 6, vaultCR)
+	if err != nil {
+		t.Fatalf("failed to wait for vault nodes to become standby: %v", err)
+	}
+
+	newVersion := "0.9.1-1"
+	vaultCR, err = e2eutil.UpdateVersion(t, f.VaultsCRClient, vaultCR, newVersion)
+	if err != nil {
+		t.Fatalf("failed to update vault version: %v", err)
+	}
+
+	// Only the canary is upgraded until it has been an unsealed standby for the soak time.
+	vaultCR, err = e2eutil.WaitSealedVaultsUp(t, f.VaultsCRClient, 1, 6, vaultCR)
+	if err != nil {
+		t.Fatalf("failed to wait for the canary to become sealed: %v", err)
+	}
+	canary := vaultCR.Status.VaultStatus.Sealed[0]
+	if u := vaultCR.Status.Upgrade; u == nil || u.Phase != api.UpgradePhaseCanary || u.Canary != canary {
+		t.Fatalf("expect the sealed node (%s) to be the canary, got upgrade status %+v", canary, u)
+	}
+	vClient = e2eutil.SetupVaultClient(t, f.KubeClient, f.Namespace, tlsConfig, canary)
+	if err := e2eutil.UnsealVaultNode(initResp.Keys[0], vClient); err != nil {
+		t.Fatalf("failed to unseal canary(%v): %v", canary, err)
+	}
+	vaultCR, err = e2eutil.WaitUntilVaultConditionTrue(t, f.VaultsCRClient, 12, vaultCR, func(v *api.VaultService) bool {
+		return v.Status.Upgrade != nil && v.Status.Upgrade.Phase == api.UpgradePhaseRolling
+	})
+	if err != nil {
+		t.Fatalf("failed to wait for the canary to pass: %v", err)
+	}
+
+	// Once the canary passed, the rest of the nodes are upgraded.
+	vaultCR, err = e2eutil.WaitSealedVaultsUp(t, f.VaultsCRClient, 2, 6, vaultCR)
+	if err != nil {
+		t.Fatalf("failed to wait for updated sealed vault nodes: %v", err)
+	}
+	upgradedNodes := vaultCR.Status.VaultStatus.Sealed
+	for _, podName := range upgradedNodes {
+		vClient = e2eutil.SetupVaultClient(t, f.KubeClient, f.Namespace, tlsConfig, podName)
+		if err := e2eutil.UnsealVaultNode(initResp.Keys[0], vClient); err != nil {
+			t.Fatalf("failed to unseal vault node(%v): %v", podName, err)
+		}
+	}
+	vaultCR, err = e2eutil.WaitUntilAvailableAreFrom(t, f.VaultsCRClient, 6, vaultCR, upgradedNodes...)
+	if err != nil {
+		t.Fatalf("failed to see all available nodes to be from the newly unsealed pods (%v): %v", upgradedNodes, err)
+	}
+	vaultCR, err = e2eutil.WaitUntilVaultConditionTrue(t, f.VaultsCRClient, 6, vaultCR, func(v *api.VaultService) bool {
+		return v.Status.Upgrade != nil && v.Status.Upgrade.Phase == api.UpgradePhaseCompleted
+	})
+	if err != nil {
+		t.Fatalf("failed to wait for the upgrade to complete: %v", err)
+	}
+}
+
+func TestCanaryUpgradePaused(t *testing.T) {
+	f := framework.Global
+	vaultCR := e2eutil.NewCluster("test-vault-", f.Namespace, 1)
+	vaultCR.Spec.Version = "0.9.1-0"
+	vaultCR.Spec.Upgrade = &api.UpgradePolicy{Strategy: api.UpgradeStrategyCanary, Timeout: "30s"}
+	vaultCR, err := e2eutil.CreateCluster(t, f.VaultsCRClient, vaultCR)
+	if err != nil {
+		t.Fatalf("failed to create vault cluster: %v", err)
+	}
+	defer func(vaultCR *api.VaultService) {
+		if err := e2eutil.DeleteCluster(t, f.VaultsCRClient, vaultCR); err != nil {
+			t.Fatalf("failed to delete vault cluster: %v", err)
+		}
+	}(vaultCR)
+	vaultCR, _ = e2eutil.WaitForCluster(t, f.KubeClient, f.VaultsCRClient, vaultCR)
+
+	// The canary is never unsealed, so the upgrade is paused once it times out.
+	vaultCR, err = e2eutil.UpdateVersion(t, f.VaultsCRClient, vaultCR, "0.9.1-1")
+	if err != nil {
+		t.Fatalf("failed to update vault version: %v", err)
+	}
+	vaultCR, err = e2eutil.WaitUntilVaultConditionTrue(t, f.VaultsCRClient, 12, vaultCR, func(v *api.VaultService) bool {
+		return v.Status.IsConditionTrue(api.VaultServiceUpgradePaused)
+	})
+	if err != nil {
+		t.Fatalf("failed to wait for the upgrade to be paused: %v", err)
+	}
+	if u := vaultCR.Status.Upgrade; u == nil || u.Phase != api.UpgradePhasePaused {
+		t.Fatalf("expect the upgrade to be paused, got upgrade status %+v", u)
+	}
+
+	d, err := f.KubeClient.AppsV1beta1().Deployments(f.Namespace).Get(vaultCR.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get vault deployment: %v", err)
+	}
+	if img := d.Spec.Template.Spec.Containers[0].Image; !strings.HasSuffix(img, ":0.9.1-0") {
+		t.Fatalf("expect the vault deployment to stay on 0.9.1-0, got image %s", img)
+	}
+}
+
+func TestUpgradeRollback(t *testing.T) {
+	f := framework.Global
+	vaultCR := e2eutil.NewCluster("test-vault-", f.Namespace, 1)
+	vaultCR.Spec.Version = "0.9.1-0"
+	vaultCR.Spec.Upgrade = &api.UpgradePolicy{Timeout: "30s"}
+	vaultCR, err := e2eutil.CreateCluster(t, f.VaultsCRClient, vaultCR)
+	if err != nil {
+		t.Fatalf("failed to create vault cluster: %v", err)
+	}
+	defer func(vaultCR *api.VaultService) {
+		if err := e2eutil.DeleteCluster(t, f.VaultsCRClient, vaultCR); err != nil {
+			t.Fatalf("failed to delete vault cluster: %v", err)
+		}
+	}(vaultCR)
+	vaultCR, _ = e2eutil.WaitForCluster(t, f.KubeClient, f.VaultsCRClient, vaultCR)
+
+	// The upgraded node is never unsealed, so the upgrade is rolled back once it times out.
+	vaultCR, err = e2eutil.UpdateVersion(t, f.VaultsCRClient, vaultCR, "0.9.1-1")
+	if err != nil {
+		t.Fatalf("failed to update vault version: %v", err)
+	}
+	vaultCR, err = e2eutil.WaitUntilVaultConditionTrue(t, f.VaultsCRClient, 12, vaultCR, func(v *api.VaultService) bool {
+		c := v.Status.GetCondition(api.VaultServiceProgressing)
+		return c != nil && c.Status == v1.ConditionFalse && c.Reason == "UpgradeRolledBack"
+	})
+	if err != nil {
+		t.Fatalf("failed to wait for the upgrade to be rolled back: %v", err)
+	}
+	u := vaultCR.Status.Upgrade
+	if u == nil || u.Phase != api.UpgradePhaseRolledBack || !strings.HasSuffix(u.PreviousImage, ":0.9.1-0") {
+		t.Fatalf("expect the upgrade to be rolled back to 0.9.1-0, got upgrade status %+v", u)
+	}
+
+	d, err := f.KubeClient.AppsV1beta1().Deployments(f.Namespace).Get(vaultCR.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("failed to get vault deployment: %v", err)
+	}
+	if img := d.Spec.Template.Spec.Containers[0].Image; !strings.HasSuffix(img, ":0.9.1-0") {
+		t.Fatalf("expect the vault deployment to be rolled back to 0.9.1-0, got image %s", img)
+	}
+	if mu := d.Spec.Strategy.RollingUpdate.MaxUnavailable; mu == nil || mu.IntValue() != 1 {
+		t.Fatalf("expect the rollback to restore maxUnavailable 1, got %v", mu)
+	}
+}